@@ -11,23 +11,37 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sku4/ozon-route256-spending-bot/configs"
 	"github.com/sku4/ozon-route256-spending-bot/internal/handler/grpc"
+	grpcV1 "github.com/sku4/ozon-route256-spending-bot/internal/handler/grpc/v1"
 	"github.com/sku4/ozon-route256-spending-bot/internal/handler/telegram"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/rates"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/rates/nbrb"
 	"github.com/sku4/ozon-route256-spending-bot/internal/service"
+	"github.com/sku4/ozon-route256-spending-bot/internal/service/spending"
 	"github.com/sku4/ozon-route256-spending-bot/model/kafka"
 	"github.com/sku4/ozon-route256-spending-bot/model/server"
 	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
 	tg "github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/server"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/api"
+	apiV1 "github.com/sku4/ozon-route256-spending-bot/pkg/api/v1"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/cache"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/logger"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/session"
 	jaeger "github.com/uber/jaeger-client-go/config"
+	"net/url"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
+)
+
+const (
+	defaultWebhookListen = "/telegram/webhook"
+	// drainTimeout limits handling of received telegram updates on shutdown
+	drainTimeout = 30 * time.Second
 )
 
 func main() {
@@ -87,23 +101,51 @@ func main() {
 		logger.Fatalf("failed init repository: %s", err.Error())
 	}
 	ratesClient := InitRates(ctx, db, repos)
-	services := service.NewService(repos, tgClient, ratesClient, kafkaProducer)
+	sessions := session.New(ctx, os.Getenv("REDIS_HOST")+":"+os.Getenv("REDIS_PORT"),
+		os.Getenv("REDIS_PASSWORD"), spending.SessionTTL)
+	services := service.NewService(repos, sessions, tgClient, ratesClient, kafkaProducer, cfg.ApiAdmins)
 	handlers := telegram.NewHandler(services)
-	grpcHandlers := grpc.NewHandler(ctx, services)
+	for _, lang := range i18n.Langs {
+		languageCode := string(lang)
+		if lang == i18n.Default {
+			languageCode = ""
+		}
+		if err = tgServer.SetCommands(handlers.Commands(lang), languageCode); err != nil {
+			logger.Infos("error set commands: ", err)
+		}
+	}
+	grpcHandlers := grpc.NewHandler(ctx, services, os.Getenv("GRPC_SERVICE_TOKEN"))
+	grpcHandlersV1 := grpcV1.NewHandler(ctx, services)
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 
-	// run telegram server
-	go func() {
-		if err = tgServer.Run(ctx, handlers); err != nil {
-			logger.Fatalf("error occured while running: %s", err.Error())
-			quit <- nil
+	restServer := server.NewRest(ctx, api.RegisterSpendingHandlerFromEndpoint, apiV1.RegisterSpendingHandlerFromEndpoint)
+
+	// run telegram server by webhook on rest server or by long polling
+	if cfg.TelegramWebhook.Url != "" {
+		webhookHandler, errWebhook := tgServer.Webhook(ctx, handlers, tg.WebhookConfig{
+			Url:         cfg.TelegramWebhook.Url,
+			SecretToken: cfg.TelegramWebhook.SecretToken,
+		}, tg.NewDeduper(ctx, os.Getenv("REDIS_HOST")+":"+os.Getenv("REDIS_PORT"), os.Getenv("REDIS_PASSWORD")))
+		if errWebhook != nil {
+			logger.Fatalf("error init telegram webhook: %s", errWebhook.Error())
 		}
-	}()
+		restServer.Handle(webhookListen(cfg), webhookHandler)
+	} else {
+		go func() {
+			if err = tgServer.Run(ctx, handlers); err != nil {
+				logger.Fatalf("error occured while running: %s", err.Error())
+				quit <- nil
+			}
+		}()
+	}
+
+	// run recurring spendings
+	go services.Spending.RunRecurring(ctx)
 
 	// run grpc server
-	grpcServer := server.NewGrpc(ctx, grpcHandlers)
+	grpcServer := server.NewGrpc(ctx, grpcHandlers, grpcHandlersV1)
 	go func() {
 		if err = grpcServer.Run(os.Getenv("GRPC_URL")); err != nil {
 			logger.Info(err.Error())
@@ -112,7 +154,6 @@ func main() {
 	}()
 
 	// run rest server
-	restServer := server.NewRest(ctx)
 	go func() {
 		if err = restServer.Run(os.Getenv("GRPC_URL"), cfg.BotRestPort); err != nil {
 			logger.Info(err.Error())
@@ -134,6 +175,18 @@ func main() {
 	} else {
 		logger.Info("Rest server stopped")
 	}
+	drainCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	if err = tgServer.Shutdown(drainCtx); err != nil {
+		logger.Info(fmt.Sprintf("error drain telegram updates: %s", err.Error()))
+	} else {
+		logger.Info("Telegram updates drained")
+	}
+	if err = services.ShutdownWebhooks(drainCtx); err != nil {
+		logger.Info(fmt.Sprintf("error deliver webhooks: %s", err.Error()))
+	} else {
+		logger.Info("Webhooks delivered")
+	}
 
 	logger.Info("App Shutting Down")
 }
@@ -157,6 +210,18 @@ func InitTelegramBot(ctx context.Context, cfg *configs.Config) (tgClient *client
 	return
 }
 
+// webhookListen returns path of telegram webhook on rest server, root is taken by grpc-gateway
+func webhookListen(cfg *configs.Config) string {
+	if cfg.TelegramWebhook.Listen != "" {
+		return cfg.TelegramWebhook.Listen
+	}
+	if u, err := url.Parse(cfg.TelegramWebhook.Url); err == nil && u.Path != "" && u.Path != "/" {
+		return u.Path
+	}
+
+	return defaultWebhookListen
+}
+
 func InitRates(ctx context.Context, db *sqlx.DB, repos *repository.Repository) rates.Client {
 	ratesClient := nbrb.NewRates(db, repos.CurrencyClient)
 	run := ratesClient.UpdateRatesSync(ctx)
@@ -8,8 +8,8 @@ import (
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 	"github.com/pkg/errors"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sku4/ozon-route256-spending-bot/configs"
+	"github.com/sku4/ozon-route256-spending-bot/internal/handler/grpc/report"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/rates"
@@ -17,12 +17,14 @@ import (
 	"github.com/sku4/ozon-route256-spending-bot/internal/service"
 	"github.com/sku4/ozon-route256-spending-bot/model/consumer"
 	"github.com/sku4/ozon-route256-spending-bot/model/kafka"
+	"github.com/sku4/ozon-route256-spending-bot/model/server"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/api"
+	apiReport "github.com/sku4/ozon-route256-spending-bot/pkg/api/report"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/auth"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/cache"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/logger"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
-	"net/http"
 	"os"
 	"os/signal"
 	"strings"
@@ -64,7 +66,7 @@ func main() {
 	}
 	ratesClient := initRates(ctx, db, repos)
 
-	grpcConn, err := initGrpcConn(os.Getenv("GRPC_URL"))
+	grpcConn, err := initGrpcConn(os.Getenv("GRPC_URL"), os.Getenv("GRPC_SERVICE_TOKEN"))
 	if err != nil {
 		logger.Fatalf("failed init grpc client: %s", err.Error())
 	}
@@ -77,10 +79,19 @@ func main() {
 	}()
 	grpcClient := api.NewSpendingClient(grpcConn)
 
-	services := service.NewReportService(repos, ratesClient, grpcClient)
-	consumerGroupHandler := consumer.NewConsumer(services)
+	kafkaProducer, err := initKafkaProducer(ctx, kafka.BrokersList)
+	if err != nil {
+		logger.Fatalf("failed init kafka producer: %s", err.Error())
+	}
+	defer func() {
+		err = kafkaProducer.Close()
+		if err != nil {
+			logger.Info(fmt.Sprintf("failed to close producer: %s", err.Error()))
+		}
+	}()
 
-	http.Handle("/metrics", promhttp.Handler())
+	services := service.NewReportService(repos, ratesClient, grpcClient, kafkaProducer)
+	consumerGroupHandler := consumer.NewConsumer(services)
 
 	cache.Run(ctx)
 
@@ -93,11 +104,23 @@ func main() {
 		}
 	}()
 
+	// run scheduled digests
+	go services.DigestScheduler.RunDigest(ctx)
+
+	// run grpc server with report api
+	grpcServer := server.NewReportGrpc(ctx, report.NewHandler(ctx, services))
 	go func() {
-		logger.Info(fmt.Sprintf("Rest server is running on: %d", cfg.ReportRestPort))
-		err = http.ListenAndServe(fmt.Sprintf(":%d", cfg.ReportRestPort), nil)
-		if err != nil {
-			logger.Fatalf("error starting http server", err.Error())
+		if err = grpcServer.Run(os.Getenv("REPORT_GRPC_URL")); err != nil {
+			logger.Info(err.Error())
+			quit <- nil
+		}
+	}()
+
+	// run rest server with report api and metrics
+	restServer := server.NewRest(ctx, apiReport.RegisterReportServiceHandlerFromEndpoint)
+	go func() {
+		if err = restServer.Run(os.Getenv("REPORT_GRPC_URL"), cfg.ReportRestPort); err != nil {
+			logger.Info(err.Error())
 			quit <- nil
 		}
 	}()
@@ -106,6 +129,14 @@ func main() {
 
 	// graceful shutdown
 	logger.Info(fmt.Sprintf("Got signal %v, attempting graceful shutdown", <-quit))
+	grpcServer.GracefulStop()
+	logger.Info("gRPC graceful stopped")
+	err = restServer.Shutdown()
+	if err != nil {
+		logger.Info(fmt.Sprintf("error rest server shutdown: %s", err.Error()))
+	} else {
+		logger.Info("Rest server stopped")
+	}
 
 	logger.Info("App Shutting Down")
 }
@@ -158,11 +189,41 @@ func initRates(ctx context.Context, db *sqlx.DB, repos *repository.Repository) r
 	return ratesClient
 }
 
-func initGrpcConn(grpcUrl string) (conn *grpc.ClientConn, err error) {
-	conn, err = grpc.Dial(grpcUrl, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// initGrpcConn connects to bot with service token, bot accepts report callback with it only
+func initGrpcConn(grpcUrl, serviceToken string) (conn *grpc.ClientConn, err error) {
+	conn, err = grpc.Dial(grpcUrl, grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(auth.NewCredentials(serviceToken)))
 	if err != nil {
 		return nil, errors.Wrap(err, "did not connect")
 	}
 
 	return
 }
+
+func initKafkaProducer(ctx context.Context, brokerList []string) (sarama.AsyncProducer, error) {
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_2_3_0
+	// So we can know the partition and offset of messages.
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewAsyncProducer(brokerList, config)
+	if err != nil {
+		return nil, fmt.Errorf("starting Sarama producer: %w", err)
+	}
+
+	// We will log to STDOUT if we're not able to produce messages.
+	go func() {
+		for {
+			select {
+			case err := <-producer.Errors():
+				logger.Infos("Failed to write message:", err)
+			case successMsg := <-producer.Successes():
+				logger.Infos("Successful to write message, offset:", successMsg.Offset)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return producer, nil
+}
@@ -10,6 +10,15 @@ type Config struct {
 	ServiceName      string `mapstructure:"ServiceName"`
 	BotRestPort      int    `mapstructure:"botRestPort"`
 	ReportRestPort   int    `mapstructure:"reportRestPort"`
+	TelegramWebhook  struct {
+		// Url is public https url of webhook, long polling is used when it is empty
+		Url         string `mapstructure:"Url"`
+		SecretToken string `mapstructure:"SecretToken"`
+		// Listen is path of webhook on rest server, path of Url by default
+		Listen string `mapstructure:"Listen"`
+	} `mapstructure:"TelegramWebhook"`
+	// ApiAdmins are telegram ids of users whose write tokens rename and delete categories
+	ApiAdmins []int `mapstructure:"ApiAdmins"`
 }
 
 func Init() (*Config, error) {
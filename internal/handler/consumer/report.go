@@ -16,7 +16,7 @@ func (h *Handler) ReportMessage(ctx context.Context, msg *sarama.ConsumerMessage
 		return
 	}
 
-	err = h.services.BuildReport.Build(ctx, report.F1, report.F2, report.UserCurr, report.ChatId)
+	err = h.services.BuildReport.Build(ctx, report)
 	if err != nil {
 		logger.Infos("report message error:", err.Error())
 	}
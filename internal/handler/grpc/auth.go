@@ -0,0 +1,47 @@
+package grpc
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/token"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sendReportMethod is internal callback of report service, it is called with service credential only
+const sendReportMethod = "/api.Spending/SendReport"
+
+// Auth checks token of call: service token for internal callback, personal token with scope of method
+// and access to requested state for the rest
+func (h *Handler) Auth(ctx context.Context, fullMethodName string, req interface{}) (context.Context, error) {
+	rawToken, err := auth.FromMetadata(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if fullMethodName == sendReportMethod {
+		if h.serviceToken == "" || !auth.Equal(rawToken, h.serviceToken) {
+			return nil, status.Error(codes.Unauthenticated, "invalid service token")
+		}
+		return ctx, nil
+	}
+
+	ctx, err = h.services.Authenticate(ctx, rawToken)
+	if errors.Is(err, token.NotFoundError) {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	identity, err := auth.FromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = identity.Authorize(fullMethodName, req); err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
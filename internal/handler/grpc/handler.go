@@ -8,15 +8,17 @@ import (
 
 // Handler struct with grpc api server
 type Handler struct {
-	ctx      context.Context
-	services service.Service
+	ctx          context.Context
+	services     service.Service
+	serviceToken string
 	api.SpendingServer
 }
 
-// NewHandler creates a new handler
-func NewHandler(ctx context.Context, services *service.Service) *Handler {
+// NewHandler creates a new handler, serviceToken is credential of report service
+func NewHandler(ctx context.Context, services *service.Service, serviceToken string) *Handler {
 	return &Handler{
-		ctx:      ctx,
-		services: *services,
+		ctx:          ctx,
+		services:     *services,
+		serviceToken: serviceToken,
 	}
 }
@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"context"
+	"database/sql"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/category"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/category_limit"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/api"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/api/limit"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *Handler) GetLimits(ctx context.Context, in *limit.LimitsRequest) (*limit.Limits, error) {
+	limits, curr, err := h.services.StateLimits(ctx, int(in.StateId))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	out := &limit.Limits{}
+	for _, l := range limits {
+		out.Limits = append(out.Limits, &limit.Limit{
+			Id:            int64(l.Id),
+			CategoryId:    int64(l.Category.Id),
+			CategoryTitle: l.Category.Title,
+			Limit:         l.Limit.Float64(),
+			Spent:         l.Spent.Float64(),
+			Period:        string(l.Range.Period),
+			Days:          int32(l.Range.Days),
+			Currency:      curr.Abbr,
+		})
+	}
+
+	return out, nil
+}
+
+func (h *Handler) SetLimit(ctx context.Context, in *limit.SetLimitRequest) (*api.Empty, error) {
+	var empty = &api.Empty{}
+	r := period.Range{Period: period.Period(in.Period), Days: int(in.Days)}
+	if in.Period == "" {
+		r = period.Range{Period: period.PeriodMonth}
+	}
+	if err := r.Validate(); err != nil {
+		return empty, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err := h.services.StateSetLimit(ctx, int(in.StateId), int(in.CategoryId), decimal.ToDecimal(in.Limit), r)
+	if err != nil {
+		return empty, statusError(err)
+	}
+
+	return empty, nil
+}
+
+func (h *Handler) DeleteLimit(ctx context.Context, in *limit.DeleteLimitRequest) (*api.Empty, error) {
+	var empty = &api.Empty{}
+	err := h.services.StateDeleteLimit(ctx, int(in.StateId), int(in.Id))
+	if err != nil {
+		return empty, statusError(err)
+	}
+
+	return empty, nil
+}
+
+// statusError converts error of service to grpc status, state, category or limit not found is NotFound
+// and the rest errors are Internal, arguments are validated before service is called
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows),
+		errors.Is(err, category.NotFoundError),
+		errors.Is(err, category_limit.NotFoundError):
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
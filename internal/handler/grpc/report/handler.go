@@ -0,0 +1,70 @@
+package report
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/token"
+	"github.com/sku4/ozon-route256-spending-bot/internal/service"
+	apiReport "github.com/sku4/ozon-route256-spending-bot/pkg/api/report"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "report",
+			Name:      "grpc_total",
+		},
+		[]string{"method"},
+	)
+)
+
+// Handler struct with grpc api server of report service
+type Handler struct {
+	ctx      context.Context
+	services service.ReportService
+	apiReport.ReportServiceServer
+}
+
+// NewHandler creates a new handler of report service
+func NewHandler(ctx context.Context, services *service.ReportService) *Handler {
+	return &Handler{
+		ctx:      ctx,
+		services: *services,
+	}
+}
+
+func (h *Handler) Metrics(ctx context.Context, fullMethodName string) (context.Context, error) {
+	grpcTotal.WithLabelValues(fullMethodName).Inc()
+
+	return ctx, nil
+}
+
+// Auth checks personal token of call with scope of method and access to requested state
+func (h *Handler) Auth(ctx context.Context, fullMethodName string, req interface{}) (context.Context, error) {
+	rawToken, err := auth.FromMetadata(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	ctx, err = h.services.Authenticate(ctx, rawToken)
+	if errors.Is(err, token.NotFoundError) {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	identity, err := auth.FromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if err = identity.Authorize(fullMethodName, req); err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
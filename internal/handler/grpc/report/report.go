@@ -0,0 +1,46 @@
+package report
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/service/spending"
+	apiReport "github.com/sku4/ozon-route256-spending-bot/pkg/api/report"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *Handler) GetReport(ctx context.Context, in *apiReport.GetReportRequest) (*apiReport.GetReportResponse, error) {
+	data, err := h.services.Query(ctx, spending.ReportQuery{
+		StateId:  int(in.StateId),
+		Range:    period.Range{Period: period.Period(in.Period), Days: int(in.Days)},
+		Currency: in.Currency,
+		GroupBy:  in.GroupBy,
+	})
+	switch {
+	case errors.Is(err, spending.InvalidReportError):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, spending.RatesNotLoadedError):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	out := &apiReport.GetReportResponse{
+		From:     timestamppb.New(data.From),
+		To:       timestamppb.New(data.To),
+		Currency: data.Currency.Abbr,
+		GroupBy:  data.GroupBy,
+		Total:    data.Total.Float64(),
+	}
+	for _, g := range data.Groups {
+		out.Groups = append(out.Groups, &apiReport.ReportGroup{
+			Id:    int64(g.Id),
+			Title: g.Title,
+			Sum:   g.Sum.Float64(),
+		})
+	}
+
+	return out, nil
+}
@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"context"
+	apiV1 "github.com/sku4/ozon-route256-spending-bot/pkg/api/v1"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/auth"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/pagination"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+func (h *Handler) ListCategories(ctx context.Context, in *apiV1.ListCategoriesRequest) (*apiV1.ListCategoriesResponse,
+	error) {
+	page, err := pagination.Parse(in.PageSize, in.PageToken)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	categories, total, err := h.services.CategoriesPage(ctx, strings.TrimSpace(in.Title), page)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	out := &apiV1.ListCategoriesResponse{
+		NextPageToken: page.NextToken(total),
+		TotalSize:     int32(total),
+	}
+	for _, c := range categories {
+		out.Categories = append(out.Categories, &apiV1.Category{Id: int64(c.Id), Title: c.Title})
+	}
+
+	return out, nil
+}
+
+func (h *Handler) CreateCategory(ctx context.Context, in *apiV1.CreateCategoryRequest) (*apiV1.Category, error) {
+	title, err := categoryTitle(in.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := h.services.AddCategory(ctx, title)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &apiV1.Category{Id: int64(c.Id), Title: c.Title}, nil
+}
+
+func (h *Handler) UpdateCategory(ctx context.Context, in *apiV1.UpdateCategoryRequest) (*apiV1.Category, error) {
+	title, err := categoryTitle(in.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := h.services.UpdateCategory(ctx, int(in.Id), title)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &apiV1.Category{Id: int64(c.Id), Title: c.Title}, nil
+}
+
+func (h *Handler) DeleteCategory(ctx context.Context, in *apiV1.DeleteCategoryRequest) (*apiV1.Empty, error) {
+	identity, err := auth.FromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err = h.services.DeleteCategory(ctx, int(in.Id), identity.States); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &apiV1.Empty{}, nil
+}
+
+// categoryTitle trims title, rules of proto check its length only
+func categoryTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", status.Error(codes.InvalidArgument, "title is empty")
+	}
+
+	return title, nil
+}
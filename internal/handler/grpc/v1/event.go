@@ -0,0 +1,171 @@
+package v1
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/service/spending"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	apiV1 "github.com/sku4/ozon-route256-spending-bot/pkg/api/v1"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/auth"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/broadcast"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/pagination"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+func (h *Handler) ListEvents(ctx context.Context, in *apiV1.ListEventsRequest) (*apiV1.ListEventsResponse, error) {
+	page, err := pagination.Parse(in.PageSize, in.PageToken)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	filter := model.EventFilter{
+		StateId:    int(in.StateId),
+		CategoryId: int(in.CategoryId),
+		UserId:     int(in.UserId),
+	}
+	if in.From != nil {
+		filter.From = in.From.AsTime()
+	}
+	if in.To != nil {
+		filter.To = in.To.AsTime()
+	}
+	events, curr, total, err := h.services.StateEvents(ctx, filter, page)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	out := &apiV1.ListEventsResponse{
+		NextPageToken: page.NextToken(total),
+		TotalSize:     int32(total),
+	}
+	for _, e := range events {
+		out.Events = append(out.Events, toEvent(e, curr))
+	}
+
+	return out, nil
+}
+
+func (h *Handler) CreateEvent(ctx context.Context, in *apiV1.CreateEventRequest) (*apiV1.Event, error) {
+	e, curr, err := h.services.StateAddEvent(ctx, int(in.StateId), int(in.CategoryId), int(in.AccountId),
+		eventDate(in.Date), decimal.ToDecimal(in.Price))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return toEvent(e, curr), nil
+}
+
+func (h *Handler) UpdateEvent(ctx context.Context, in *apiV1.UpdateEventRequest) (*apiV1.Event, error) {
+	e, curr, err := h.services.StateUpdateEvent(ctx, int(in.StateId), int(in.Id), int(in.CategoryId),
+		eventDate(in.Date), decimal.ToDecimal(in.Price))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return toEvent(e, curr), nil
+}
+
+func (h *Handler) DeleteEvent(ctx context.Context, in *apiV1.DeleteEventRequest) (*apiV1.Empty, error) {
+	if err := h.services.StateDeleteEvent(ctx, int(in.StateId), int(in.Id)); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &apiV1.Empty{}, nil
+}
+
+// WatchEvents streams changes of events of states available to token until client or server stops
+func (h *Handler) WatchEvents(in *apiV1.WatchEventsRequest, stream apiV1.Spending_WatchEventsServer) error {
+	ctx := stream.Context()
+	identity, err := auth.FromContext(ctx)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	stateIds := identity.States
+	if in.StateId > 0 {
+		if !identity.HasState(int(in.StateId)) {
+			return status.Error(codes.PermissionDenied, "state is not available to token")
+		}
+		stateIds = []int{int(in.StateId)}
+	}
+
+	sub, err := h.services.WatchEvents(stateIds, in.Cursor)
+	if errors.Is(err, broadcast.CursorExpiredError) {
+		return status.Error(codes.OutOfRange, "cursor expired, list events and watch without cursor")
+	}
+	if err != nil {
+		return statusError(err)
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-h.ctx.Done():
+			return status.Error(codes.Unavailable, "server is stopping, resume from the last cursor")
+		case item, ok := <-sub.C():
+			if !ok {
+				if errors.Is(sub.Err(), broadcast.SlowConsumerError) {
+					return status.Error(codes.ResourceExhausted, "slow consumer, resume from the last cursor")
+				}
+				return nil
+			}
+			change, err := h.toEventChange(ctx, item.Value)
+			if err != nil {
+				return statusError(err)
+			}
+			change.Cursor = h.services.EventsCursor(item.Seq)
+			if err = stream.Send(change); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (h *Handler) toEventChange(ctx context.Context, change model.EventChange) (*apiV1.EventChange, error) {
+	out := &apiV1.EventChange{
+		Type:    eventChangeTypes[change.Type],
+		StateId: int64(change.StateId),
+	}
+	if change.Type == model.EventDeleted {
+		out.Event = &apiV1.Event{Id: int64(change.Event.Id)}
+		return out, nil
+	}
+
+	e, curr, err := h.services.StateEventSpend(ctx, change.StateId, change.Event)
+	if err != nil {
+		return nil, err
+	}
+	out.Event = toEvent(e, curr)
+
+	return out, nil
+}
+
+var eventChangeTypes = map[model.EventChangeType]apiV1.EventChange_Type{
+	model.EventCreated: apiV1.EventChange_CREATED,
+	model.EventUpdated: apiV1.EventChange_UPDATED,
+	model.EventDeleted: apiV1.EventChange_DELETED,
+}
+
+// eventDate returns calendar date of timestamp, events are stored by date
+func eventDate(ts *timestamppb.Timestamp) time.Time {
+	return period.Date(ts.AsTime())
+}
+
+func toEvent(e spending.EventSpend, curr model.Currency) *apiV1.Event {
+	return &apiV1.Event{
+		Id:            int64(e.Id),
+		CategoryId:    int64(e.Category.Id),
+		CategoryTitle: e.Category.Title,
+		Price:         e.Amount.Float64(),
+		Currency:      curr.Abbr,
+		Date:          timestamppb.New(e.Date),
+		UserId:        int64(e.UserId),
+		AccountId:     int64(e.AccountId),
+	}
+}
@@ -0,0 +1,54 @@
+package v1
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/account"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/category"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/category_limit"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/spending"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/webhook"
+	"github.com/sku4/ozon-route256-spending-bot/internal/service"
+	spendingService "github.com/sku4/ozon-route256-spending-bot/internal/service/spending"
+	apiV1 "github.com/sku4/ozon-route256-spending-bot/pkg/api/v1"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/pagination"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Handler struct with versioned grpc api server
+type Handler struct {
+	ctx      context.Context
+	services service.Service
+	apiV1.SpendingServer
+}
+
+// NewHandler creates a new handler of api v1
+func NewHandler(ctx context.Context, services *service.Service) *Handler {
+	return &Handler{
+		ctx:      ctx,
+		services: *services,
+	}
+}
+
+// statusError maps errors of services to grpc codes
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, spending.NotFoundError),
+		errors.Is(err, category.NotFoundError),
+		errors.Is(err, account.NotFoundError),
+		errors.Is(err, category_limit.NotFoundError),
+		errors.Is(err, webhook.NotFoundError):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, category.ExistsError):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, category.InUseError):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, pagination.InvalidTokenError),
+		errors.Is(err, spendingService.InvalidSettingsError),
+		errors.Is(err, spendingService.InvalidWebhookError):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
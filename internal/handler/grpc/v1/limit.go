@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"context"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/category_limit"
+	"github.com/sku4/ozon-route256-spending-bot/internal/service/spending"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/api/limit"
+	apiV1 "github.com/sku4/ozon-route256-spending-bot/pkg/api/v1"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/pagination"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *Handler) ListLimits(ctx context.Context, in *apiV1.ListLimitsRequest) (*apiV1.ListLimitsResponse, error) {
+	page, err := pagination.Parse(in.PageSize, in.PageToken)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	limits, curr, err := h.services.StateLimits(ctx, int(in.StateId))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	found := make([]spending.LimitSpend, 0, len(limits))
+	for _, l := range limits {
+		if in.CategoryId > 0 && int64(l.Category.Id) != in.CategoryId {
+			continue
+		}
+		if in.Period != "" && string(l.Range.Period) != in.Period {
+			continue
+		}
+		found = append(found, l)
+	}
+	from, to := page.Slice(len(found))
+
+	out := &apiV1.ListLimitsResponse{
+		NextPageToken: page.NextToken(len(found)),
+		TotalSize:     int32(len(found)),
+	}
+	for _, l := range found[from:to] {
+		out.Limits = append(out.Limits, toLimit(l, curr))
+	}
+
+	return out, nil
+}
+
+func (h *Handler) CreateLimit(ctx context.Context, in *apiV1.CreateLimitRequest) (*limit.Limit, error) {
+	r, err := limitRange(in.Period, in.Days)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.services.StateSetLimit(ctx, int(in.StateId), int(in.CategoryId), decimal.ToDecimal(in.Limit), r)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return h.stateLimit(ctx, in.StateId, func(l spending.LimitSpend) bool {
+		return int64(l.Category.Id) == in.CategoryId
+	})
+}
+
+func (h *Handler) UpdateLimit(ctx context.Context, in *apiV1.UpdateLimitRequest) (*limit.Limit, error) {
+	r, err := limitRange(in.Period, in.Days)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.services.StateUpdateLimit(ctx, int(in.StateId), int(in.Id), decimal.ToDecimal(in.Limit), r)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return h.stateLimit(ctx, in.StateId, func(l spending.LimitSpend) bool {
+		return int64(l.Id) == in.Id
+	})
+}
+
+func (h *Handler) DeleteLimit(ctx context.Context, in *apiV1.DeleteLimitRequest) (*apiV1.Empty, error) {
+	if err := h.services.StateDeleteLimit(ctx, int(in.StateId), int(in.Id)); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &apiV1.Empty{}, nil
+}
+
+// stateLimit returns limit of state with spending found by match
+func (h *Handler) stateLimit(ctx context.Context, stateId int64, match func(spending.LimitSpend) bool) (*limit.Limit,
+	error) {
+	limits, curr, err := h.services.StateLimits(ctx, int(stateId))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	for _, l := range limits {
+		if match(l) {
+			return toLimit(l, curr), nil
+		}
+	}
+
+	return nil, statusError(category_limit.NotFoundError)
+}
+
+// limitRange returns period of limit, month by default, days of rolling period are checked here
+func limitRange(p string, days int32) (period.Range, error) {
+	r := period.Range{Period: period.Period(p), Days: int(days)}
+	if p == "" {
+		r = period.Range{Period: period.PeriodMonth}
+	}
+	if err := r.Validate(); err != nil {
+		return period.Range{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return r, nil
+}
+
+func toLimit(l spending.LimitSpend, curr model.Currency) *limit.Limit {
+	return &limit.Limit{
+		Id:            int64(l.Id),
+		CategoryId:    int64(l.Category.Id),
+		CategoryTitle: l.Category.Title,
+		Limit:         l.Limit.Float64(),
+		Spent:         l.Spent.Float64(),
+		Period:        string(l.Range.Period),
+		Days:          int32(l.Range.Days),
+		Currency:      curr.Abbr,
+	}
+}
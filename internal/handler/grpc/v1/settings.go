@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"context"
+	"github.com/sku4/ozon-route256-spending-bot/internal/service/spending"
+	apiV1 "github.com/sku4/ozon-route256-spending-bot/pkg/api/v1"
+	"strings"
+)
+
+func (h *Handler) GetSettings(ctx context.Context, in *apiV1.GetSettingsRequest) (*apiV1.Settings, error) {
+	settings, err := h.services.StateSettings(ctx, int(in.StateId))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return toSettings(settings), nil
+}
+
+func (h *Handler) UpdateSettings(ctx context.Context, in *apiV1.UpdateSettingsRequest) (*apiV1.Settings, error) {
+	update := spending.SettingsUpdate{
+		Currency:  in.Currency,
+		WeekStart: in.WeekStart,
+		Timezone:  in.Timezone,
+	}
+	if in.MonthStart != nil {
+		monthStart := int(*in.MonthStart)
+		update.MonthStart = &monthStart
+	}
+	for _, threshold := range in.Thresholds {
+		update.Thresholds = append(update.Thresholds, int(threshold))
+	}
+
+	settings, err := h.services.StateUpdateSettings(ctx, int(in.StateId), update)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return toSettings(settings), nil
+}
+
+func toSettings(settings spending.Settings) *apiV1.Settings {
+	out := &apiV1.Settings{
+		Currency:   settings.Currency.Abbr,
+		WeekStart:  strings.ToLower(settings.Start.Weekday.String()[:3]),
+		MonthStart: int32(settings.Start.MonthDay),
+		Timezone:   settings.Timezone,
+	}
+	for _, threshold := range settings.Thresholds {
+		out.Thresholds = append(out.Thresholds, int32(threshold))
+	}
+
+	return out
+}
@@ -0,0 +1,94 @@
+package v1
+
+import (
+	"context"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	apiV1 "github.com/sku4/ozon-route256-spending-bot/pkg/api/v1"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/pagination"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *Handler) ListWebhooks(ctx context.Context, in *apiV1.ListWebhooksRequest) (*apiV1.ListWebhooksResponse,
+	error) {
+	webhooks, err := h.services.StateWebhooks(ctx, int(in.StateId))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	out := &apiV1.ListWebhooksResponse{}
+	for _, w := range webhooks {
+		webhook := toWebhook(w)
+		// secret is returned by CreateWebhook only
+		webhook.Secret = ""
+		out.Webhooks = append(out.Webhooks, webhook)
+	}
+
+	return out, nil
+}
+
+func (h *Handler) CreateWebhook(ctx context.Context, in *apiV1.CreateWebhookRequest) (*apiV1.Webhook, error) {
+	w, err := h.services.StateAddWebhook(ctx, int(in.StateId), in.Url, in.Events)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return toWebhook(w), nil
+}
+
+func (h *Handler) DeleteWebhook(ctx context.Context, in *apiV1.DeleteWebhookRequest) (*apiV1.Empty, error) {
+	if err := h.services.StateDeleteWebhook(ctx, int(in.StateId), int(in.Id)); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &apiV1.Empty{}, nil
+}
+
+func (h *Handler) ListWebhookDeliveries(ctx context.Context,
+	in *apiV1.ListWebhookDeliveriesRequest) (*apiV1.ListWebhookDeliveriesResponse, error) {
+	page, err := pagination.Parse(in.PageSize, in.PageToken)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	deliveries, total, err := h.services.StateWebhookDeliveries(ctx, int(in.StateId), int(in.Id), page)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	out := &apiV1.ListWebhookDeliveriesResponse{
+		NextPageToken: page.NextToken(total),
+		TotalSize:     int32(total),
+	}
+	for _, d := range deliveries {
+		out.Deliveries = append(out.Deliveries, &apiV1.WebhookDelivery{
+			Id:         int64(d.Id),
+			DeliveryId: d.DeliveryId,
+			Event:      string(d.Event),
+			Attempt:    int32(d.Attempt),
+			StatusCode: int32(d.StatusCode),
+			Error:      d.Error,
+			DurationMs: int32(d.DurationMs),
+			CreatedAt:  timestamppb.New(d.CreatedAt),
+		})
+	}
+
+	return out, nil
+}
+
+func toWebhook(w model.Webhook) *apiV1.Webhook {
+	out := &apiV1.Webhook{
+		Id:        int64(w.Id),
+		Url:       w.Url,
+		Secret:    w.Secret,
+		Failures:  int32(w.Failures),
+		CreatedAt: timestamppb.New(w.CreatedAt),
+	}
+	for _, e := range w.Events {
+		out.Events = append(out.Events, string(e))
+	}
+	if !w.DisabledAt.IsZero() {
+		out.DisabledAt = timestamppb.New(w.DisabledAt)
+	}
+
+	return out
+}
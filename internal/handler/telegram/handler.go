@@ -4,6 +4,7 @@ import (
 	"context"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 	"github.com/sku4/ozon-route256-spending-bot/internal/service"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
 )
 
 type IHandler interface {
@@ -18,10 +19,19 @@ func (f Func) IncomingMessage(ctx context.Context, update tgbotapi.Update) error
 
 type Handler struct {
 	services service.Service
+	router   *Router
 }
 
-func NewHandler(services *service.Service) IHandler {
-	return &Handler{
+func NewHandler(services *service.Service) *Handler {
+	h := &Handler{
 		services: *services,
 	}
+	h.router = h.routes()
+
+	return h
+}
+
+// Commands returns menu of commands for Telegram in language
+func (h *Handler) Commands(lang i18n.Lang) []BotCommand {
+	return h.router.Commands(i18n.NewPrinter(lang))
 }
@@ -0,0 +1,107 @@
+package telegram
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"sync"
+	"time"
+)
+
+// Reply sends message to chat of update, middlewares translate message by printer of user
+type Reply func(context.Context, tgbotapi.Update, string) error
+
+// Tracing starts span named by command of route
+func Tracing(next IHandler) IHandler {
+	return Func(func(ctx context.Context, update tgbotapi.Update) (err error) {
+		name := "TelegramRoute"
+		if route, ok := RouteFromContext(ctx); ok {
+			name += " /" + route.Command
+		}
+		span, ctx := opentracing.StartSpanFromContext(ctx, name)
+		defer span.Finish()
+
+		if err = next.IncomingMessage(ctx, update); err != nil {
+			ext.Error.Set(span, true)
+		}
+
+		return
+	})
+}
+
+// Private handles updates of private chats only, other chats get message
+func Private(reply Reply, message string) Middleware {
+	return func(next IHandler) IHandler {
+		return Func(func(ctx context.Context, update tgbotapi.Update) error {
+			if chat := updateChat(update); chat != nil && !chat.IsPrivate() {
+				return reply(ctx, update, i18n.FromContext(ctx).Text(message))
+			}
+			return next.IncomingMessage(ctx, update)
+		})
+	}
+}
+
+// RateLimit lets every user run route limit times per period, user is told to wait when limit is reached
+func RateLimit(reply Reply, limit int, per time.Duration) Middleware {
+	type window struct {
+		start time.Time
+		count int
+	}
+	var (
+		mu      sync.Mutex
+		windows = make(map[int]*window)
+	)
+	allow := func(userId int, now time.Time) bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		w, ok := windows[userId]
+		if !ok || now.Sub(w.start) >= per {
+			// forget windows of users gone quiet
+			for id, old := range windows {
+				if now.Sub(old.start) >= per {
+					delete(windows, id)
+				}
+			}
+			w = &window{start: now}
+			windows[userId] = w
+		}
+		w.count++
+
+		return w.count <= limit
+	}
+
+	return func(next IHandler) IHandler {
+		return Func(func(ctx context.Context, update tgbotapi.Update) error {
+			from := updateFrom(update)
+			if from != nil && !allow(from.ID, time.Now()) {
+				return reply(ctx, update, i18n.FromContext(ctx).Text("Too many requests, please repeat later"))
+			}
+			return next.IncomingMessage(ctx, update)
+		})
+	}
+}
+
+func updateChat(update tgbotapi.Update) *tgbotapi.Chat {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat
+	}
+
+	return nil
+}
+
+func updateFrom(update tgbotapi.Update) *tgbotapi.User {
+	switch {
+	case update.Message != nil:
+		return update.Message.From
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"sort"
+	"strings"
+)
+
+// Middleware wraps handler of route
+type Middleware func(IHandler) IHandler
+
+// Route handles command and buttons of command
+type Route struct {
+	// Command is name of command without slash
+	Command string
+	Aliases []string
+	// Usage is example of command in help, `/command` by default
+	Usage string
+	// Help describes command in /help and in menu of Telegram, commands without help are hidden
+	Help string
+	// Prefixes of callback data of buttons of command
+	Prefixes []string
+	// Rates waits until rates of currencies are loaded
+	Rates       bool
+	Middlewares []Middleware
+	Handle      Func
+	// Query handles buttons of command, Handle by default
+	Query Func
+
+	handler IHandler
+}
+
+// BotCommand is command of menu of Telegram
+type BotCommand struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+type routeKey struct{}
+
+// RouteFromContext returns route of update handled by router
+func RouteFromContext(ctx context.Context) (*Route, bool) {
+	r, ok := ctx.Value(routeKey{}).(*Route)
+	return r, ok
+}
+
+// Router routes commands by name or alias and callbacks by the longest prefix of data,
+// other updates go to fallback
+type Router struct {
+	middlewares []Middleware
+	routes      []*Route
+	commands    map[string]*Route
+	prefixes    []string
+	byPrefix    map[string]*Route
+	fallback    IHandler
+}
+
+func NewRouter(fallback Func) *Router {
+	return &Router{
+		commands: make(map[string]*Route),
+		byPrefix: make(map[string]*Route),
+		fallback: fallback,
+	}
+}
+
+// Use adds middlewares of all routes added after, they run before middlewares of route
+func (r *Router) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// Add registers route, it panics when command, alias or prefix is already registered
+func (r *Router) Add(route Route) {
+	rt := &route
+	for _, name := range append([]string{rt.Command}, rt.Aliases...) {
+		if _, ok := r.commands[name]; ok {
+			panic(fmt.Sprintf("telegram: command %s is already registered", name))
+		}
+		r.commands[name] = rt
+	}
+	for _, prefix := range rt.Prefixes {
+		if _, ok := r.byPrefix[prefix]; ok {
+			panic(fmt.Sprintf("telegram: prefix %s is already registered", prefix))
+		}
+		r.byPrefix[prefix] = rt
+		r.prefixes = append(r.prefixes, prefix)
+	}
+	sort.Slice(r.prefixes, func(i, j int) bool {
+		return len(r.prefixes[i]) > len(r.prefixes[j])
+	})
+
+	var h IHandler = Func(func(ctx context.Context, update tgbotapi.Update) error {
+		if update.CallbackQuery != nil && rt.Query != nil {
+			return rt.Query(ctx, update)
+		}
+		return rt.Handle(ctx, update)
+	})
+	for i := len(rt.Middlewares) - 1; i >= 0; i-- {
+		h = rt.Middlewares[i](h)
+	}
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		h = r.middlewares[i](h)
+	}
+	next := h
+	rt.handler = Func(func(ctx context.Context, update tgbotapi.Update) error {
+		return next.IncomingMessage(context.WithValue(ctx, routeKey{}, rt), update)
+	})
+	r.routes = append(r.routes, rt)
+}
+
+// Match returns route of update, route is nil for updates going to fallback
+func (r *Router) Match(update tgbotapi.Update) *Route {
+	switch {
+	case update.Message != nil && update.Message.IsCommand():
+		return r.commands[update.Message.Command()]
+	case update.CallbackQuery != nil:
+		for _, prefix := range r.prefixes {
+			if strings.HasPrefix(update.CallbackQuery.Data, prefix) {
+				return r.byPrefix[prefix]
+			}
+		}
+	}
+
+	return nil
+}
+
+// IncomingMessage handles update by its route or by fallback
+func (r *Router) IncomingMessage(ctx context.Context, update tgbotapi.Update) error {
+	if route := r.Match(update); route != nil {
+		return route.handler.IncomingMessage(ctx, update)
+	}
+
+	return r.fallback.IncomingMessage(ctx, update)
+}
+
+// Help lists commands with help translated by printer in order of registration
+func (r *Router) Help(pr *i18n.Printer) string {
+	lines := make([]string, 0, len(r.routes))
+	for _, rt := range r.routes {
+		if rt.Help == "" {
+			continue
+		}
+		usage := rt.Usage
+		if usage == "" {
+			usage = "/" + rt.Command
+		}
+		aliases := ""
+		if len(rt.Aliases) > 0 {
+			aliases = pr.Sprintf(" (also %s)", "/"+strings.Join(rt.Aliases, ", /"))
+		}
+		lines = append(lines, fmt.Sprintf("%s _- %s%s_", usage, pr.Text(rt.Help), aliases))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Commands returns menu of Telegram in language of printer, markdown of help is removed
+func (r *Router) Commands(pr *i18n.Printer) []BotCommand {
+	plain := strings.NewReplacer("`", "", "*", "")
+	commands := make([]BotCommand, 0, len(r.routes))
+	for _, rt := range r.routes {
+		if rt.Help == "" {
+			continue
+		}
+		commands = append(commands, BotCommand{
+			Command:     rt.Command,
+			Description: plain.Replace(pr.Text(rt.Help)),
+		})
+	}
+
+	return commands
+}
@@ -0,0 +1,134 @@
+//go:build integration
+// +build integration
+
+package telegram
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func commandUpdate(text string, chatType string) tgbotapi.Update {
+	length := len(text)
+	for i, c := range text {
+		if c == ' ' {
+			length = i
+			break
+		}
+	}
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		Text:     text,
+		From:     &tgbotapi.User{ID: 5},
+		Chat:     &tgbotapi.Chat{ID: 15, Type: chatType},
+		Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Length: length}},
+	}}
+}
+
+func queryUpdate(data string) tgbotapi.Update {
+	return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		Data:    data,
+		From:    &tgbotapi.User{ID: 5},
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 15, Type: "private"}},
+	}}
+}
+
+func TestRouter(t *testing.T) {
+	var handled []string
+	handle := func(name string) Func {
+		return func(ctx context.Context, update tgbotapi.Update) error {
+			handled = append(handled, name)
+			return nil
+		}
+	}
+	mark := func(name string) Middleware {
+		return func(next IHandler) IHandler {
+			return Func(func(ctx context.Context, update tgbotapi.Update) error {
+				handled = append(handled, name)
+				return next.IncomingMessage(ctx, update)
+			})
+		}
+	}
+
+	r := NewRouter(handle("fallback"))
+	r.Use(mark("global"))
+	r.Add(Route{Command: "start", Aliases: []string{"help"}, Help: "commands of bot", Handle: handle("start")})
+	r.Add(Route{Command: "limit", Usage: "`/limit 100`", Help: "limit per `week`", Prefixes: []string{"limit_"},
+		Rates: true, Middlewares: []Middleware{mark("route")}, Handle: handle("limit")})
+	r.Add(Route{Command: "limits", Prefixes: []string{"limits_"}, Handle: handle("limits"),
+		Query: handle("limits query")})
+
+	tests := []struct {
+		name   string
+		update tgbotapi.Update
+		want   []string
+		rates  bool
+	}{
+		{name: "Command", update: commandUpdate("/start", "private"), want: []string{"global", "start"}},
+		{name: "Alias", update: commandUpdate("/help", "private"), want: []string{"global", "start"}},
+		{name: "Route middleware", update: commandUpdate("/limit 100", "private"),
+			want: []string{"global", "route", "limit"}, rates: true},
+		{name: "Button", update: queryUpdate("limit_abc_1"), want: []string{"global", "route", "limit"}, rates: true},
+		{name: "Longest prefix", update: queryUpdate("limits_del_1"), want: []string{"global", "limits query"}},
+		{name: "Unknown command", update: commandUpdate("/unknown", "private"), want: []string{"fallback"}},
+		{name: "Unknown button", update: queryUpdate("other_1"), want: []string{"fallback"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled = nil
+			route := r.Match(tt.update)
+			assert.Equal(t, tt.rates, route != nil && route.Rates)
+			assert.NoError(t, r.IncomingMessage(context.Background(), tt.update))
+			assert.Equal(t, tt.want, handled)
+		})
+	}
+
+	en := i18n.NewPrinter(i18n.En)
+	assert.Equal(t, "/start _- commands of bot (also /help)_\n`/limit 100` _- limit per `week`_", r.Help(en))
+	assert.Equal(t, []BotCommand{
+		{Command: "start", Description: "commands of bot"},
+		{Command: "limit", Description: "limit per week"},
+	}, r.Commands(en))
+	ru := i18n.NewPrinter(i18n.Ru)
+	assert.Equal(t, "/start _- команды бота (также /help)_\n`/limit 100` _- limit per `week`_", r.Help(ru))
+	assert.Equal(t, "команды бота", r.Commands(ru)[0].Description)
+	assert.Panics(t, func() {
+		r.Add(Route{Command: "help", Handle: handle("help")})
+	})
+}
+
+func TestMiddlewares(t *testing.T) {
+	var replies []string
+	reply := func(ctx context.Context, update tgbotapi.Update, message string) error {
+		replies = append(replies, message)
+		return nil
+	}
+	handled := 0
+	h := Func(func(ctx context.Context, update tgbotapi.Update) error {
+		handled++
+		return nil
+	})
+	ctx := context.Background()
+
+	private := Private(reply, "private only")(h)
+	assert.NoError(t, private.IncomingMessage(ctx, commandUpdate("/token", "group")))
+	assert.NoError(t, private.IncomingMessage(ctx, commandUpdate("/token", "private")))
+	assert.Equal(t, 1, handled)
+	assert.Equal(t, []string{"private only"}, replies)
+
+	handled, replies = 0, nil
+	limited := RateLimit(reply, 2, time.Hour)(h)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, limited.IncomingMessage(ctx, commandUpdate("/report7", "private")))
+	}
+	assert.Equal(t, 2, handled)
+	assert.Equal(t, []string{"Too many requests, please repeat later"}, replies)
+
+	replies = nil
+	ctx = i18n.WithPrinter(ctx, i18n.NewPrinter(i18n.Ru))
+	assert.NoError(t, limited.IncomingMessage(ctx, commandUpdate("/report7", "private")))
+	assert.Equal(t, []string{"Слишком много запросов, повторите позже"}, replies)
+}
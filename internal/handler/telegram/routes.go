@@ -0,0 +1,92 @@
+package telegram
+
+import (
+	"time"
+)
+
+const (
+	reportLimit  = 10
+	reportPeriod = time.Minute
+)
+
+// routes registers commands of bot, order of registration is order of /help and of menu of Telegram
+func (h *Handler) routes() *Router {
+	s := h.services.Spending
+	reply := s.ErrorMessage
+
+	r := NewRouter(h.fallback)
+	r.Use(Tracing)
+	r.Add(Route{Command: "start", Aliases: []string{"help"}, Help: "commands of bot", Handle: h.help})
+	r.Add(Route{Command: "categories", Help: "list categories or add one", Prefixes: []string{"categories_"},
+		Handle: h.dialog})
+	r.Add(Route{Command: "categoryadd", Usage: "`/categoryadd Food`", Help: "where Food is category name",
+		Handle: s.CategoryAdd})
+	r.Add(Route{Command: "spendingadd", Usage: "`/spendingadd 100`", Help: "where 100 is price",
+		Prefixes: []string{"spendingadd_"}, Rates: true, Handle: h.dialog})
+	r.Add(Route{Command: "report7", Help: "report by current week", Rates: true,
+		Middlewares: []Middleware{RateLimit(reply, reportLimit, reportPeriod)}, Handle: s.Report7})
+	r.Add(Route{Command: "report31", Help: "report by current month with forecast", Rates: true,
+		Middlewares: []Middleware{RateLimit(reply, reportLimit, reportPeriod)}, Handle: s.Report31})
+	r.Add(Route{Command: "report365", Help: "report by current year", Rates: true,
+		Middlewares: []Middleware{RateLimit(reply, reportLimit, reportPeriod)}, Handle: s.Report365})
+	r.Add(Route{Command: "currency", Help: "change currency", Prefixes: []string{"currency_"}, Rates: true,
+		Handle: h.dialog})
+	r.Add(Route{Command: "limit", Usage: "`/limit 100 week`",
+		Help:     "limit category by sum spending per `day`, `week`, `month` (default), `year` or `days 10`",
+		Prefixes: []string{"limit_"}, Rates: true, Handle: h.dialog})
+	r.Add(Route{Command: "limits", Help: "limits with spending, change or remove them", Prefixes: []string{"limits_"},
+		Rates: true, Handle: s.Limits, Query: s.LimitsQuery})
+	r.Add(Route{Command: "thresholds", Usage: "`/thresholds 50 80 100`",
+		Help: "warn when spending reaches percents of limit", Handle: s.Thresholds})
+	r.Add(Route{Command: "weekstart", Usage: "`/weekstart sun`", Help: "weekday weeks begin on",
+		Handle: s.WeekStart})
+	r.Add(Route{Command: "monthstart", Usage: "`/monthstart 10`", Help: "day months begin on, for example payday",
+		Handle: s.MonthStart})
+	r.Add(Route{Command: "timezone", Usage: "`/timezone Europe/Moscow`",
+		Help: "time zone of dates and reports, also set by shared location", Handle: s.Timezone})
+	r.Add(Route{Command: "accounts", Help: "cash, cards and other accounts", Prefixes: []string{"accounts_"},
+		Handle: s.Accounts, Query: s.AccountsQuery})
+	r.Add(Route{Command: "accountadd", Usage: "`/accountadd Cash USD 100`", Help: "where 100 is opening balance",
+		Rates: true, Handle: s.AccountAdd})
+	r.Add(Route{Command: "transfer", Usage: "`/transfer 100`", Help: "transfer between accounts",
+		Prefixes: []string{"transfer_"}, Rates: true, Handle: s.Transfer, Query: s.TransferQuery})
+	r.Add(Route{Command: "balance", Help: "current balances of accounts and who owes whom in group", Rates: true,
+		Handle: s.Balance})
+	r.Add(Route{Command: "split", Usage: "`/split Anna Bob`",
+		Help:  "split your last spending in group equally, by shares `Anna:2` or amounts `Anna=500`",
+		Rates: true, Handle: s.Split})
+	r.Add(Route{Command: "settle", Help: "minimal transfers to settle up debts of group", Prefixes: []string{"settle_"},
+		Rates: true, Handle: s.Settle, Query: s.SettleQuery})
+	r.Add(Route{Command: "recurring", Usage: "`/recurring 500 month 10`",
+		Help:     "monthly spending on day 10, also `week mon` or `year 03-15`",
+		Prefixes: []string{"recurring_"}, Rates: true, Handle: s.Recurring, Query: s.RecurringQuery})
+	r.Add(Route{Command: "budget", Usage: "`/budget 50000 rollover`",
+		Help: "total budget of month, unused money is carried to next month", Rates: true, Handle: s.Budget})
+	r.Add(Route{Command: "plan", Usage: "`/plan 5000`",
+		Help:     "plan category of month, /plan shows planned, actual and difference",
+		Prefixes: []string{"plan_"}, Rates: true, Handle: s.Plan, Query: s.PlanQuery})
+	r.Add(Route{Command: "digest", Usage: "`/digest week 20:00`",
+		Help:     "weekly report every sunday at 20:00, also `month` or `year`",
+		Prefixes: []string{"digest_"}, Handle: s.Digest, Query: s.DigestQuery})
+	r.Add(Route{Command: "goal", Usage: "`/goal Vacation 150000 EUR by August`",
+		Help:     "savings goal, `/goal Vacation +5000` logs contribution",
+		Prefixes: []string{"goal_"}, Rates: true, Handle: s.Goal, Query: s.GoalQuery})
+	r.Add(Route{Command: "token", Usage: "`/token Sheets write`",
+		Help:     "api token in private chat, read only without `write`, /token revokes",
+		Prefixes: []string{"token_"},
+		Middlewares: []Middleware{
+			Private(reply, "Tokens are personal, write /token in private chat with bot"),
+		},
+		Handle: s.Token, Query: s.TokenQuery})
+	r.Add(Route{Command: "webhook", Usage: "`/webhook https://example.com/hook`",
+		Help:     "post signed events to url, /webhook deletes",
+		Prefixes: []string{"webhook_"},
+		Middlewares: []Middleware{
+			Private(reply, "Secret of webhook is personal, write /webhook in private chat with bot"),
+		},
+		Handle: s.Webhook, Query: s.WebhookQuery})
+	r.Add(Route{Command: "language", Usage: "`/language ru`", Help: "language of bot, English or Russian",
+		Handle: s.Language})
+
+	return r
+}
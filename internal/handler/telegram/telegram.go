@@ -4,7 +4,7 @@ import (
 	"context"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 	"github.com/pkg/errors"
-	"strings"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
 )
 
 func (h *Handler) IncomingMessage(ctx context.Context, update tgbotapi.Update) (err error) {
@@ -14,9 +14,9 @@ func (h *Handler) IncomingMessage(ctx context.Context, update tgbotapi.Update) (
 		return errors.Wrap(err, "incoming message define user")
 	}
 
+	route := h.router.Match(update)
 	if run {
-		req := requiredRates(update)
-		if req {
+		if route != nil && route.Rates {
 			// wait until rates will update
 			err = <-h.services.Middleware.RatesSyncChan(ctx)
 			if err != nil {
@@ -34,66 +34,31 @@ func (h *Handler) IncomingMessage(ctx context.Context, update tgbotapi.Update) (
 		}
 	}
 
-	if update.Message != nil {
-		if update.Message.IsCommand() {
-			switch update.Message.Command() {
-			case "start", "help":
-				err = h.services.Spending.Start(ctx, update)
-			case "categories":
-				err = h.services.Spending.Categories(ctx, update)
-			case "categoryadd":
-				err = h.services.Spending.CategoryAdd(ctx, update)
-			case "spendingadd":
-				err = h.services.Spending.SpendingAdd(ctx, update)
-			case "report7":
-				err = h.services.Spending.Report7(ctx, update)
-			case "report31":
-				err = h.services.Spending.Report31(ctx, update)
-			case "report365":
-				err = h.services.Spending.Report365(ctx, update)
-			case "currency":
-				err = h.services.Spending.Currency(ctx, update)
-			case "limit":
-				err = h.services.Spending.LimitAdd(ctx, update)
-			default:
-				err = h.services.Spending.NotFound(ctx, update)
-			}
-		}
-	} else if update.CallbackQuery != nil {
-		if strings.Index(update.CallbackQuery.Data, "categories") == 0 {
-			err = h.services.Spending.CategoriesQuery(ctx, update)
-		} else if strings.Index(update.CallbackQuery.Data, "spendingadd") == 0 {
-			err = h.services.Spending.SpendingAddQuery(ctx, update)
-		} else if strings.Index(update.CallbackQuery.Data, "currency") == 0 {
-			err = h.services.Spending.CurrencyQuery(ctx, update)
-		} else if strings.Index(update.CallbackQuery.Data, "limit") == 0 {
-			err = h.services.Spending.LimitQuery(ctx, update)
-		}
-	}
+	return h.router.IncomingMessage(ctx, update)
+}
 
-	return
+func (h *Handler) help(ctx context.Context, update tgbotapi.Update) error {
+	return h.services.Spending.Help(ctx, update, h.router.Help(i18n.FromContext(ctx)))
 }
 
-func requiredRates(update tgbotapi.Update) (req bool) {
-	if update.Message != nil {
-		if update.Message.IsCommand() {
-			switch update.Message.Command() {
-			case "spendingadd",
-				"report7",
-				"report31",
-				"report365",
-				"currency",
-				"limit":
-				req = true
-			}
-		}
-	} else if update.CallbackQuery != nil {
-		if strings.Index(update.CallbackQuery.Data, "spendingadd") == 0 ||
-			strings.Index(update.CallbackQuery.Data, "currency") == 0 ||
-			strings.Index(update.CallbackQuery.Data, "limit") == 0 {
-			req = true
-		}
+// dialog runs step of multi-step dialog of service
+func (h *Handler) dialog(ctx context.Context, update tgbotapi.Update) error {
+	_, err := h.services.Spending.Dialog(ctx, update)
+	return err
+}
+
+// fallback handles updates without route: shared location, text written in dialogs and unknown commands
+func (h *Handler) fallback(ctx context.Context, update tgbotapi.Update) error {
+	if update.Message != nil && update.Message.Location != nil {
+		return h.services.Spending.Timezone(ctx, update)
+	}
+	handled, err := h.services.Spending.Dialog(ctx, update)
+	if handled {
+		return err
+	}
+	if update.Message != nil && update.Message.IsCommand() {
+		return h.services.Spending.NotFound(ctx, update)
 	}
 
-	return
+	return nil
 }
@@ -0,0 +1,147 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"time"
+)
+
+type Client interface {
+	Accounts(context.Context, int) ([]model.Account, error)
+	GetById(context.Context, int, int) (*model.Account, error)
+	AddAccount(context.Context, int, string, model.Currency, decimal.Decimal) (int, error)
+	DeleteAccount(context.Context, int, int) error
+	AddTransfer(context.Context, int, int, time.Time, decimal.Decimal) (int, error)
+	Balances(context.Context, int) (map[int]decimal.Decimal, error)
+}
+
+const (
+	accountTable  = "account"
+	transferTable = "account_transfer"
+	currencyTable = "currency"
+	eventTable    = "event"
+)
+
+var (
+	NotFoundError     = errors.New("account not found")
+	querySelectFields = `a.id, a.state_id, coalesce(a.currency_id, 0) as currency_id, ` +
+		`coalesce(c.abbreviation, '') as currency_abbr, a.title, a.opening_balance`
+	querySelectByState = fmt.Sprintf(`SELECT %s FROM %s as a
+									LEFT JOIN %s as c ON c.id = a.currency_id
+									WHERE a.state_id = $1 ORDER BY a.id`, querySelectFields, accountTable, currencyTable)
+	queryGetById = fmt.Sprintf(`SELECT %s FROM %s as a
+									LEFT JOIN %s as c ON c.id = a.currency_id
+									WHERE a.id = $1 AND a.state_id = $2`, querySelectFields, accountTable, currencyTable)
+	queryInsert = fmt.Sprintf(`INSERT INTO %s (state_id, currency_id, title, opening_balance)
+									values ($1, $2, $3, $4) RETURNING id`, accountTable)
+	queryDelete         = fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND state_id = $2`, accountTable)
+	queryInsertTransfer = fmt.Sprintf(`INSERT INTO %s (from_account_id, to_account_id, transfer_at, amount)
+									values ($1, $2, $3, $4) RETURNING id`, transferTable)
+	queryBalances = fmt.Sprintf(`SELECT a.id, a.opening_balance
+									- coalesce((SELECT sum(e.price) FROM %[2]s as e WHERE e.account_id = a.id), 0)
+									- coalesce((SELECT sum(t.amount) FROM %[3]s as t WHERE t.from_account_id = a.id), 0)
+									+ coalesce((SELECT sum(t.amount) FROM %[3]s as t WHERE t.to_account_id = a.id), 0)
+									as balance
+									FROM %[1]s as a WHERE a.state_id = $1`, accountTable, eventTable, transferTable)
+)
+
+type Account struct {
+	db *sqlx.DB
+}
+
+func NewAccount(db *sqlx.DB) *Account {
+	return &Account{
+		db: db,
+	}
+}
+
+func (a *Account) Accounts(ctx context.Context, stateId int) (as []model.Account, err error) {
+	var accountsDB []model.AccountDB
+	if err = a.db.SelectContext(ctx, &accountsDB, querySelectByState, stateId); err != nil {
+		return nil, errors.Wrap(err, "accounts by state")
+	}
+
+	for _, accountDB := range accountsDB {
+		as = append(as, toAccount(accountDB))
+	}
+
+	return
+}
+
+func (a *Account) GetById(ctx context.Context, stateId, id int) (acc *model.Account, err error) {
+	var accountDB model.AccountDB
+	if err = a.db.GetContext(ctx, &accountDB, queryGetById, id, stateId); err != nil {
+		return nil, NotFoundError
+	}
+	account := toAccount(accountDB)
+
+	return &account, nil
+}
+
+// AddAccount creates account, opening balance is stored in the default currency like event prices
+func (a *Account) AddAccount(ctx context.Context, stateId int, title string, curr model.Currency,
+	openingBalance decimal.Decimal) (accountId int, err error) {
+	row := a.db.QueryRowContext(ctx, queryInsert, stateId, curr.Id, title, openingBalance.Original())
+	err = row.Scan(&accountId)
+	if err != nil {
+		return 0, errors.Wrap(err, "insert account")
+	}
+
+	return
+}
+
+func (a *Account) DeleteAccount(ctx context.Context, stateId, id int) (err error) {
+	_, err = a.db.ExecContext(ctx, queryDelete, id, stateId)
+	if err != nil {
+		return errors.Wrap(err, "delete account")
+	}
+
+	return
+}
+
+func (a *Account) AddTransfer(ctx context.Context, fromId, toId int, date time.Time,
+	amount decimal.Decimal) (transferId int, err error) {
+	if fromId == toId {
+		return 0, errors.New("transfer to the same account")
+	}
+
+	row := a.db.QueryRowContext(ctx, queryInsertTransfer, fromId, toId, date.Format("2006-01-02"), amount.Original())
+	err = row.Scan(&transferId)
+	if err != nil {
+		return 0, errors.Wrap(err, "insert transfer")
+	}
+
+	return
+}
+
+// Balances returns running balance by account id in the default currency
+func (a *Account) Balances(ctx context.Context, stateId int) (m map[int]decimal.Decimal, err error) {
+	var balances []model.AccountBalanceDB
+	if err = a.db.SelectContext(ctx, &balances, queryBalances, stateId); err != nil {
+		return nil, errors.Wrap(err, "select balances")
+	}
+
+	m = make(map[int]decimal.Decimal, len(balances))
+	for _, balance := range balances {
+		m[balance.Id] = decimal.Decimal(balance.Balance)
+	}
+
+	return
+}
+
+func toAccount(accountDB model.AccountDB) model.Account {
+	return model.Account{
+		Id:      accountDB.Id,
+		StateId: accountDB.StateId,
+		Title:   accountDB.Title,
+		Currency: model.Currency{
+			Id:   accountDB.CurrencyId,
+			Abbr: accountDB.CurrencyAbbr,
+		},
+		OpeningBalance: accountDB.OpeningBalance,
+	}
+}
@@ -0,0 +1,135 @@
+package account
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+	"testing"
+	"time"
+)
+
+func TestAccount_Accounts(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func(db *sqlx.DB) {
+		_ = db.Close()
+	}(db)
+
+	r := NewAccount(db)
+
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		mock    func()
+		stateId int
+		want    []model.Account
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "state_id", "currency_id", "currency_abbr",
+					"title", "opening_balance"}).
+					AddRow(1, 5, 4, "RUB", "Cash", 1000000).
+					AddRow(2, 5, 1, "USD", "Card", 0)
+				mock.ExpectQuery("SELECT (.+) FROM account").
+					WithArgs(5).WillReturnRows(rows)
+			},
+			stateId: 5,
+			want: []model.Account{
+				{
+					Id:             1,
+					StateId:        5,
+					Title:          "Cash",
+					Currency:       model.Currency{Id: 4, Abbr: "RUB"},
+					OpeningBalance: 1000000,
+				},
+				{
+					Id:       2,
+					StateId:  5,
+					Title:    "Card",
+					Currency: model.Currency{Id: 1, Abbr: "USD"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.Accounts(ctx, tt.stateId)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAccount_AddTransfer(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func(db *sqlx.DB) {
+		_ = db.Close()
+	}(db)
+
+	r := NewAccount(db)
+
+	ctx := context.Background()
+	date := time.Date(2022, 11, 20, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		mock           func()
+		fromId, toId   int
+		amount         decimal.Decimal
+		wantTransferId int
+		wantErr        bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(3)
+				mock.ExpectQuery("INSERT INTO account_transfer").
+					WithArgs(1, 2, "2022-11-20", int64(1000000)).WillReturnRows(rows)
+			},
+			fromId:         1,
+			toId:           2,
+			amount:         decimal.ToDecimal(100),
+			wantTransferId: 3,
+		},
+		{
+			name:    "Same account",
+			mock:    func() {},
+			fromId:  1,
+			toId:    1,
+			amount:  decimal.ToDecimal(100),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.AddTransfer(ctx, tt.fromId, tt.toId, date, tt.amount)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantTransferId, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
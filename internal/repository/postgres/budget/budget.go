@@ -0,0 +1,159 @@
+package budget
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"time"
+)
+
+type Client interface {
+	GetBudget(context.Context, int, time.Time) (*model.Budget, error)
+	CreateBudget(context.Context, model.Budget) (*model.Budget, error)
+	SetTotal(context.Context, int, decimal.Decimal, bool) error
+	SetPlan(context.Context, int, int, decimal.Decimal) error
+}
+
+const (
+	budgetTable     = "budget"
+	budgetPlanTable = "budget_plan"
+	categoryTable   = "category"
+)
+
+var (
+	NotFoundError = errors.New("budget not found")
+	querySelect   = fmt.Sprintf(`SELECT id, state_id, month_at, total, carried, rollover FROM %s
+										WHERE state_id = $1 AND month_at = $2`, budgetTable)
+	querySelectPlans = fmt.Sprintf(`SELECT bp.category_id, c.title as category_title, bp.planned
+										FROM %s as bp
+										LEFT JOIN %s as c ON c.id = bp.category_id
+										WHERE bp.budget_id = $1 ORDER BY c.title`, budgetPlanTable, categoryTable)
+	queryInsert = fmt.Sprintf(`INSERT INTO %s (state_id, month_at, total, carried, rollover)
+										values ($1, $2, $3, $4, $5)
+										ON CONFLICT (state_id, month_at) DO NOTHING RETURNING id`, budgetTable)
+	queryUpdateTotal = fmt.Sprintf(`UPDATE %s SET total = $1, rollover = $2 WHERE id = $3`, budgetTable)
+	queryUpsertPlan  = fmt.Sprintf(`INSERT INTO %s (budget_id, category_id, planned) values ($1, $2, $3)
+										ON CONFLICT (budget_id, category_id) DO UPDATE SET planned = $3`, budgetPlanTable)
+	queryDeletePlan = fmt.Sprintf(`DELETE FROM %s WHERE budget_id = $1 AND category_id = $2`, budgetPlanTable)
+)
+
+type Budget struct {
+	db *sqlx.DB
+}
+
+func NewBudget(db *sqlx.DB) *Budget {
+	return &Budget{
+		db: db,
+	}
+}
+
+// GetBudget returns budget of state with plans by month with first day of month
+func (b *Budget) GetBudget(ctx context.Context, stateId int, month time.Time) (*model.Budget, error) {
+	var budgetDB model.BudgetDB
+	err := b.db.GetContext(ctx, &budgetDB, querySelect, stateId, month.Format("2006-01-02"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, NotFoundError
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "get budget")
+	}
+
+	var plansDB []model.BudgetPlanDB
+	if err = b.db.SelectContext(ctx, &plansDB, querySelectPlans, budgetDB.Id); err != nil {
+		return nil, errors.Wrap(err, "get budget plans")
+	}
+
+	budget := &model.Budget{
+		Id:       budgetDB.Id,
+		StateId:  budgetDB.StateId,
+		Month:    budgetDB.Month,
+		Total:    budgetDB.Total,
+		Carried:  budgetDB.Carried,
+		Rollover: budgetDB.Rollover,
+	}
+	for _, planDB := range plansDB {
+		budget.Plans = append(budget.Plans, model.BudgetPlan{
+			Category: model.Category{
+				Id:    planDB.CategoryId,
+				Title: planDB.CategoryTitle,
+			},
+			Planned: planDB.Planned,
+		})
+	}
+
+	return budget, nil
+}
+
+// CreateBudget adds budget of month with plans, if budget of month is already created
+// by concurrent request then it is returned unchanged
+func (b *Budget) CreateBudget(ctx context.Context, budget model.Budget) (*model.Budget, error) {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return nil, errors.Wrap(err, "create budget tx begin")
+	}
+
+	var budgetId int
+	row := tx.QueryRowContext(ctx, queryInsert, budget.StateId, budget.Month.Format("2006-01-02"),
+		budget.Total, budget.Carried, budget.Rollover)
+	err = row.Scan(&budgetId)
+	if errors.Is(err, sql.ErrNoRows) {
+		errRoll := tx.Rollback()
+		if errRoll != nil {
+			return nil, errors.Wrap(errRoll, "create budget rollback")
+		}
+		return b.GetBudget(ctx, budget.StateId, budget.Month)
+	}
+	if err != nil {
+		errRoll := tx.Rollback()
+		if errRoll != nil {
+			return nil, errors.Wrap(errRoll, "create budget rollback")
+		}
+		return nil, errors.Wrap(err, "insert budget")
+	}
+
+	for _, plan := range budget.Plans {
+		_, err = tx.ExecContext(ctx, queryUpsertPlan, budgetId, plan.Category.Id, plan.Planned)
+		if err != nil {
+			errRoll := tx.Rollback()
+			if errRoll != nil {
+				return nil, errors.Wrap(errRoll, "create budget rollback")
+			}
+			return nil, errors.Wrap(err, "insert budget plan")
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, "create budget tx commit")
+	}
+	budget.Id = budgetId
+
+	return &budget, nil
+}
+
+func (b *Budget) SetTotal(ctx context.Context, budgetId int, total decimal.Decimal, rollover bool) (err error) {
+	_, err = b.db.ExecContext(ctx, queryUpdateTotal, total.Original(), rollover, budgetId)
+	if err != nil {
+		return errors.Wrap(err, "update budget total")
+	}
+
+	return
+}
+
+// SetPlan assigns planned amount to category, zero amount removes plan
+func (b *Budget) SetPlan(ctx context.Context, budgetId, categoryId int, planned decimal.Decimal) (err error) {
+	if planned <= 0 {
+		_, err = b.db.ExecContext(ctx, queryDeletePlan, budgetId, categoryId)
+	} else {
+		_, err = b.db.ExecContext(ctx, queryUpsertPlan, budgetId, categoryId, planned.Original())
+	}
+	if err != nil {
+		return errors.Wrap(err, "set budget plan")
+	}
+
+	return
+}
@@ -0,0 +1,81 @@
+package budget
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+	"testing"
+	"time"
+)
+
+func TestBudget_GetBudget(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func(db *sqlx.DB) {
+		_ = db.Close()
+	}(db)
+
+	r := NewBudget(db)
+
+	ctx := context.Background()
+	month := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		mock    func()
+		want    *model.Budget
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "state_id", "month_at", "total", "carried", "rollover"}).
+					AddRow(1, 5, month, 500000000, 10000000, true)
+				mock.ExpectQuery("SELECT (.+) FROM budget").
+					WithArgs(5, "2022-11-01").WillReturnRows(rows)
+				plans := sqlmock.NewRows([]string{"category_id", "category_title", "planned"}).
+					AddRow(2, "Food", 100000000)
+				mock.ExpectQuery("SELECT (.+) FROM budget_plan").
+					WithArgs(1).WillReturnRows(plans)
+			},
+			want: &model.Budget{
+				Id:       1,
+				StateId:  5,
+				Month:    month,
+				Total:    500000000,
+				Carried:  10000000,
+				Rollover: true,
+				Plans: []model.BudgetPlan{
+					{Category: model.Category{Id: 2, Title: "Food"}, Planned: 100000000},
+				},
+			},
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "state_id", "month_at", "total", "carried", "rollover"})
+				mock.ExpectQuery("SELECT (.+) FROM budget").
+					WithArgs(5, "2022-11-01").WillReturnRows(rows)
+			},
+			wantErr: NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.GetBudget(ctx, 5, month)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -17,15 +17,21 @@ type Search interface {
 
 const (
 	categoryTable = "category"
+	eventTable    = "event"
 )
 
 var (
 	NotFoundError   = errors.New("category not found")
+	ExistsError     = errors.New("category already exists")
+	InUseError      = errors.New("category has events in other states")
 	querySelectAll  = fmt.Sprintf(`SELECT id, title FROM %s`, categoryTable)
 	queryInsert     = fmt.Sprintf("INSERT INTO %s (title) values ($1) RETURNING id", categoryTable)
 	queryDelete     = fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, categoryTable)
+	queryUpdate     = fmt.Sprintf(`UPDATE %s SET title = $1 WHERE id = $2`, categoryTable)
 	queryGetById    = fmt.Sprintf(`SELECT id, title FROM %s WHERE id=$1`, categoryTable)
 	queryGetByTitle = fmt.Sprintf(`SELECT id, title FROM %s WHERE title=$1`, categoryTable)
+	queryStates     = fmt.Sprintf(`SELECT DISTINCT coalesce(state_id, 0) FROM %s WHERE category_id = $1`,
+		eventTable)
 )
 
 type Category struct {
@@ -61,6 +67,23 @@ func (s *Category) AddCategory(ctx context.Context, title string) (categoryId in
 	return
 }
 
+// UpdateCategory renames category, title of another category is not taken
+func (s *Category) UpdateCategory(ctx context.Context, id int, title string) (err error) {
+	if cat, errTitle := s.CategoryGetByTitle(ctx, title); errTitle == nil && cat.Id != id {
+		return ExistsError
+	}
+
+	res, err := s.db.ExecContext(ctx, queryUpdate, title, id)
+	if err != nil {
+		return errors.Wrap(err, "update category")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return NotFoundError
+	}
+
+	return
+}
+
 func (s *Category) DeleteCategory(ctx context.Context, id int) (err error) {
 	_, err = s.db.ExecContext(ctx, queryDelete, id)
 	if err != nil {
@@ -70,6 +93,15 @@ func (s *Category) DeleteCategory(ctx context.Context, id int) (err error) {
 	return
 }
 
+// CategoryStates returns states having events of category, events without state are in state 0
+func (s *Category) CategoryStates(ctx context.Context, id int) (stateIds []int, err error) {
+	if err = s.db.SelectContext(ctx, &stateIds, queryStates, id); err != nil {
+		return nil, errors.Wrap(err, "category states")
+	}
+
+	return
+}
+
 func (s *Category) CategoryGetById(ctx context.Context, id int) (cat *model.Category, err error) {
 	var c model.Category
 	if err = s.db.GetContext(ctx, &c, queryGetById, id); err != nil {
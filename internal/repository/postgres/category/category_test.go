@@ -177,6 +177,75 @@ func TestCategory_DeleteCategory(t *testing.T) {
 	}
 }
 
+func TestCategory_UpdateCategory(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func(db *sqlx.DB) {
+		_ = db.Close()
+	}(db)
+
+	r := NewCategory(db)
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		mock    func()
+		id      int
+		title   string
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM category WHERE (.+)").
+					WithArgs("Food").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("UPDATE category SET (.+)").
+					WithArgs("Food", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			id:    1,
+			title: "Food",
+		},
+		{
+			name: "Title taken",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "title"}).AddRow(2, "Food")
+				mock.ExpectQuery("SELECT (.+) FROM category WHERE (.+)").
+					WithArgs("Food").WillReturnRows(rows)
+			},
+			id:      1,
+			title:   "Food",
+			wantErr: ExistsError,
+		},
+		{
+			name: "Not Found",
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM category WHERE (.+)").
+					WithArgs("Food").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("UPDATE category SET (.+)").
+					WithArgs("Food", 3).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			id:      3,
+			title:   "Food",
+			wantErr: NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err = r.UpdateCategory(ctx, tt.id, tt.title)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestCategory_CategoryGetById(t *testing.T) {
 	db, mock, err := sqlmock.Newx()
 	if err != nil {
@@ -276,3 +345,23 @@ func TestCategory_CategoryGetByTitle(t *testing.T) {
 		})
 	}
 }
+
+func TestCategory_CategoryStates(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func(db *sqlx.DB) {
+		_ = db.Close()
+	}(db)
+
+	r := NewCategory(db)
+	rows := sqlmock.NewRows([]string{"state_id"}).AddRow(0).AddRow(5)
+	mock.ExpectQuery("SELECT DISTINCT (.+) FROM event WHERE (.+)").
+		WithArgs(2).WillReturnRows(rows)
+
+	got, err := r.CategoryStates(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 5}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,40 +2,56 @@ package category_limit
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/category"
 	"github.com/sku4/ozon-route256-spending-bot/model"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"time"
 )
 
 type CategoryLimitSet interface {
-	Set(ctx context.Context, stateId, categoryId int, limit decimal.Decimal) (*CategoryLimit, error)
+	Set(ctx context.Context, stateId, categoryId int, limit decimal.Decimal, r period.Range) (*CategoryLimit, error)
 	GetByState(ctx context.Context, stateId int) ([]*CategoryLimit, error)
 	Create(ctx context.Context, cat *model.Category, limitDB *model.CategoryLimitDB) *CategoryLimit
+	Delete(ctx context.Context, stateId, limitId int) error
+	ClaimThreshold(ctx context.Context, limitId, threshold int, periodAt time.Time) (bool, error)
+	ReleaseThresholds(ctx context.Context, limitId, percent int, periodAt time.Time) error
 }
 
 const (
 	categoryLimitTable = "category_limit"
+	limitNoticeTable   = "category_limit_notice"
 	categoryTable      = "category"
 )
 
 var (
-	queryInsertDoUpdate = fmt.Sprintf(`INSERT INTO %s (state_id, category_id, category_limit) 
-												values ($1, $2, $3) 
+	NotFoundError       = errors.New("limit not found")
+	queryInsertDoUpdate = fmt.Sprintf(`INSERT INTO %s (state_id, category_id, category_limit, period, period_days) 
+												values ($1, $2, $3, $4, $5) 
 												ON CONFLICT (state_id, category_id) DO UPDATE 
-												SET category_limit = $3 RETURNING id`, categoryLimitTable)
+												SET category_limit = $3, period = $4, period_days = $5 RETURNING id`, categoryLimitTable)
 	querySelectByState = fmt.Sprintf(`SELECT
-									cl.id, cl.state_id, cl.category_id, c.title as category_title, cl.category_limit 
+									cl.id, cl.state_id, cl.category_id, c.title as category_title, cl.category_limit,
+									cl.period, cl.period_days
 									FROM %s as cl
 									LEFT JOIN %s as c ON cl.category_id = c.id
-									WHERE state_id = $1`, categoryLimitTable, categoryTable)
+									WHERE state_id = $1 ORDER BY cl.id`, categoryLimitTable, categoryTable)
+	queryDelete         = fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND state_id = $2`, categoryLimitTable)
+	queryClaimThreshold = fmt.Sprintf(`INSERT INTO %s (category_limit_id, threshold, period_at) values ($1, $2, $3)
+									ON CONFLICT (category_limit_id, threshold, period_at) DO NOTHING RETURNING id`,
+		limitNoticeTable)
+	queryReleaseThresholds = fmt.Sprintf(`DELETE FROM %s WHERE category_limit_id = $1 AND threshold > $2
+									AND period_at = $3`, limitNoticeTable)
 )
 
 type CategoryLimit struct {
 	model.CategoryLimit
 	Limit          decimal.Decimal
+	Range          period.Range
 	db             *sqlx.DB
 	categorySearch category.Search
 }
@@ -47,7 +63,8 @@ func NewCategoryLimit(db *sqlx.DB, categorySearch category.Search) *CategoryLimi
 	}
 }
 
-func (cl *CategoryLimit) Set(ctx context.Context, stateId, categoryId int, limit decimal.Decimal) (s *CategoryLimit, err error) {
+func (cl *CategoryLimit) Set(ctx context.Context, stateId, categoryId int, limit decimal.Decimal,
+	r period.Range) (s *CategoryLimit, err error) {
 	tx, err := cl.db.Begin()
 	if err != nil {
 		return nil, errors.Wrap(err, "limit tx begin")
@@ -63,7 +80,7 @@ func (cl *CategoryLimit) Set(ctx context.Context, stateId, categoryId int, limit
 	}
 
 	var categoryLimitId int
-	row := tx.QueryRowContext(ctx, queryInsertDoUpdate, stateId, cat.Id, limit.Original())
+	row := tx.QueryRowContext(ctx, queryInsertDoUpdate, stateId, cat.Id, limit.Original(), r.Period, r.Days)
 	err = row.Scan(&categoryLimitId)
 	if err != nil {
 		errRoll := tx.Rollback()
@@ -82,8 +99,11 @@ func (cl *CategoryLimit) Set(ctx context.Context, stateId, categoryId int, limit
 		CategoryLimit: model.CategoryLimit{
 			Id:       categoryLimitId,
 			Category: cat,
+			Period:   string(r.Period),
+			Days:     r.Days,
 		},
 		Limit: limit,
+		Range: r,
 	}, nil
 }
 
@@ -101,8 +121,11 @@ func (cl *CategoryLimit) GetByState(ctx context.Context, stateId int) (cls []*Ca
 					Id:    limitDB.CategoryId,
 					Title: limitDB.CategoryTitle,
 				},
+				Period: limitDB.Period,
+				Days:   limitDB.Days,
 			},
 			Limit:          decimal.Decimal(limitDB.Limit),
+			Range:          toRange(limitDB),
 			db:             cl.db,
 			categorySearch: cl.categorySearch,
 		}
@@ -119,9 +142,64 @@ func (cl *CategoryLimit) Create(ctx context.Context, cat *model.Category, limitD
 		CategoryLimit: model.CategoryLimit{
 			Id:       limitDB.Id,
 			Category: cat,
+			Period:   limitDB.Period,
+			Days:     limitDB.Days,
 		},
 		Limit:          decimal.Decimal(limitDB.Limit),
+		Range:          toRange(limitDB),
 		db:             cl.db,
 		categorySearch: cl.categorySearch,
 	}
 }
+
+func (cl *CategoryLimit) Delete(ctx context.Context, stateId, limitId int) (err error) {
+	res, err := cl.db.ExecContext(ctx, queryDelete, limitId, stateId)
+	if err != nil {
+		return errors.Wrap(err, "delete limit")
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "delete limit rows")
+	}
+	if affected == 0 {
+		return NotFoundError
+	}
+
+	return
+}
+
+// ClaimThreshold marks threshold of limit as notified in period started at periodAt,
+// returns false if it was notified before
+func (cl *CategoryLimit) ClaimThreshold(ctx context.Context, limitId, threshold int, periodAt time.Time) (ok bool, err error) {
+	var noticeId int
+	row := cl.db.QueryRowContext(ctx, queryClaimThreshold, limitId, threshold, periodAt.Format("2006-01-02"))
+	err = row.Scan(&noticeId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "claim limit threshold")
+	}
+
+	return true, nil
+}
+
+// ReleaseThresholds forgets notices of thresholds over percent in period started at periodAt,
+// so they are notified again when spending reaches them
+func (cl *CategoryLimit) ReleaseThresholds(ctx context.Context, limitId, percent int, periodAt time.Time) error {
+	if _, err := cl.db.ExecContext(ctx, queryReleaseThresholds, limitId, percent,
+		periodAt.Format("2006-01-02")); err != nil {
+		return errors.Wrap(err, "release limit thresholds")
+	}
+
+	return nil
+}
+
+// toRange reads limit period, limits without period are monthly
+func toRange(limitDB *model.CategoryLimitDB) period.Range {
+	if limitDB.Period == "" {
+		return period.Range{Period: period.PeriodMonth}
+	}
+
+	return period.Range{Period: period.Period(limitDB.Period), Days: limitDB.Days}
+}
@@ -0,0 +1,123 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/state"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+)
+
+type Client interface {
+	AddChat(context.Context, int64) (*state.State, error)
+	AddMember(context.Context, int, int) error
+	Members(context.Context, int) ([]model.Member, error)
+}
+
+const (
+	chatTable   = "chat"
+	memberTable = "ledger_member"
+	userTable   = "user"
+)
+
+var (
+	querySelect = fmt.Sprintf(`SELECT state_id FROM %s WHERE chat_id = $1`, chatTable)
+	queryInsert = fmt.Sprintf(`INSERT INTO %s (chat_id, state_id) values ($1, $2)
+									ON CONFLICT (chat_id) DO NOTHING RETURNING id`, chatTable)
+	queryInsertMember = fmt.Sprintf(`INSERT INTO %s (state_id, user_id) values ($1, $2)
+									ON CONFLICT (state_id, user_id) DO NOTHING`, memberTable)
+	querySelectMembers = fmt.Sprintf(`SELECT m.user_id, coalesce(u.name, '') as name FROM %s as m
+									INNER JOIN "%s" as u ON u.id = m.user_id
+									WHERE m.state_id = $1 ORDER BY m.id`, memberTable, userTable)
+)
+
+// Chats keeps shared ledgers of group chats, ledger is state of its own like state of user
+type Chats struct {
+	db         *sqlx.DB
+	reposState state.Client
+}
+
+func NewChats(db *sqlx.DB, reposState state.Client) *Chats {
+	return &Chats{
+		db:         db,
+		reposState: reposState,
+	}
+}
+
+// AddChat returns state of ledger of group chat, ledger is created with first message of group
+func (c *Chats) AddChat(ctx context.Context, chatId int64) (st *state.State, err error) {
+	var stateId int
+	err = c.db.GetContext(ctx, &stateId, querySelect, chatId)
+	if err == nil {
+		return c.reposState.GetById(ctx, stateId)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "chat ledger")
+	}
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return nil, errors.Wrap(err, "add chat tx begin")
+	}
+
+	st, err = c.reposState.AddStateTx(ctx, tx)
+	if err != nil {
+		errRoll := tx.Rollback()
+		if errRoll != nil {
+			return nil, errors.Wrap(errRoll, "add chat rollback")
+		}
+		return nil, errors.Wrap(err, "add chat state")
+	}
+
+	var id int
+	err = tx.QueryRowContext(ctx, queryInsert, chatId, st.Id).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		// ledger is created by concurrent message of the same group
+		errRoll := tx.Rollback()
+		if errRoll != nil {
+			return nil, errors.Wrap(errRoll, "add chat rollback")
+		}
+		if err = c.db.GetContext(ctx, &stateId, querySelect, chatId); err != nil {
+			return nil, errors.Wrap(err, "chat ledger")
+		}
+		return c.reposState.GetById(ctx, stateId)
+	}
+	if err != nil {
+		errRoll := tx.Rollback()
+		if errRoll != nil {
+			return nil, errors.Wrap(errRoll, "add chat rollback")
+		}
+		return nil, errors.Wrap(err, "insert chat")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "add chat tx commit")
+	}
+
+	return
+}
+
+// AddMember joins user to ledger of group chat, members share expenses split in the group
+func (c *Chats) AddMember(ctx context.Context, stateId, userId int) (err error) {
+	if _, err = c.db.ExecContext(ctx, queryInsertMember, stateId, userId); err != nil {
+		return errors.Wrap(err, "add ledger member")
+	}
+
+	return
+}
+
+// Members returns members of ledger in order of joining
+func (c *Chats) Members(ctx context.Context, stateId int) (members []model.Member, err error) {
+	var membersDB []model.MemberDB
+	if err = c.db.SelectContext(ctx, &membersDB, querySelectMembers, stateId); err != nil {
+		return nil, errors.Wrap(err, "ledger members")
+	}
+
+	for _, memberDB := range membersDB {
+		members = append(members, model.Member{UserId: memberDB.UserId, Name: memberDB.Name})
+	}
+
+	return
+}
@@ -0,0 +1,145 @@
+package debt
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+)
+
+type Client interface {
+	Split(context.Context, int, int, int, map[int]decimal.Decimal) error
+	Debts(context.Context, int) ([]model.Debt, error)
+	Settle(context.Context, int, int, int, decimal.Decimal) error
+}
+
+const (
+	splitTable      = "split"
+	debtTable       = "debt"
+	settlementTable = "settlement"
+	userTable       = "user"
+)
+
+var (
+	AlreadySplitError = errors.New("event already split")
+	queryInsertSplit  = fmt.Sprintf(`INSERT INTO %s (state_id, event_id, payer_id) values ($1, $2, $3)
+										ON CONFLICT (event_id) DO NOTHING RETURNING id`, splitTable)
+	queryAddDebt = fmt.Sprintf(`INSERT INTO %s (state_id, debtor_id, creditor_id, amount) values ($1, $2, $3, $4)
+										ON CONFLICT (state_id, debtor_id, creditor_id)
+										DO UPDATE SET amount = %s.amount + excluded.amount`, debtTable, debtTable)
+	querySelectDebts = fmt.Sprintf(`SELECT d.debtor_id, coalesce(du.name, '') as debtor_name,
+										d.creditor_id, coalesce(cu.name, '') as creditor_name, d.amount
+										FROM %s as d
+										LEFT JOIN "%s" as du ON du.id = d.debtor_id
+										LEFT JOIN "%s" as cu ON cu.id = d.creditor_id
+										WHERE d.state_id = $1 AND d.amount <> 0
+										ORDER BY d.debtor_id, d.creditor_id`, debtTable, userTable, userTable)
+	queryInsertSettlement = fmt.Sprintf(`INSERT INTO %s (state_id, from_user_id, to_user_id, amount)
+										values ($1, $2, $3, $4)`, settlementTable)
+)
+
+type Debt struct {
+	db *sqlx.DB
+}
+
+func NewDebt(db *sqlx.DB) *Debt {
+	return &Debt{
+		db: db,
+	}
+}
+
+// Split records event paid by payerId as split among members by shares in default currency,
+// every member except payer owes payer his share
+func (d *Debt) Split(ctx context.Context, stateId, eventId, payerId int, shares map[int]decimal.Decimal) (err error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "split tx begin")
+	}
+
+	var splitId int
+	err = tx.QueryRowContext(ctx, queryInsertSplit, stateId, eventId, payerId).Scan(&splitId)
+	if errors.Is(err, sql.ErrNoRows) {
+		errRoll := tx.Rollback()
+		if errRoll != nil {
+			return errors.Wrap(errRoll, "split rollback")
+		}
+		return AlreadySplitError
+	}
+	if err != nil {
+		errRoll := tx.Rollback()
+		if errRoll != nil {
+			return errors.Wrap(errRoll, "split rollback")
+		}
+		return errors.Wrap(err, "insert split")
+	}
+
+	for userId, share := range shares {
+		if userId == payerId || share == 0 {
+			continue
+		}
+		if _, err = tx.ExecContext(ctx, queryAddDebt, stateId, userId, payerId, share.Original()); err != nil {
+			errRoll := tx.Rollback()
+			if errRoll != nil {
+				return errors.Wrap(errRoll, "split rollback")
+			}
+			return errors.Wrap(err, "add debt")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "split tx commit")
+	}
+
+	return
+}
+
+// Debts returns amounts members of ledger owe each other, both directions of pair may be present
+func (d *Debt) Debts(ctx context.Context, stateId int) (ds []model.Debt, err error) {
+	var debtsDB []model.DebtDB
+	if err = d.db.SelectContext(ctx, &debtsDB, querySelectDebts, stateId); err != nil {
+		return nil, errors.Wrap(err, "debts")
+	}
+
+	for _, debtDB := range debtsDB {
+		ds = append(ds, model.Debt{
+			Debtor:   model.Member{UserId: debtDB.DebtorId, Name: debtDB.DebtorName},
+			Creditor: model.Member{UserId: debtDB.CreditorId, Name: debtDB.CreditorName},
+			Amount:   debtDB.Amount,
+		})
+	}
+
+	return
+}
+
+// Settle records payment of amount in default currency from member fromId to member toId,
+// the payment is debt of receiver to payer which nets debts of the pair
+func (d *Debt) Settle(ctx context.Context, stateId, fromId, toId int, amount decimal.Decimal) (err error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "settle tx begin")
+	}
+
+	if _, err = tx.ExecContext(ctx, queryInsertSettlement, stateId, fromId, toId, amount.Original()); err != nil {
+		errRoll := tx.Rollback()
+		if errRoll != nil {
+			return errors.Wrap(errRoll, "settle rollback")
+		}
+		return errors.Wrap(err, "insert settlement")
+	}
+	if _, err = tx.ExecContext(ctx, queryAddDebt, stateId, toId, fromId, amount.Original()); err != nil {
+		errRoll := tx.Rollback()
+		if errRoll != nil {
+			return errors.Wrap(errRoll, "settle rollback")
+		}
+		return errors.Wrap(err, "settle debt")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "settle tx commit")
+	}
+
+	return
+}
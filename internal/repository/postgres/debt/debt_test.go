@@ -0,0 +1,146 @@
+package debt
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+	"testing"
+)
+
+func TestDebt_Split(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func(db *sqlx.DB) {
+		_ = db.Close()
+	}(db)
+
+	r := NewDebt(db)
+
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		mock    func()
+		shares  map[int]decimal.Decimal
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery("INSERT INTO split").
+					WithArgs(5, 10, 1).WillReturnRows(rows)
+				mock.ExpectExec("INSERT INTO debt").
+					WithArgs(5, 2, 1, int64(500000)).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			shares: map[int]decimal.Decimal{1: 500000, 2: 500000},
+		},
+		{
+			name: "Already split",
+			mock: func() {
+				mock.ExpectBegin()
+				rows := sqlmock.NewRows([]string{"id"})
+				mock.ExpectQuery("INSERT INTO split").
+					WithArgs(5, 10, 1).WillReturnRows(rows)
+				mock.ExpectRollback()
+			},
+			shares:  map[int]decimal.Decimal{1: 500000, 2: 500000},
+			wantErr: AlreadySplitError,
+		},
+		{
+			name: "Debt error",
+			mock: func() {
+				mock.ExpectBegin()
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery("INSERT INTO split").
+					WithArgs(5, 10, 1).WillReturnRows(rows)
+				mock.ExpectExec("INSERT INTO debt").
+					WithArgs(5, 2, 1, int64(500000)).WillReturnError(errors.New("insert error"))
+				mock.ExpectRollback()
+			},
+			shares:  map[int]decimal.Decimal{1: 500000, 2: 500000},
+			wantErr: errors.New("add debt: insert error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.Split(ctx, 5, 10, 1, tt.shares)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDebt_Debts(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func(db *sqlx.DB) {
+		_ = db.Close()
+	}(db)
+
+	r := NewDebt(db)
+
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		mock    func()
+		want    []model.Debt
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"debtor_id", "debtor_name", "creditor_id", "creditor_name", "amount"}).
+					AddRow(2, "Bob", 1, "Anna", 500000)
+				mock.ExpectQuery("SELECT (.+) FROM debt").
+					WithArgs(5).WillReturnRows(rows)
+			},
+			want: []model.Debt{
+				{
+					Debtor:   model.Member{UserId: 2, Name: "Bob"},
+					Creditor: model.Member{UserId: 1, Name: "Anna"},
+					Amount:   500000,
+				},
+			},
+		},
+		{
+			name: "Error",
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM debt").
+					WithArgs(5).WillReturnError(errors.New("select error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.Debts(ctx, 5)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
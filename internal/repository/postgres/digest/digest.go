@@ -0,0 +1,139 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"time"
+)
+
+type Client interface {
+	Digests(context.Context, int) ([]model.Digest, error)
+	Due(context.Context, time.Time) ([]model.Digest, error)
+	AddDigest(context.Context, model.Digest) (int, error)
+	DeleteDigest(context.Context, int, int) error
+	Claim(context.Context, model.Digest, time.Time) (bool, error)
+}
+
+const (
+	digestTable   = "digest"
+	stateTable    = "state"
+	currencyTable = "currency"
+)
+
+var (
+	querySelect = fmt.Sprintf(`SELECT d.id, d.state_id, d.chat_id, d.period, d.clock, d.next_at,
+										coalesce(st.currency_id, 0) as currency_id,
+										coalesce(cur.abbreviation, '') as currency_abbr,
+										coalesce(st.week_start, 1) as week_start,
+										coalesce(st.month_start, 1) as month_start,
+										coalesce(st.timezone, '') as timezone, d.language
+										FROM %s as d
+										LEFT JOIN %s as st ON st.id = d.state_id
+										LEFT JOIN %s as cur ON cur.id = st.currency_id`,
+		digestTable, stateTable, currencyTable)
+	querySelectByState = querySelect + ` WHERE d.state_id = $1 ORDER BY d.id`
+	querySelectDue     = querySelect + ` WHERE d.next_at <= $1 ORDER BY d.next_at`
+	queryInsert        = fmt.Sprintf(`INSERT INTO %s (state_id, chat_id, period, clock, next_at, language)
+										values ($1, $2, $3, $4, $5, $6)
+										ON CONFLICT (chat_id, period) DO UPDATE
+										SET state_id = excluded.state_id, clock = excluded.clock,
+										next_at = excluded.next_at, language = excluded.language
+										RETURNING id`, digestTable)
+	queryDelete = fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND state_id = $2`, digestTable)
+	queryClaim  = fmt.Sprintf(`UPDATE %s SET next_at = $1 WHERE id = $2 AND next_at = $3`, digestTable)
+)
+
+type Digest struct {
+	db *sqlx.DB
+}
+
+func NewDigest(db *sqlx.DB) *Digest {
+	return &Digest{
+		db: db,
+	}
+}
+
+func (d *Digest) Digests(ctx context.Context, stateId int) (ds []model.Digest, err error) {
+	var digestsDB []model.DigestDB
+	if err = d.db.SelectContext(ctx, &digestsDB, querySelectByState, stateId); err != nil {
+		return nil, errors.Wrap(err, "digests by state")
+	}
+
+	for _, digestDB := range digestsDB {
+		ds = append(ds, toDigest(digestDB))
+	}
+
+	return
+}
+
+// Due returns digests which should be sent at t
+func (d *Digest) Due(ctx context.Context, t time.Time) (ds []model.Digest, err error) {
+	var digestsDB []model.DigestDB
+	if err = d.db.SelectContext(ctx, &digestsDB, querySelectDue, t); err != nil {
+		return nil, errors.Wrap(err, "due digests")
+	}
+
+	for _, digestDB := range digestsDB {
+		ds = append(ds, toDigest(digestDB))
+	}
+
+	return
+}
+
+// AddDigest subscribes chat to period, repeated subscription changes time of existing one
+func (d *Digest) AddDigest(ctx context.Context, digest model.Digest) (digestId int, err error) {
+	row := d.db.QueryRowContext(ctx, queryInsert, digest.StateId, digest.ChatId, digest.Period,
+		digest.Clock, digest.NextAt, digest.Language)
+	err = row.Scan(&digestId)
+	if err != nil {
+		return 0, errors.Wrap(err, "insert digest")
+	}
+
+	return
+}
+
+func (d *Digest) DeleteDigest(ctx context.Context, stateId, id int) (err error) {
+	_, err = d.db.ExecContext(ctx, queryDelete, id, stateId)
+	if err != nil {
+		return errors.Wrap(err, "delete digest")
+	}
+
+	return
+}
+
+// Claim moves digest to the next send time only if nobody moved it before,
+// so the digest is sent once by one of concurrent instances
+func (d *Digest) Claim(ctx context.Context, digest model.Digest, next time.Time) (ok bool, err error) {
+	res, err := d.db.ExecContext(ctx, queryClaim, next, digest.Id, digest.NextAt)
+	if err != nil {
+		return false, errors.Wrap(err, "claim digest")
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "claim digest rows")
+	}
+
+	return affected == 1, nil
+}
+
+func toDigest(digestDB model.DigestDB) model.Digest {
+	return model.Digest{
+		Id:      digestDB.Id,
+		StateId: digestDB.StateId,
+		ChatId:  digestDB.ChatId,
+		Period:  digestDB.Period,
+		Clock:   digestDB.Clock,
+		NextAt:  digestDB.NextAt,
+		Currency: model.Currency{
+			Id:   digestDB.CurrencyId,
+			Abbr: digestDB.CurrencyAbbr,
+		},
+		WeekStart:  digestDB.WeekStart,
+		MonthStart: digestDB.MonthStart,
+		Timezone:   digestDB.Timezone,
+		Language:   digestDB.Language,
+	}
+}
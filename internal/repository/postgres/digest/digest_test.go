@@ -0,0 +1,135 @@
+package digest
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+	"testing"
+	"time"
+)
+
+func TestDigest_Due(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func(db *sqlx.DB) {
+		_ = db.Close()
+	}(db)
+
+	r := NewDigest(db)
+
+	ctx := context.Background()
+	now := time.Date(2022, 11, 20, 20, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		mock    func()
+		want    []model.Digest
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "state_id", "chat_id", "period", "clock", "next_at",
+					"currency_id", "currency_abbr", "week_start", "month_start", "timezone", "language"}).
+					AddRow(1, 5, 100, "week", 1200, now, 4, "RUB", 0, 10, "Asia/Vladivostok", "ru")
+				mock.ExpectQuery("SELECT (.+) FROM digest (.+) WHERE d.next_at <=").
+					WithArgs(now).WillReturnRows(rows)
+			},
+			want: []model.Digest{
+				{
+					Id:         1,
+					StateId:    5,
+					ChatId:     100,
+					Period:     "week",
+					Clock:      1200,
+					NextAt:     now,
+					Currency:   model.Currency{Id: 4, Abbr: "RUB"},
+					MonthStart: 10,
+					Timezone:   "Asia/Vladivostok",
+					Language:   "ru",
+				},
+			},
+		},
+		{
+			name: "Empty",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "state_id", "chat_id", "period", "clock", "next_at",
+					"currency_id", "currency_abbr", "week_start", "month_start", "timezone", "language"})
+				mock.ExpectQuery("SELECT (.+) FROM digest (.+) WHERE d.next_at <=").
+					WithArgs(now).WillReturnRows(rows)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.Due(ctx, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDigest_Claim(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func(db *sqlx.DB) {
+		_ = db.Close()
+	}(db)
+
+	r := NewDigest(db)
+
+	ctx := context.Background()
+	digest := model.Digest{Id: 1, NextAt: time.Date(2022, 11, 20, 20, 0, 0, 0, time.UTC)}
+	next := time.Date(2022, 11, 27, 20, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		mock    func()
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectExec("UPDATE digest SET next_at").
+					WithArgs(next, digest.Id, digest.NextAt).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			want: true,
+		},
+		{
+			name: "Already claimed",
+			mock: func() {
+				mock.ExpectExec("UPDATE digest SET next_at").
+					WithArgs(next, digest.Id, digest.NextAt).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.Claim(ctx, digest, next)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package goal
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"time"
+)
+
+type Client interface {
+	Goals(context.Context, int) ([]model.Goal, error)
+	AddGoal(context.Context, model.Goal) (int, error)
+	DeleteGoal(context.Context, int, int) error
+	AddContribution(context.Context, int, time.Time, decimal.Decimal) (int, error)
+}
+
+const (
+	goalTable         = "goal"
+	contributionTable = "goal_contribution"
+	currencyTable     = "currency"
+)
+
+var (
+	querySelectByState = fmt.Sprintf(`SELECT g.id, g.state_id, g.title, g.target,
+									coalesce(g.currency_id, 0) as currency_id, coalesce(c.abbreviation, '') as currency_abbr,
+									g.deadline, g.savings, g.created_at,
+									coalesce((SELECT sum(gc.amount) FROM %[3]s as gc WHERE gc.goal_id = g.id), 0)
+									as contributed
+									FROM %[1]s as g
+									LEFT JOIN %[2]s as c ON c.id = g.currency_id
+									WHERE g.state_id = $1 ORDER BY g.deadline, g.id`,
+		goalTable, currencyTable, contributionTable)
+	queryInsert = fmt.Sprintf(`INSERT INTO %s (state_id, title, target, currency_id, deadline, savings)
+									values ($1, $2, $3, $4, $5, $6) RETURNING id`, goalTable)
+	queryDelete             = fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND state_id = $2`, goalTable)
+	queryInsertContribution = fmt.Sprintf(`INSERT INTO %s (goal_id, amount, contributed_at)
+									values ($1, $2, $3) RETURNING id`, contributionTable)
+)
+
+type Goal struct {
+	db *sqlx.DB
+}
+
+func NewGoal(db *sqlx.DB) *Goal {
+	return &Goal{
+		db: db,
+	}
+}
+
+// Goals returns goals of state by deadline with sum of manual contributions
+func (g *Goal) Goals(ctx context.Context, stateId int) (gs []model.Goal, err error) {
+	var goalsDB []model.GoalDB
+	if err = g.db.SelectContext(ctx, &goalsDB, querySelectByState, stateId); err != nil {
+		return nil, errors.Wrap(err, "goals by state")
+	}
+
+	for _, goalDB := range goalsDB {
+		gs = append(gs, model.Goal{
+			Id:      goalDB.Id,
+			StateId: goalDB.StateId,
+			Title:   goalDB.Title,
+			Target:  goalDB.Target,
+			Currency: model.Currency{
+				Id:   goalDB.CurrencyId,
+				Abbr: goalDB.CurrencyAbbr,
+			},
+			Deadline:    goalDB.Deadline,
+			Savings:     goalDB.Savings,
+			Contributed: goalDB.Contributed,
+			CreatedAt:   goalDB.CreatedAt,
+		})
+	}
+
+	return
+}
+
+// AddGoal creates goal, target is stored in the default currency like account balances
+func (g *Goal) AddGoal(ctx context.Context, goal model.Goal) (goalId int, err error) {
+	row := g.db.QueryRowContext(ctx, queryInsert, goal.StateId, goal.Title, goal.Target, goal.Currency.Id,
+		goal.Deadline.Format("2006-01-02"), goal.Savings)
+	if err = row.Scan(&goalId); err != nil {
+		return 0, errors.Wrap(err, "insert goal")
+	}
+
+	return
+}
+
+func (g *Goal) DeleteGoal(ctx context.Context, stateId, id int) (err error) {
+	if _, err = g.db.ExecContext(ctx, queryDelete, id, stateId); err != nil {
+		return errors.Wrap(err, "delete goal")
+	}
+
+	return
+}
+
+// AddContribution logs contribution to goal in the default currency, negative amount is withdrawal
+func (g *Goal) AddContribution(ctx context.Context, goalId int, date time.Time,
+	amount decimal.Decimal) (contributionId int, err error) {
+	row := g.db.QueryRowContext(ctx, queryInsertContribution, goalId, amount.Original(), date.Format("2006-01-02"))
+	if err = row.Scan(&contributionId); err != nil {
+		return 0, errors.Wrap(err, "insert contribution")
+	}
+
+	return
+}
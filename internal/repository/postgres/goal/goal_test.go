@@ -0,0 +1,80 @@
+package goal
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+	"testing"
+	"time"
+)
+
+func TestGoal_Goals(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func(db *sqlx.DB) {
+		_ = db.Close()
+	}(db)
+
+	r := NewGoal(db)
+
+	ctx := context.Background()
+	deadline := time.Date(2023, 8, 31, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2022, 11, 25, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		mock    func()
+		want    []model.Goal
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "state_id", "title", "target", "currency_id", "currency_abbr",
+					"deadline", "savings", "created_at", "contributed"}).
+					AddRow(1, 5, "Vacation", 1500000000, 3, "EUR", deadline, true, created, 100000000)
+				mock.ExpectQuery("SELECT (.+) FROM goal").
+					WithArgs(5).WillReturnRows(rows)
+			},
+			want: []model.Goal{
+				{
+					Id:          1,
+					StateId:     5,
+					Title:       "Vacation",
+					Target:      1500000000,
+					Currency:    model.Currency{Id: 3, Abbr: "EUR"},
+					Deadline:    deadline,
+					Savings:     true,
+					Contributed: 100000000,
+					CreatedAt:   created,
+				},
+			},
+		},
+		{
+			name: "Error",
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM goal").
+					WithArgs(5).WillReturnError(sqlmock.ErrCancelled)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.Goals(ctx, 5)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
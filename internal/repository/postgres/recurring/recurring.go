@@ -0,0 +1,214 @@
+package recurring
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/spending"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"time"
+)
+
+type Client interface {
+	Recurrings(context.Context, int) ([]model.Recurring, error)
+	All(context.Context) ([]model.Recurring, error)
+	AddRecurring(context.Context, model.Recurring) (int, error)
+	DeleteRecurring(context.Context, int, int) error
+	GetByEvent(context.Context, int, int) (*model.Recurring, error)
+	Materialize(context.Context, model.Recurring, time.Time) (int, bool, error)
+}
+
+const (
+	recurringTable      = "recurring"
+	recurringEventTable = "recurring_event"
+	categoryTable       = "category"
+	currencyTable       = "currency"
+	stateTable          = "state"
+)
+
+var (
+	NotFoundError = errors.New("recurring not found")
+	querySelect   = fmt.Sprintf(`SELECT r.id, r.state_id, r.chat_id, r.category_id, c.title as category_title,
+									coalesce(r.account_id, 0) as account_id, coalesce(r.currency_id, 0) as currency_id,
+									coalesce(cur.abbreviation, '') as currency_abbr, r.price, r.period, r.day, r.month, r.start_at,
+									coalesce((SELECT max(re.occurrence_at) FROM %s as re WHERE re.recurring_id = r.id),
+										r.start_at - 1) as last_occurrence_at,
+									coalesce(st.timezone, '') as timezone, r.language
+									FROM %s as r
+									LEFT JOIN %s as c ON c.id = r.category_id
+									LEFT JOIN %s as cur ON cur.id = r.currency_id
+									LEFT JOIN %s as st ON st.id = r.state_id`,
+		recurringEventTable, recurringTable, categoryTable, currencyTable, stateTable)
+	querySelectByState = querySelect + ` WHERE r.state_id = $1 ORDER BY r.id`
+	querySelectByEvent = querySelect + fmt.Sprintf(` WHERE r.state_id = $1 AND r.id IN
+									(SELECT recurring_id FROM %s WHERE event_id = $2)`, recurringEventTable)
+	queryInsert = fmt.Sprintf(`INSERT INTO %s (state_id, chat_id, category_id, account_id, currency_id,
+									price, period, day, month, start_at, language)
+									values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`, recurringTable)
+	queryDelete = fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND state_id = $2`, recurringTable)
+	queryClaim  = fmt.Sprintf(`INSERT INTO %s (recurring_id, occurrence_at) values ($1, $2)
+									ON CONFLICT (recurring_id, occurrence_at) DO NOTHING RETURNING id`, recurringEventTable)
+	querySetEvent = fmt.Sprintf(`UPDATE %s SET event_id = $1 WHERE id = $2`, recurringEventTable)
+)
+
+type Recurring struct {
+	db         *sqlx.DB
+	reposSpend spending.EventTx
+}
+
+func NewRecurring(db *sqlx.DB, reposSpend spending.EventTx) *Recurring {
+	return &Recurring{
+		db:         db,
+		reposSpend: reposSpend,
+	}
+}
+
+func (r *Recurring) Recurrings(ctx context.Context, stateId int) (rs []model.Recurring, err error) {
+	var recurringsDB []model.RecurringDB
+	if err = r.db.SelectContext(ctx, &recurringsDB, querySelectByState, stateId); err != nil {
+		return nil, errors.Wrap(err, "recurrings by state")
+	}
+
+	for _, recurringDB := range recurringsDB {
+		rs = append(rs, toRecurring(recurringDB))
+	}
+
+	return
+}
+
+func (r *Recurring) All(ctx context.Context) (rs []model.Recurring, err error) {
+	var recurringsDB []model.RecurringDB
+	if err = r.db.SelectContext(ctx, &recurringsDB, querySelect); err != nil {
+		return nil, errors.Wrap(err, "all recurrings")
+	}
+
+	for _, recurringDB := range recurringsDB {
+		rs = append(rs, toRecurring(recurringDB))
+	}
+
+	return
+}
+
+func (r *Recurring) AddRecurring(ctx context.Context, rec model.Recurring) (recurringId int, err error) {
+	account := sql.NullInt64{Int64: int64(rec.AccountId), Valid: rec.AccountId > 0}
+	row := r.db.QueryRowContext(ctx, queryInsert, rec.StateId, rec.ChatId, rec.Category.Id, account, rec.Currency.Id,
+		rec.Price, rec.Period, rec.Day, rec.Month, rec.StartAt.Format("2006-01-02"), rec.Language)
+	err = row.Scan(&recurringId)
+	if err != nil {
+		return 0, errors.Wrap(err, "insert recurring")
+	}
+
+	return
+}
+
+func (r *Recurring) DeleteRecurring(ctx context.Context, stateId, id int) (err error) {
+	_, err = r.db.ExecContext(ctx, queryDelete, id, stateId)
+	if err != nil {
+		return errors.Wrap(err, "delete recurring")
+	}
+
+	return
+}
+
+func (r *Recurring) GetByEvent(ctx context.Context, stateId, eventId int) (rec *model.Recurring, err error) {
+	var recurringDB model.RecurringDB
+	if err = r.db.GetContext(ctx, &recurringDB, querySelectByEvent, stateId, eventId); err != nil {
+		return nil, NotFoundError
+	}
+	recurring := toRecurring(recurringDB)
+
+	return &recurring, nil
+}
+
+// Materialize adds event for occurrence exactly once: occurrence is claimed by unique index
+// in the same transaction with the event, so concurrent instances and restarts skip it
+func (r *Recurring) Materialize(ctx context.Context, rec model.Recurring, occurrence time.Time) (eventId int, ok bool, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "materialize tx begin")
+	}
+
+	var recurringEventId int
+	row := tx.QueryRowContext(ctx, queryClaim, rec.Id, occurrence.Format("2006-01-02"))
+	err = row.Scan(&recurringEventId)
+	if errors.Is(err, sql.ErrNoRows) {
+		errRoll := tx.Rollback()
+		if errRoll != nil {
+			return 0, false, errors.Wrap(errRoll, "materialize rollback")
+		}
+		return 0, false, nil
+	}
+	if err != nil {
+		errRoll := tx.Rollback()
+		if errRoll != nil {
+			return 0, false, errors.Wrap(errRoll, "materialize rollback")
+		}
+		return 0, false, errors.Wrap(err, "materialize claim")
+	}
+
+	eventId, err = r.reposSpend.AddEventTx(ctx, tx, rec.StateId, 0, rec.Category.Id, rec.AccountId,
+		occurrence, decimal.Decimal(rec.Price))
+	if err != nil {
+		errRoll := tx.Rollback()
+		if errRoll != nil {
+			return 0, false, errors.Wrap(errRoll, "materialize rollback")
+		}
+		return 0, false, errors.Wrap(err, "materialize add event")
+	}
+
+	_, err = tx.ExecContext(ctx, querySetEvent, eventId, recurringEventId)
+	if err != nil {
+		errRoll := tx.Rollback()
+		if errRoll != nil {
+			return 0, false, errors.Wrap(errRoll, "materialize rollback")
+		}
+		return 0, false, errors.Wrap(err, "materialize set event")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, false, errors.Wrap(err, "materialize tx commit")
+	}
+
+	r.reposSpend.Publish(model.EventChange{
+		Type:    model.EventCreated,
+		StateId: rec.StateId,
+		Event: model.Event{
+			Id:        eventId,
+			Category:  rec.Category,
+			Date:      occurrence,
+			Price:     rec.Price,
+			AccountId: rec.AccountId,
+		},
+	})
+
+	return eventId, true, nil
+}
+
+func toRecurring(recurringDB model.RecurringDB) model.Recurring {
+	return model.Recurring{
+		Id:      recurringDB.Id,
+		StateId: recurringDB.StateId,
+		ChatId:  recurringDB.ChatId,
+		Category: model.Category{
+			Id:    recurringDB.CategoryId,
+			Title: recurringDB.CategoryTitle,
+		},
+		AccountId: recurringDB.AccountId,
+		Currency: model.Currency{
+			Id:   recurringDB.CurrencyId,
+			Abbr: recurringDB.CurrencyAbbr,
+		},
+		Price:          recurringDB.Price,
+		Period:         recurringDB.Period,
+		Day:            recurringDB.Day,
+		Month:          recurringDB.Month,
+		StartAt:        recurringDB.StartAt,
+		LastOccurrence: recurringDB.LastOccurrence,
+		Timezone:       recurringDB.Timezone,
+		Language:       recurringDB.Language,
+	}
+}
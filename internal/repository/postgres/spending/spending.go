@@ -2,6 +2,7 @@ package spending
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
@@ -10,22 +11,53 @@ import (
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/category"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/rates"
 	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/broadcast"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/cache"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"strings"
 	"time"
 )
 
 const (
-	eventTable = "event"
+	eventTable          = "event"
+	userTable           = "user"
+	categoryTable       = "category"
+	recurringEventTable = "recurring_event"
+
+	// ChangesHistory is number of last changes of events kept to resume watchers,
+	// ChangesBuffer is number of changes buffered by watcher before it is dropped as slow
+	ChangesHistory = 10000
+	ChangesBuffer  = 256
 )
 
 var (
-	queryInsert = fmt.Sprintf("INSERT INTO %s (category_id, event_at, price) values ($1, $2, $3) RETURNING id",
-		eventTable)
-	queryDelete = fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, eventTable)
+	NotFoundError = errors.New("event not found")
+	queryInsert   = fmt.Sprintf("INSERT INTO %s (category_id, event_at, price, account_id, state_id, user_id) "+
+		"values ($1, $2, $3, $4, $5, $6) RETURNING id", eventTable)
+	queryDelete       = fmt.Sprintf(`DELETE FROM %s WHERE id = $1 RETURNING coalesce(state_id, 0)`, eventTable)
+	queryEventsFields = `e.id, e.category_id, coalesce(c.title, '') as category_title, e.event_at, e.price,
+		coalesce(e.user_id, 0) as user_id, coalesce(e.account_id, 0) as account_id, e.created_at`
+	queryEventsFrom = fmt.Sprintf(`FROM %s as e LEFT JOIN %s as c ON c.id = e.category_id`, eventTable, categoryTable)
+	queryGetEvent   = fmt.Sprintf(`SELECT %s %s WHERE e.id = $1 AND e.state_id = $2`, queryEventsFields,
+		queryEventsFrom)
+	queryUpdate = fmt.Sprintf(`UPDATE %s SET category_id = $1, event_at = $2, price = $3
+		WHERE id = $4 AND state_id = $5`, eventTable)
+	queryLast = fmt.Sprintf(`SELECT id, category_id, event_at, price, created_at FROM %s
+		WHERE state_id = $1 AND user_id = $2 ORDER BY id DESC LIMIT 1`, eventTable)
 	queryReport = fmt.Sprintf(`SELECT category_id, sum(price) as price FROM `+
-		`%s WHERE event_at BETWEEN $1 AND $2 GROUP BY category_id`,
-		eventTable)
+		`%s WHERE state_id = $1 AND event_at BETWEEN $2 AND $3 GROUP BY category_id`, eventTable)
+	queryCategorySum = fmt.Sprintf(`SELECT coalesce(sum(price), 0) FROM %s
+		WHERE state_id = $1 AND category_id = $2 AND event_at BETWEEN $3 AND $4`, eventTable)
+	queryReportRecurring = fmt.Sprintf(`SELECT e.category_id,
+		EXISTS (SELECT 1 FROM %s as re WHERE re.event_id = e.id) as recurring, sum(e.price) as price
+		FROM %s as e WHERE e.state_id = $1 AND e.event_at BETWEEN $2 AND $3 GROUP BY e.category_id, recurring`,
+		recurringEventTable, eventTable)
+	queryReportByMember = fmt.Sprintf(`SELECT coalesce(e.user_id, 0) as user_id, coalesce(u.name, '') as name,
+		sum(e.price) as price FROM %s as e
+		LEFT JOIN "%s" as u ON u.id = e.user_id
+		WHERE e.state_id = $1 AND e.event_at BETWEEN $2 AND $3
+		GROUP BY e.user_id, u.name ORDER BY price DESC`,
+		eventTable, userTable)
 	histogramEventPrice = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Namespace: "bot",
@@ -37,9 +69,15 @@ var (
 	)
 )
 
+type EventTx interface {
+	AddEventTx(context.Context, *sql.Tx, int, int, int, int, time.Time, decimal.Decimal) (int, error)
+	Publish(model.EventChange)
+}
+
 type Spending struct {
 	db             *sqlx.DB
 	categorySearch category.Search
+	changes        *broadcast.Broadcaster[model.EventChange]
 }
 
 type Event struct {
@@ -47,25 +85,67 @@ type Event struct {
 	Price decimal.Decimal
 }
 
-func NewSpending(db *sqlx.DB, categorySearch category.Search) *Spending {
+func NewSpending(db *sqlx.DB, categorySearch category.Search,
+	changes *broadcast.Broadcaster[model.EventChange]) *Spending {
 	return &Spending{
 		db:             db,
 		categorySearch: categorySearch,
+		changes:        changes,
 	}
 }
 
-func (s *Spending) AddEvent(ctx context.Context, categoryId int, date time.Time, price decimal.Decimal) (eventId int, err error) {
+// AddEvent adds event to ledger of state by member userId, zero userId is event without member
+func (s *Spending) AddEvent(ctx context.Context, stateId, userId, categoryId, accountId int, date time.Time,
+	price decimal.Decimal) (eventId int, err error) {
 	cat, err := s.categorySearch.CategoryGetById(ctx, categoryId)
 	if errors.Is(err, category.NotFoundError) {
 		return 0, errors.Wrap(err, "category not found")
 	}
 
-	row := s.db.QueryRowContext(ctx, queryInsert, cat.Id, date.Format("2006-01-02"), price.Original())
+	account := sql.NullInt64{Int64: int64(accountId), Valid: accountId > 0}
+	row := s.db.QueryRowContext(ctx, queryInsert, cat.Id, date.Format("2006-01-02"), price.Original(), account,
+		stateId, sql.NullInt64{Int64: int64(userId), Valid: userId > 0})
 	err = row.Scan(&eventId)
 	if err != nil {
 		return 0, errors.Wrap(err, "insert event")
 	}
 
+	histogramEventPrice.
+		WithLabelValues(cat.Title).
+		Observe(price.Float64())
+
+	s.Publish(model.EventChange{
+		Type:    model.EventCreated,
+		StateId: stateId,
+		Event: model.Event{
+			Id:        eventId,
+			Category:  *cat,
+			Date:      date,
+			Price:     price.Original(),
+			UserId:    userId,
+			AccountId: accountId,
+		},
+	})
+
+	return
+}
+
+// AddEventTx adds event in transaction, change is published by caller after commit
+func (s *Spending) AddEventTx(ctx context.Context, tx *sql.Tx, stateId, userId, categoryId, accountId int,
+	date time.Time, price decimal.Decimal) (eventId int, err error) {
+	cat, err := s.categorySearch.CategoryGetByIdTx(ctx, tx, categoryId)
+	if errors.Is(err, category.NotFoundError) {
+		return 0, errors.Wrap(err, "category not found")
+	}
+
+	account := sql.NullInt64{Int64: int64(accountId), Valid: accountId > 0}
+	row := tx.QueryRowContext(ctx, queryInsert, cat.Id, date.Format("2006-01-02"), price.Original(), account,
+		stateId, sql.NullInt64{Int64: int64(userId), Valid: userId > 0})
+	err = row.Scan(&eventId)
+	if err != nil {
+		return 0, errors.Wrap(err, "insert event tx")
+	}
+
 	histogramEventPrice.
 		WithLabelValues(cat.Title).
 		Observe(price.Float64())
@@ -74,23 +154,171 @@ func (s *Spending) AddEvent(ctx context.Context, categoryId int, date time.Time,
 }
 
 func (s *Spending) DeleteEvent(ctx context.Context, id int) (err error) {
-	_, err = s.db.ExecContext(ctx, queryDelete, id)
+	var stateId int
+	err = s.db.GetContext(ctx, &stateId, queryDelete, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
 	if err != nil {
 		return errors.Wrap(err, "delete event")
 	}
 
+	s.Publish(model.EventChange{
+		Type:    model.EventDeleted,
+		StateId: stateId,
+		Event:   model.Event{Id: id},
+	})
+
 	return
 }
 
-func (s Spending) Report(ctx context.Context, f1, f2 time.Time, rates rates.Client, userCurrency model.Currency) (m map[int]decimal.Decimal, err error) {
+// Publish sends change of event to watchers
+func (s *Spending) Publish(change model.EventChange) {
+	if s.changes != nil {
+		s.changes.Publish(change)
+	}
+}
+
+// Watch subscribes to changes of events of states after cursor, empty cursor watches new changes only
+func (s *Spending) Watch(cursor string, stateIds []int) (*broadcast.Subscription[model.EventChange], error) {
+	return s.changes.Subscribe(cursor, func(change model.EventChange) bool {
+		for _, stateId := range stateIds {
+			if change.StateId == stateId {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Cursor returns position of watcher after change with seq
+func (s *Spending) Cursor(seq uint64) string {
+	return s.changes.Cursor(seq)
+}
+
+// LastEvent returns the latest event added by member userId to ledger of state
+func (s *Spending) LastEvent(ctx context.Context, stateId, userId int) (event model.Event, err error) {
+	var eventDB model.EventDB
+	err = s.db.GetContext(ctx, &eventDB, queryLast, stateId, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return event, NotFoundError
+	}
+	if err != nil {
+		return event, errors.Wrap(err, "last event")
+	}
+
+	cat, err := s.categorySearch.CategoryGetById(ctx, eventDB.CategoryId)
+	if err != nil {
+		return event, errors.Wrap(err, "last event category")
+	}
+
+	return model.Event{
+		Id:       eventDB.Id,
+		Category: *cat,
+		Date:     eventDB.Date,
+		Price:    eventDB.Price,
+	}, nil
+}
+
+// Events returns page of events by filter newest first and number of all events by filter
+func (s *Spending) Events(ctx context.Context, filter model.EventFilter, offset, limit int) (es []model.Event,
+	total int, err error) {
+	conditions, args := []string{"e.state_id = $1"}, []interface{}{filter.StateId}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From.Format("2006-01-02"))
+		conditions = append(conditions, fmt.Sprintf("e.event_at >= $%d", len(args)))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To.Format("2006-01-02"))
+		conditions = append(conditions, fmt.Sprintf("e.event_at <= $%d", len(args)))
+	}
+	if filter.CategoryId > 0 {
+		args = append(args, filter.CategoryId)
+		conditions = append(conditions, fmt.Sprintf("e.category_id = $%d", len(args)))
+	}
+	if filter.UserId > 0 {
+		args = append(args, filter.UserId)
+		conditions = append(conditions, fmt.Sprintf("e.user_id = $%d", len(args)))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	if err = s.db.GetContext(ctx, &total, fmt.Sprintf(`SELECT count(*) %s WHERE %s`, queryEventsFrom, where),
+		args...); err != nil {
+		return nil, 0, errors.Wrap(err, "count events")
+	}
+
+	var eventsDB []model.EventDB
+	query := fmt.Sprintf(`SELECT %s %s WHERE %s ORDER BY e.event_at DESC, e.id DESC LIMIT $%d OFFSET $%d`,
+		queryEventsFields, queryEventsFrom, where, len(args)+1, len(args)+2)
+	if err = s.db.SelectContext(ctx, &eventsDB, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, errors.Wrap(err, "select events")
+	}
+	for _, eventDB := range eventsDB {
+		es = append(es, toEvent(eventDB))
+	}
+
+	return
+}
+
+// GetEvent returns event of ledger of state
+func (s *Spending) GetEvent(ctx context.Context, stateId, id int) (event model.Event, err error) {
+	var eventDB model.EventDB
+	err = s.db.GetContext(ctx, &eventDB, queryGetEvent, id, stateId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return event, NotFoundError
+	}
+	if err != nil {
+		return event, errors.Wrap(err, "get event")
+	}
+
+	return toEvent(eventDB), nil
+}
+
+// UpdateEvent changes category, date and price of event of state
+func (s *Spending) UpdateEvent(ctx context.Context, stateId, id, categoryId int, date time.Time,
+	price decimal.Decimal) (err error) {
+	res, err := s.db.ExecContext(ctx, queryUpdate, categoryId, date.Format("2006-01-02"), price.Original(), id,
+		stateId)
+	if err != nil {
+		return errors.Wrap(err, "update event")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return NotFoundError
+	}
+
+	if event, errGet := s.GetEvent(ctx, stateId, id); errGet == nil {
+		s.Publish(model.EventChange{
+			Type:    model.EventUpdated,
+			StateId: stateId,
+			Event:   event,
+		})
+	}
+
+	return
+}
+
+func toEvent(eventDB model.EventDB) model.Event {
+	return model.Event{
+		Id:        eventDB.Id,
+		Category:  model.Category{Id: eventDB.CategoryId, Title: eventDB.CategoryTitle},
+		Date:      eventDB.Date,
+		Price:     eventDB.Price,
+		UserId:    eventDB.UserId,
+		AccountId: eventDB.AccountId,
+	}
+}
+
+func (s Spending) Report(ctx context.Context, stateId int, f1, f2 time.Time, rates rates.Client,
+	userCurrency model.Currency) (m map[int]decimal.Decimal, err error) {
 	var events []model.EventDB
-	keyCacheReport := fmt.Sprintf("events_report_%s_%s", f1.Format("2006_01_02"), f2.Format("2006_01_02"))
+	keyCacheReport := fmt.Sprintf("events_report_%d_%s_%s", stateId,
+		f1.Format("2006_01_02"), f2.Format("2006_01_02"))
 	err = cache.Once(&cache.Item{
 		Key:   keyCacheReport,
 		Value: &events,
 		TTL:   time.Minute * 10,
 	}, func(ci *cache.Item) (interface{}, error) {
-		if err = s.db.SelectContext(ctx, &events, queryReport,
+		if err = s.db.SelectContext(ctx, &events, queryReport, stateId,
 			f1.Format("2006-01-02"), f2.Format("2006-01-02")); err != nil {
 			return nil, errors.Wrap(err, "select report")
 		}
@@ -119,3 +347,68 @@ func (s Spending) Report(ctx context.Context, f1, f2 time.Time, rates rates.Clie
 
 	return m, nil
 }
+
+// CategorySum returns spending of category of state in default currency, it is not cached
+// for checks of limits right after spending is added
+func (s Spending) CategorySum(ctx context.Context, stateId, categoryId int, f1, f2 time.Time) (decimal.Decimal,
+	error) {
+	var sum int64
+	if err := s.db.GetContext(ctx, &sum, queryCategorySum, stateId, categoryId,
+		f1.Format("2006-01-02"), f2.Format("2006-01-02")); err != nil {
+		return 0, errors.Wrap(err, "select category sum")
+	}
+
+	return decimal.Decimal(sum), nil
+}
+
+// ReportRecurring returns spending of state by category in currency userCurrency apart from spending
+// of events added by recurring spending, it is not cached
+func (s Spending) ReportRecurring(ctx context.Context, stateId int, f1, f2 time.Time, rates rates.Client,
+	userCurrency model.Currency) (other, recurring map[int]decimal.Decimal, err error) {
+	var spendsDB []model.CategorySpendDB
+	if err = s.db.SelectContext(ctx, &spendsDB, queryReportRecurring, stateId,
+		f1.Format("2006-01-02"), f2.Format("2006-01-02")); err != nil {
+		return nil, nil, errors.Wrap(err, "select report recurring")
+	}
+
+	rateUserCurr, ok := rates.GetRate(ctx, userCurrency)
+	if !ok {
+		return nil, nil, errors.New("user currency not found")
+	}
+	other, recurring = make(map[int]decimal.Decimal), make(map[int]decimal.Decimal)
+	for _, spend := range spendsDB {
+		sum := decimal.Decimal(spend.Price).Divide(rateUserCurr.Rate)
+		if spend.Recurring {
+			recurring[spend.CategoryId] = sum
+		} else {
+			other[spend.CategoryId] = sum
+		}
+	}
+
+	return
+}
+
+// ReportByMember returns spending of ledger of state by members in currency userCurrency,
+// events without member have zero user id
+func (s Spending) ReportByMember(ctx context.Context, stateId int, f1, f2 time.Time, rates rates.Client,
+	userCurrency model.Currency) (ms []model.MemberSpend, err error) {
+	var membersDB []model.MemberSpendDB
+	if err = s.db.SelectContext(ctx, &membersDB, queryReportByMember, stateId,
+		f1.Format("2006-01-02"), f2.Format("2006-01-02")); err != nil {
+		return nil, errors.Wrap(err, "select report by member")
+	}
+
+	rateUserCurr, ok := rates.GetRate(ctx, userCurrency)
+	if !ok {
+		return nil, errors.New("user currency not found")
+	}
+	for _, m := range membersDB {
+		ms = append(ms, model.MemberSpend{
+			UserId: m.UserId,
+			Name:   m.Name,
+			Sum:    decimal.Decimal(m.Price).Divide(rateUserCurr.Rate),
+		})
+	}
+
+	return
+}
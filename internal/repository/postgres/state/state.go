@@ -10,7 +10,13 @@ import (
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/currency"
 	"github.com/sku4/ozon-route256-spending-bot/model"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/timezone"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 const (
@@ -22,21 +28,32 @@ const (
 
 var (
 	queryUpdate      = fmt.Sprintf(`UPDATE %s SET currency_id=$1 WHERE id=$2`, stateTable)
+	queryUpdateAcc   = fmt.Sprintf(`UPDATE %s SET last_account_id=$1 WHERE id=$2`, stateTable)
+	queryUpdateThr   = fmt.Sprintf(`UPDATE %s SET limit_thresholds=$1 WHERE id=$2`, stateTable)
+	queryUpdateStart = fmt.Sprintf(`UPDATE %s SET week_start=$1, month_start=$2 WHERE id=$3`, stateTable)
+	queryUpdateTz    = fmt.Sprintf(`UPDATE %s SET timezone=$1 WHERE id=$2`, stateTable)
 	querySelect      = fmt.Sprintf(`SELECT id, currency_id FROM %s WHERE id=$1`, stateTable)
 	queryInsert      = fmt.Sprintf("INSERT INTO %s (currency_id) values ($1) RETURNING id", stateTable)
 	queryGetWithCurr = fmt.Sprintf(`
-				SELECT st.id, c.id as currency_id, c.abbreviation as currency_abbr 
+				SELECT st.id, c.id as currency_id, c.abbreviation as currency_abbr,
+						coalesce(st.last_account_id, 0) as last_account_id, st.limit_thresholds,
+						st.week_start, st.month_start, st.timezone
 						FROM %s as st
 						LEFT JOIN %s as c on c.id = st.currency_id
 						WHERE st.id=$1`, stateTable, currencyTable)
 	queryGetWithLimits = fmt.Sprintf(`
-				SELECT cl.id as category_limit_id, cl.category_id, cat.title as category_title, cl.category_limit 
+				SELECT cl.id as category_limit_id, cl.category_id, cat.title as category_title, cl.category_limit,
+						cl.period, cl.period_days
 						FROM %s as cl
 						LEFT JOIN %s as cat on cat.id = cl.category_id
-						WHERE cl.state_id=$1`,
+						WHERE cl.state_id=$1
+						ORDER BY cl.id`,
 		categoryLimitTable, categoryTable)
 )
 
+// DefaultThresholds are percents of limit to warn about when user has not set them
+var DefaultThresholds = []int{50, 80, 100}
+
 type Client interface {
 	GetById(context.Context, int) (*State, error)
 	GetByIdTx(context.Context, *sql.Tx, int) (*State, error)
@@ -84,12 +101,153 @@ func (s *State) GetCurrency(ctx context.Context) (c model.Currency, err error) {
 	return s.Currency, nil
 }
 
-func (s *State) AddLimit(ctx context.Context, categoryId int, limit decimal.Decimal) (err error) {
-	_, err = s.reposCatLimitSet.Set(ctx, s.Id, categoryId, limit)
+func (s *State) SetLastAccount(ctx context.Context, accountId int) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.LastAccountId == accountId {
+		return
+	}
+
+	_, err = s.db.ExecContext(ctx, queryUpdateAcc, accountId, s.Id)
+	if err != nil {
+		return errors.Wrap(err, "update last account")
+	}
+	s.LastAccountId = accountId
+
+	return
+}
+
+func (s *State) GetLastAccount(ctx context.Context) int {
+	_ = ctx
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.LastAccountId
+}
+
+// GetThresholds returns percents of limit to warn about, sorted ascending
+func (s *State) GetThresholds(ctx context.Context) []int {
+	_ = ctx
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.Thresholds == nil {
+		return DefaultThresholds
+	}
+
+	return s.Thresholds
+}
+
+func (s *State) SetThresholds(ctx context.Context, thresholds []int) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	thresholds = append([]int{}, thresholds...)
+	sort.Ints(thresholds)
+	_, err = s.db.ExecContext(ctx, queryUpdateThr, formatThresholds(thresholds), s.Id)
+	if err != nil {
+		return errors.Wrap(err, "update thresholds")
+	}
+	s.Thresholds = thresholds
+
+	return
+}
+
+// GetStart returns weekday and day of month user periods begin on
+func (s *State) GetStart(ctx context.Context) period.Start {
+	_ = ctx
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return period.Start{Weekday: time.Weekday(s.WeekStart), MonthDay: s.MonthStart}
+}
+
+func (s *State) SetStart(ctx context.Context, start period.Start) (err error) {
+	if err = start.Validate(); err != nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err = s.db.ExecContext(ctx, queryUpdateStart, int(start.Weekday), start.MonthDay, s.Id)
+	if err != nil {
+		return errors.Wrap(err, "update period start")
+	}
+	s.WeekStart, s.MonthStart = int(start.Weekday), start.MonthDay
+
+	return
+}
+
+// GetLocation returns time zone of user, time zone of server when it is not set
+func (s *State) GetLocation(ctx context.Context) *time.Location {
+	_ = ctx
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	loc, err := timezone.Load(s.Timezone)
+	if err != nil {
+		return time.Local
+	}
+
+	return loc
+}
+
+// SetTimezone saves IANA time zone name of user
+func (s *State) SetTimezone(ctx context.Context, name string) (err error) {
+	if _, err = timezone.Load(name); err != nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err = s.db.ExecContext(ctx, queryUpdateTz, name, s.Id)
+	if err != nil {
+		return errors.Wrap(err, "update timezone")
+	}
+	s.Timezone = name
+
+	return
+}
+
+func (s *State) AddLimit(ctx context.Context, categoryId int, limit decimal.Decimal, r period.Range) (err error) {
+	_, err = s.reposCatLimitSet.Set(ctx, s.Id, categoryId, limit, r)
+	if err != nil {
+		return
+	}
+
+	// reload limits on next read
+	s.limits = nil
+
+	return
+}
+
+func (s *State) DeleteLimit(ctx context.Context, limitId int) (err error) {
+	err = s.reposCatLimitSet.Delete(ctx, s.Id, limitId)
+	if err != nil {
+		return
+	}
+
+	// reload limits on next read
+	s.limits = nil
 
 	return
 }
 
+func (s *State) ClaimThreshold(ctx context.Context, limitId, threshold int, periodAt time.Time) (bool, error) {
+	return s.reposCatLimitSet.ClaimThreshold(ctx, limitId, threshold, periodAt)
+}
+
+func (s *State) ReleaseThresholds(ctx context.Context, limitId, percent int, periodAt time.Time) error {
+	return s.reposCatLimitSet.ReleaseThresholds(ctx, limitId, percent, periodAt)
+}
+
 func (s *State) GetLimits(ctx context.Context) (cls []*category_limit.CategoryLimit, err error) {
 	if s.limits == nil {
 		s.limits, err = s.reposCatLimitSet.GetByState(ctx, s.Id)
@@ -150,6 +308,8 @@ func (s *States) GetById(ctx context.Context, id int) (st *State, err error) {
 		limitDB := &model.CategoryLimitDB{
 			Id:         limit.CategoryLimitId,
 			Limit:      limit.CategoryLimit,
+			Period:     limit.LimitPeriod,
+			Days:       limit.LimitDays,
 			CategoryId: limit.CategoryId,
 			StateId:    id,
 		}
@@ -158,8 +318,13 @@ func (s *States) GetById(ctx context.Context, id int) (st *State, err error) {
 
 	st = &State{
 		State: model.State{
-			Id:       state.Id,
-			Currency: curr,
+			Id:            state.Id,
+			Currency:      curr,
+			LastAccountId: state.LastAccountId,
+			Thresholds:    parseThresholds(state.Thresholds),
+			WeekStart:     state.WeekStart,
+			MonthStart:    state.MonthStart,
+			Timezone:      state.Timezone,
 		},
 		limits:           limits,
 		mutex:            s.mutex,
@@ -174,7 +339,8 @@ func (s *States) GetById(ctx context.Context, id int) (st *State, err error) {
 func (s *States) GetByIdTx(ctx context.Context, tx *sql.Tx, id int) (st *State, err error) {
 	var state model.StateWithLimits
 	row := tx.QueryRowContext(ctx, queryGetWithCurr, id)
-	err = row.Scan(&state.Id, &state.CurrencyId, &state.CurrencyAbbr)
+	err = row.Scan(&state.Id, &state.CurrencyId, &state.CurrencyAbbr, &state.LastAccountId, &state.Thresholds,
+		&state.WeekStart, &state.MonthStart, &state.Timezone)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("state '%d' not found", id))
 	}
@@ -193,7 +359,7 @@ func (s *States) GetByIdTx(ctx context.Context, tx *sql.Tx, id int) (st *State,
 	for rows.Next() {
 		var stateLimit model.StateWithLimits
 		err = rows.Scan(&stateLimit.CategoryLimitId, &stateLimit.CategoryId,
-			&stateLimit.CategoryTitle, &stateLimit.CategoryLimit)
+			&stateLimit.CategoryTitle, &stateLimit.CategoryLimit, &stateLimit.LimitPeriod, &stateLimit.LimitDays)
 		if err != nil {
 			break
 		}
@@ -209,6 +375,8 @@ func (s *States) GetByIdTx(ctx context.Context, tx *sql.Tx, id int) (st *State,
 		limitDB := &model.CategoryLimitDB{
 			Id:         limit.CategoryLimitId,
 			Limit:      limit.CategoryLimit,
+			Period:     limit.LimitPeriod,
+			Days:       limit.LimitDays,
 			CategoryId: limit.CategoryId,
 			StateId:    id,
 		}
@@ -217,8 +385,13 @@ func (s *States) GetByIdTx(ctx context.Context, tx *sql.Tx, id int) (st *State,
 
 	st = &State{
 		State: model.State{
-			Id:       state.Id,
-			Currency: curr,
+			Id:            state.Id,
+			Currency:      curr,
+			LastAccountId: state.LastAccountId,
+			Thresholds:    parseThresholds(state.Thresholds),
+			WeekStart:     state.WeekStart,
+			MonthStart:    state.MonthStart,
+			Timezone:      state.Timezone,
 		},
 		limits:           limits,
 		mutex:            s.mutex,
@@ -242,8 +415,10 @@ func (s *States) AddState(ctx context.Context) (st *State, err error) {
 
 	return &State{
 		State: model.State{
-			Id:       stateId,
-			Currency: c,
+			Id:         stateId,
+			Currency:   c,
+			WeekStart:  int(period.DefaultStart.Weekday),
+			MonthStart: period.DefaultStart.MonthDay,
 		},
 		limits:           []*category_limit.CategoryLimit{},
 		mutex:            &sync.RWMutex{},
@@ -265,8 +440,10 @@ func (s *States) AddStateTx(ctx context.Context, tx *sql.Tx) (st *State, err err
 
 	return &State{
 		State: model.State{
-			Id:       stateId,
-			Currency: c,
+			Id:         stateId,
+			Currency:   c,
+			WeekStart:  int(period.DefaultStart.Weekday),
+			MonthStart: period.DefaultStart.MonthDay,
 		},
 		limits:           []*category_limit.CategoryLimit{},
 		mutex:            &sync.RWMutex{},
@@ -275,3 +452,23 @@ func (s *States) AddStateTx(ctx context.Context, tx *sql.Tx) (st *State, err err
 		reposCatLimitSet: s.reposCatLimitSet,
 	}, nil
 }
+
+func parseThresholds(s string) (thresholds []int) {
+	for _, f := range strings.Split(s, ",") {
+		if t, err := strconv.Atoi(strings.TrimSpace(f)); err == nil {
+			thresholds = append(thresholds, t)
+		}
+	}
+	sort.Ints(thresholds)
+
+	return
+}
+
+func formatThresholds(thresholds []int) string {
+	fields := make([]string, 0, len(thresholds))
+	for _, t := range thresholds {
+		fields = append(fields, strconv.Itoa(t))
+	}
+
+	return strings.Join(fields, ",")
+}
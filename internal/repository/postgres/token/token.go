@@ -0,0 +1,134 @@
+package token
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/auth"
+)
+
+type Client interface {
+	Tokens(context.Context, int) ([]model.Token, error)
+	AddToken(context.Context, model.Token) (int, error)
+	RevokeToken(context.Context, int, int) error
+	UseToken(context.Context, string) (model.Token, error)
+	States(context.Context, int) ([]int, error)
+}
+
+const (
+	tokenTable  = "api_token"
+	userTable   = "user"
+	memberTable = "ledger_member"
+)
+
+var (
+	NotFoundError = errors.New("token not found")
+
+	querySelectByUser = fmt.Sprintf(`SELECT id, user_id, name, token_hash, scopes, created_at, used_at FROM %s
+									WHERE user_id = $1 AND revoked_at IS NULL ORDER BY id`, tokenTable)
+	queryInsert = fmt.Sprintf(`INSERT INTO %s (user_id, name, token_hash, scopes)
+									values ($1, $2, $3, $4) RETURNING id`, tokenTable)
+	queryRevoke = fmt.Sprintf(`UPDATE %s SET revoked_at = now()
+									WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, tokenTable)
+	queryUse = fmt.Sprintf(`UPDATE %s SET used_at = now() WHERE token_hash = $1 AND revoked_at IS NULL
+									RETURNING id, user_id, name, token_hash, scopes, created_at, used_at`, tokenTable)
+	queryStates = fmt.Sprintf(`SELECT state_id FROM "%s" WHERE id = $1
+									UNION SELECT state_id FROM %s WHERE user_id = $1`, userTable, memberTable)
+)
+
+// Token keeps hashes of personal access tokens of users
+type Token struct {
+	db *sqlx.DB
+}
+
+func NewToken(db *sqlx.DB) *Token {
+	return &Token{
+		db: db,
+	}
+}
+
+// Tokens returns not revoked tokens of user
+func (t *Token) Tokens(ctx context.Context, userId int) (ts []model.Token, err error) {
+	var tokensDB []model.TokenDB
+	if err = t.db.SelectContext(ctx, &tokensDB, querySelectByUser, userId); err != nil {
+		return nil, errors.Wrap(err, "tokens by user")
+	}
+
+	for _, tokenDB := range tokensDB {
+		token, errToken := toToken(tokenDB)
+		if errToken != nil {
+			return nil, errToken
+		}
+		ts = append(ts, token)
+	}
+
+	return
+}
+
+func (t *Token) AddToken(ctx context.Context, token model.Token) (tokenId int, err error) {
+	row := t.db.QueryRowContext(ctx, queryInsert, token.UserId, token.Name, token.Hash, token.Scopes.String())
+	if err = row.Scan(&tokenId); err != nil {
+		return 0, errors.Wrap(err, "insert token")
+	}
+
+	return
+}
+
+// RevokeToken revokes token of user, revoked token is kept for history
+func (t *Token) RevokeToken(ctx context.Context, userId, id int) error {
+	res, err := t.db.ExecContext(ctx, queryRevoke, id, userId)
+	if err != nil {
+		return errors.Wrap(err, "revoke token")
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "revoke token rows")
+	}
+	if rows == 0 {
+		return NotFoundError
+	}
+
+	return nil
+}
+
+// UseToken returns not revoked token by hash and marks it as used
+func (t *Token) UseToken(ctx context.Context, hash string) (model.Token, error) {
+	var tokenDB model.TokenDB
+	if err := t.db.GetContext(ctx, &tokenDB, queryUse, hash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Token{}, NotFoundError
+		}
+		return model.Token{}, errors.Wrap(err, "use token")
+	}
+
+	return toToken(tokenDB)
+}
+
+// States returns own state of user and shared ledgers user is member of
+func (t *Token) States(ctx context.Context, userId int) (states []int, err error) {
+	if err = t.db.SelectContext(ctx, &states, queryStates, userId); err != nil {
+		return nil, errors.Wrap(err, "user states")
+	}
+
+	return
+}
+
+func toToken(tokenDB model.TokenDB) (model.Token, error) {
+	scopes, err := auth.ParseScopes(tokenDB.Scopes)
+	if err != nil {
+		return model.Token{}, errors.Wrap(err, "token scopes")
+	}
+
+	return model.Token{
+		Id:        tokenDB.Id,
+		UserId:    tokenDB.UserId,
+		Name:      tokenDB.Name,
+		Hash:      tokenDB.Hash,
+		Scopes:    scopes,
+		CreatedAt: tokenDB.CreatedAt,
+		UsedAt:    tokenDB.UsedAt.Time,
+	}, nil
+}
@@ -0,0 +1,128 @@
+package token
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+	"testing"
+	"time"
+)
+
+func TestToken_UseToken(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func(db *sqlx.DB) {
+		_ = db.Close()
+	}(db)
+
+	r := NewToken(db)
+
+	ctx := context.Background()
+	created := time.Date(2022, 11, 26, 10, 0, 0, 0, time.UTC)
+	used := time.Date(2022, 11, 27, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		mock    func()
+		want    model.Token
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "token_hash", "scopes", "created_at",
+					"used_at"}).
+					AddRow(1, 5, "sheets", "hash", "read,write", created, used)
+				mock.ExpectQuery("UPDATE api_token SET used_at").
+					WithArgs("hash").WillReturnRows(rows)
+			},
+			want: model.Token{
+				Id:        1,
+				UserId:    5,
+				Name:      "sheets",
+				Hash:      "hash",
+				Scopes:    auth.Scopes{auth.ScopeRead, auth.ScopeWrite},
+				CreatedAt: created,
+				UsedAt:    used,
+			},
+		},
+		{
+			name: "Revoked",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "token_hash", "scopes", "created_at",
+					"used_at"})
+				mock.ExpectQuery("UPDATE api_token SET used_at").
+					WithArgs("hash").WillReturnRows(rows)
+			},
+			wantErr: NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.UseToken(ctx, "hash")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestToken_RevokeToken(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func(db *sqlx.DB) {
+		_ = db.Close()
+	}(db)
+
+	r := NewToken(db)
+
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectExec("UPDATE api_token SET revoked_at").
+					WithArgs(1, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectExec("UPDATE api_token SET revoked_at").
+					WithArgs(1, 5).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.RevokeToken(ctx, 5, 1)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -18,8 +18,11 @@ const (
 var (
 	mutex                = &sync.RWMutex{}
 	queryGeById          = fmt.Sprintf(`SELECT id, state_id FROM "%s" WHERE id=$1`, userTable)
-	queryInsert          = fmt.Sprintf(`INSERT INTO "%s" (telegram_id, state_id) values ($1, $2) RETURNING id`, userTable)
-	queryGetByTelegramId = fmt.Sprintf(`SELECT id, state_id, telegram_id FROM "%s" WHERE telegram_id=$1`, userTable)
+	queryInsert          = fmt.Sprintf(`INSERT INTO "%s" (telegram_id, state_id, name) values ($1, $2, $3) RETURNING id`, userTable)
+	queryGetByTelegramId = fmt.Sprintf(`SELECT id, state_id, telegram_id, name, language FROM "%s" WHERE telegram_id=$1`, userTable)
+	queryUpdateName      = fmt.Sprintf(`UPDATE "%s" SET name=$1 WHERE id=$2`, userTable)
+	queryUpdateLanguage  = fmt.Sprintf(`UPDATE "%s" SET language=$1 WHERE id=$2`, userTable)
+	queryGetById         = fmt.Sprintf(`SELECT id, state_id, telegram_id, name, language FROM "%s" WHERE id=$1`, userTable)
 )
 
 type Users struct {
@@ -54,6 +57,16 @@ func (u *User) GetState(ctx context.Context) (s *state.State, err error) {
 	return u.State, nil
 }
 
+// SetLanguage keeps language chosen by user, empty language returns to language of telegram client
+func (u *User) SetLanguage(ctx context.Context, language string) error {
+	if _, err := u.db.ExecContext(ctx, queryUpdateLanguage, language, u.Id); err != nil {
+		return errors.Wrap(err, "update user language")
+	}
+	u.Language = language
+
+	return nil
+}
+
 func NewUsers(db *sqlx.DB, reposCurr currency.Client, reposState state.Client) *Users {
 	us := &Users{
 		db:         db,
@@ -64,8 +77,16 @@ func NewUsers(db *sqlx.DB, reposCurr currency.Client, reposState state.Client) *
 	return us
 }
 
-func (us *Users) AddUser(ctx context.Context, telegramId int) (u *User, err error) {
+// AddUser returns user by telegram id or adds new one with own state, name is shown in reports
+// of shared ledgers and updated when changed
+func (us *Users) AddUser(ctx context.Context, telegramId int, name string) (u *User, err error) {
 	if u, err = us.GetByTgId(ctx, telegramId); err == nil {
+		if name != "" && u.Name != name {
+			if _, err = us.db.ExecContext(ctx, queryUpdateName, name, u.Id); err != nil {
+				return nil, errors.Wrap(err, "update user name")
+			}
+			u.Name = name
+		}
 		return u, nil
 	}
 
@@ -84,7 +105,7 @@ func (us *Users) AddUser(ctx context.Context, telegramId int) (u *User, err erro
 	}
 
 	var userId int
-	row := tx.QueryRowContext(ctx, queryInsert, telegramId, st.Id)
+	row := tx.QueryRowContext(ctx, queryInsert, telegramId, st.Id, name)
 	err = row.Scan(&userId)
 	if err != nil {
 		errRoll := tx.Rollback()
@@ -103,6 +124,7 @@ func (us *Users) AddUser(ctx context.Context, telegramId int) (u *User, err erro
 		User: model.User{
 			Id:   userId,
 			TgId: telegramId,
+			Name: name,
 		},
 		State:      st,
 		db:         us.db,
@@ -121,7 +143,7 @@ func (us *Users) GetByTgId(ctx context.Context, tgId int) (u *User, err error) {
 
 	var user model.UserDB
 	row := tx.QueryRowContext(ctx, queryGetByTelegramId, tgId)
-	err = row.Scan(&user.Id, &user.StateId, &user.TgId)
+	err = row.Scan(&user.Id, &user.StateId, &user.TgId, &user.Name, &user.Language)
 	if err != nil {
 		errRoll := tx.Rollback()
 		if errRoll != nil {
@@ -146,8 +168,10 @@ func (us *Users) GetByTgId(ctx context.Context, tgId int) (u *User, err error) {
 
 	u = &User{
 		User: model.User{
-			Id:   user.Id,
-			TgId: user.TgId,
+			Id:       user.Id,
+			TgId:     user.TgId,
+			Name:     user.Name,
+			Language: user.Language,
 		},
 		State:      st,
 		db:         us.db,
@@ -157,3 +181,29 @@ func (us *Users) GetByTgId(ctx context.Context, tgId int) (u *User, err error) {
 
 	return
 }
+
+// GetById returns user with own state, used by api tokens
+func (us *Users) GetById(ctx context.Context, id int) (u *User, err error) {
+	var user model.UserDB
+	if err = us.db.GetContext(ctx, &user, queryGetById, id); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("user '%d' not found", id))
+	}
+
+	st, err := us.reposState.GetById(ctx, user.StateId)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("state '%d' not found", user.StateId))
+	}
+
+	return &User{
+		User: model.User{
+			Id:       user.Id,
+			TgId:     user.TgId,
+			Name:     user.Name,
+			Language: user.Language,
+		},
+		State:      st,
+		db:         us.db,
+		reposCurr:  us.reposCurr,
+		reposState: us.reposState,
+	}, nil
+}
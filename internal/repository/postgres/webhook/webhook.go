@@ -0,0 +1,187 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"strings"
+)
+
+type Client interface {
+	Webhooks(context.Context, int) ([]model.Webhook, error)
+	GetWebhook(context.Context, int, int) (model.Webhook, error)
+	AddWebhook(context.Context, model.Webhook) (model.Webhook, error)
+	DeleteWebhook(context.Context, int, int) error
+	Delivered(context.Context, int, bool) (bool, error)
+	AddDelivery(context.Context, model.WebhookDelivery) error
+	Deliveries(context.Context, int, int, int) ([]model.WebhookDelivery, int, error)
+}
+
+const (
+	webhookTable  = "webhook"
+	deliveryTable = "webhook_delivery"
+
+	// MaxFailures is number of failed deliveries in a row which disables webhook
+	MaxFailures = 5
+)
+
+var (
+	NotFoundError = errors.New("webhook not found")
+
+	queryFields = `id, state_id, coalesce(user_id, 0) as user_id, url, secret, events, failures, created_at,
+									disabled_at`
+	querySelectByState = fmt.Sprintf(`SELECT %s FROM %s WHERE state_id = $1 ORDER BY id`, queryFields, webhookTable)
+	querySelectById    = fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1 AND state_id = $2`, queryFields, webhookTable)
+	// registering url again changes events and enables disabled webhook, secret is kept
+	queryUpsert = fmt.Sprintf(`INSERT INTO %s (state_id, user_id, url, secret, events) values ($1, $2, $3, $4, $5)
+									ON CONFLICT (state_id, url) DO UPDATE SET events = excluded.events, failures = 0,
+									disabled_at = NULL RETURNING %s`, webhookTable, queryFields)
+	queryDelete    = fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND state_id = $2`, webhookTable)
+	querySucceeded = fmt.Sprintf(`UPDATE %s SET failures = 0 WHERE id = $1 RETURNING disabled_at IS NOT NULL`,
+		webhookTable)
+	queryFailed = fmt.Sprintf(`UPDATE %s SET failures = failures + 1,
+									disabled_at = CASE WHEN failures + 1 >= $2 THEN coalesce(disabled_at, now())
+									ELSE disabled_at END WHERE id = $1 RETURNING disabled_at IS NOT NULL`, webhookTable)
+	queryInsertDelivery = fmt.Sprintf(`INSERT INTO %s (webhook_id, delivery_id, event, attempt, status_code, error,
+									duration_ms) values ($1, $2, $3, $4, $5, $6, $7)`, deliveryTable)
+	queryCountDeliveries  = fmt.Sprintf(`SELECT count(*) FROM %s WHERE webhook_id = $1`, deliveryTable)
+	querySelectDeliveries = fmt.Sprintf(`SELECT id, webhook_id, delivery_id, event, attempt, status_code, error,
+									duration_ms, created_at FROM %s WHERE webhook_id = $1 ORDER BY id DESC
+									LIMIT $2 OFFSET $3`, deliveryTable)
+)
+
+// Webhook keeps callback urls of states and log of deliveries to them
+type Webhook struct {
+	db *sqlx.DB
+}
+
+func NewWebhook(db *sqlx.DB) *Webhook {
+	return &Webhook{
+		db: db,
+	}
+}
+
+// Webhooks returns webhooks of state including disabled ones
+func (w *Webhook) Webhooks(ctx context.Context, stateId int) (ws []model.Webhook, err error) {
+	var webhooksDB []model.WebhookDB
+	if err = w.db.SelectContext(ctx, &webhooksDB, querySelectByState, stateId); err != nil {
+		return nil, errors.Wrap(err, "webhooks by state")
+	}
+	for _, webhookDB := range webhooksDB {
+		ws = append(ws, toWebhook(webhookDB))
+	}
+
+	return
+}
+
+func (w *Webhook) GetWebhook(ctx context.Context, stateId, id int) (model.Webhook, error) {
+	var webhookDB model.WebhookDB
+	if err := w.db.GetContext(ctx, &webhookDB, querySelectById, id, stateId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Webhook{}, NotFoundError
+		}
+		return model.Webhook{}, errors.Wrap(err, "get webhook")
+	}
+
+	return toWebhook(webhookDB), nil
+}
+
+// AddWebhook adds webhook of state or enables existing webhook of the same url with new events
+func (w *Webhook) AddWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error) {
+	events := make([]string, 0, len(webhook.Events))
+	for _, e := range webhook.Events {
+		events = append(events, string(e))
+	}
+	user := sql.NullInt64{Int64: int64(webhook.UserId), Valid: webhook.UserId > 0}
+
+	var webhookDB model.WebhookDB
+	if err := w.db.GetContext(ctx, &webhookDB, queryUpsert, webhook.StateId, user, webhook.Url, webhook.Secret,
+		strings.Join(events, ",")); err != nil {
+		return model.Webhook{}, errors.Wrap(err, "insert webhook")
+	}
+
+	return toWebhook(webhookDB), nil
+}
+
+// DeleteWebhook removes webhook of state with its deliveries
+func (w *Webhook) DeleteWebhook(ctx context.Context, stateId, id int) error {
+	res, err := w.db.ExecContext(ctx, queryDelete, id, stateId)
+	if err != nil {
+		return errors.Wrap(err, "delete webhook")
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "delete webhook rows")
+	}
+	if rows == 0 {
+		return NotFoundError
+	}
+
+	return nil
+}
+
+// Delivered counts result of delivery: success resets failures, MaxFailures failures in a row disable webhook.
+// Returns whether webhook is disabled
+func (w *Webhook) Delivered(ctx context.Context, id int, ok bool) (disabled bool, err error) {
+	if ok {
+		err = w.db.GetContext(ctx, &disabled, querySucceeded, id)
+	} else {
+		err = w.db.GetContext(ctx, &disabled, queryFailed, id, MaxFailures)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, NotFoundError
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "webhook delivered")
+	}
+
+	return
+}
+
+// AddDelivery logs attempt of delivery
+func (w *Webhook) AddDelivery(ctx context.Context, d model.WebhookDelivery) error {
+	_, err := w.db.ExecContext(ctx, queryInsertDelivery, d.WebhookId, d.DeliveryId, d.Event, d.Attempt,
+		d.StatusCode, d.Error, d.DurationMs)
+	if err != nil {
+		return errors.Wrap(err, "insert webhook delivery")
+	}
+
+	return nil
+}
+
+// Deliveries returns attempts of deliveries to webhook, newest first, and number of attempts
+func (w *Webhook) Deliveries(ctx context.Context, webhookId, offset, limit int) (ds []model.WebhookDelivery,
+	total int, err error) {
+	if err = w.db.GetContext(ctx, &total, queryCountDeliveries, webhookId); err != nil {
+		return nil, 0, errors.Wrap(err, "count webhook deliveries")
+	}
+	if err = w.db.SelectContext(ctx, &ds, querySelectDeliveries, webhookId, limit, offset); err != nil {
+		return nil, 0, errors.Wrap(err, "select webhook deliveries")
+	}
+
+	return
+}
+
+func toWebhook(webhookDB model.WebhookDB) model.Webhook {
+	var events []model.WebhookEvent
+	for _, e := range strings.Split(webhookDB.Events, ",") {
+		if e != "" {
+			events = append(events, model.WebhookEvent(e))
+		}
+	}
+
+	return model.Webhook{
+		Id:         webhookDB.Id,
+		StateId:    webhookDB.StateId,
+		UserId:     webhookDB.UserId,
+		Url:        webhookDB.Url,
+		Secret:     webhookDB.Secret,
+		Events:     events,
+		Failures:   webhookDB.Failures,
+		CreatedAt:  webhookDB.CreatedAt,
+		DisabledAt: webhookDB.DisabledAt.Time,
+	}
+}
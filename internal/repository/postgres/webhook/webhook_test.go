@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+	"testing"
+	"time"
+)
+
+func TestWebhook_AddWebhook(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func(db *sqlx.DB) {
+		_ = db.Close()
+	}(db)
+
+	r := NewWebhook(db)
+
+	ctx := context.Background()
+	created := time.Date(2022, 11, 27, 10, 0, 0, 0, time.UTC)
+	columns := []string{"id", "state_id", "user_id", "url", "secret", "events", "failures", "created_at",
+		"disabled_at"}
+	in := model.Webhook{
+		StateId: 3,
+		UserId:  5,
+		Url:     "https://example.com/hook",
+		Secret:  "whsec_new",
+		Events:  []model.WebhookEvent{model.WebhookSpendingAdded, model.WebhookLimitExceeded},
+	}
+	tests := []struct {
+		name string
+		mock func()
+		want model.Webhook
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, 3, 5, "https://example.com/hook", "whsec_new", "spending.added,limit.exceeded", 0,
+						created, nil)
+				mock.ExpectQuery("INSERT INTO webhook").
+					WithArgs(3, 5, "https://example.com/hook", "whsec_new", "spending.added,limit.exceeded").
+					WillReturnRows(rows)
+			},
+			want: model.Webhook{
+				Id:        1,
+				StateId:   3,
+				UserId:    5,
+				Url:       "https://example.com/hook",
+				Secret:    "whsec_new",
+				Events:    []model.WebhookEvent{model.WebhookSpendingAdded, model.WebhookLimitExceeded},
+				CreatedAt: created,
+			},
+		},
+		{
+			name: "Existing url keeps secret",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, 3, 5, "https://example.com/hook", "whsec_old", "spending.added,limit.exceeded", 0,
+						created, nil)
+				mock.ExpectQuery("INSERT INTO webhook").
+					WithArgs(3, 5, "https://example.com/hook", "whsec_new", "spending.added,limit.exceeded").
+					WillReturnRows(rows)
+			},
+			want: model.Webhook{
+				Id:        1,
+				StateId:   3,
+				UserId:    5,
+				Url:       "https://example.com/hook",
+				Secret:    "whsec_old",
+				Events:    []model.WebhookEvent{model.WebhookSpendingAdded, model.WebhookLimitExceeded},
+				CreatedAt: created,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.AddWebhook(ctx, in)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestWebhook_Delivered(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func(db *sqlx.DB) {
+		_ = db.Close()
+	}(db)
+
+	r := NewWebhook(db)
+
+	ctx := context.Background()
+	tests := []struct {
+		name         string
+		ok           bool
+		mock         func()
+		wantDisabled bool
+		wantErr      error
+	}{
+		{
+			name: "Succeeded",
+			ok:   true,
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"disabled"}).AddRow(false)
+				mock.ExpectQuery("UPDATE webhook SET failures = 0").WithArgs(1).WillReturnRows(rows)
+			},
+		},
+		{
+			name: "Failed",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"disabled"}).AddRow(false)
+				mock.ExpectQuery("UPDATE webhook SET failures = failures \\+ 1").
+					WithArgs(1, MaxFailures).WillReturnRows(rows)
+			},
+		},
+		{
+			name: "Disabled",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"disabled"}).AddRow(true)
+				mock.ExpectQuery("UPDATE webhook SET failures = failures \\+ 1").
+					WithArgs(1, MaxFailures).WillReturnRows(rows)
+			},
+			wantDisabled: true,
+		},
+		{
+			name: "Deleted",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"disabled"})
+				mock.ExpectQuery("UPDATE webhook SET failures = failures \\+ 1").
+					WithArgs(1, MaxFailures).WillReturnRows(rows)
+			},
+			wantErr: NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			disabled, err := r.Delivered(ctx, 1, tt.ok)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantDisabled, disabled)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
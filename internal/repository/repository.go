@@ -4,14 +4,24 @@ import (
 	"context"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/account"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/budget"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/category"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/category_limit"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/chat"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/currency"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/debt"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/digest"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/goal"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/rates"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/recurring"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/spending"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/state"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/token"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/user"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/webhook"
 	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/broadcast"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
 	"time"
 )
@@ -19,21 +29,34 @@ import (
 //go:generate mockgen -source=repository.go -destination=mocks/repository.go
 
 type Spending interface {
-	AddEvent(context.Context, int, time.Time, decimal.Decimal) (int, error)
+	AddEvent(context.Context, int, int, int, int, time.Time, decimal.Decimal) (int, error)
 	DeleteEvent(context.Context, int) error
-	Report(context.Context, time.Time, time.Time, rates.Client, model.Currency) (map[int]decimal.Decimal, error)
+	LastEvent(context.Context, int, int) (model.Event, error)
+	Events(context.Context, model.EventFilter, int, int) ([]model.Event, int, error)
+	GetEvent(context.Context, int, int) (model.Event, error)
+	UpdateEvent(context.Context, int, int, int, time.Time, decimal.Decimal) error
+	Report(context.Context, int, time.Time, time.Time, rates.Client, model.Currency) (map[int]decimal.Decimal, error)
+	ReportByMember(context.Context, int, time.Time, time.Time, rates.Client, model.Currency) ([]model.MemberSpend, error)
+	ReportRecurring(context.Context, int, time.Time, time.Time, rates.Client, model.Currency) (map[int]decimal.Decimal,
+		map[int]decimal.Decimal, error)
+	CategorySum(context.Context, int, int, time.Time, time.Time) (decimal.Decimal, error)
+	Watch(string, []int) (*broadcast.Subscription[model.EventChange], error)
+	Cursor(uint64) string
 }
 
 type Categories interface {
 	Categories(context.Context) ([]model.Category, error)
 	AddCategory(context.Context, string) (int, error)
+	UpdateCategory(context.Context, int, string) error
 	DeleteCategory(context.Context, int) error
+	CategoryStates(context.Context, int) ([]int, error)
 	category.Search
 }
 
 type Users interface {
-	AddUser(context.Context, int) (*user.User, error)
+	AddUser(context.Context, int, string) (*user.User, error)
 	GetByTgId(context.Context, int) (*user.User, error)
+	GetById(context.Context, int) (*user.User, error)
 }
 
 type Repository struct {
@@ -45,6 +68,15 @@ type Repository struct {
 	StateClient      state.Client
 	CategorySearch   category.Search
 	CategoryLimitSet category_limit.CategoryLimitSet
+	AccountClient    account.Client
+	RecurringClient  recurring.Client
+	DigestClient     digest.Client
+	BudgetClient     budget.Client
+	ChatClient       chat.Client
+	DebtClient       debt.Client
+	GoalClient       goal.Client
+	TokenClient      token.Client
+	WebhookClient    webhook.Client
 }
 
 func NewRepository(db *sqlx.DB) (*Repository, error) {
@@ -53,10 +85,20 @@ func NewRepository(db *sqlx.DB) (*Repository, error) {
 		return nil, errors.Wrap(err, "create repository currencies")
 	}
 	categoryClient := category.NewCategory(db)
-	spendingClient := spending.NewSpending(db, categoryClient)
+	spendingClient := spending.NewSpending(db, categoryClient,
+		broadcast.New[model.EventChange](spending.ChangesHistory, spending.ChangesBuffer))
 	categoryLimitSet := category_limit.NewCategoryLimit(db, categoryClient)
 	stateClient := state.NewStates(db, currencyClient, categoryLimitSet)
 	usersClient := user.NewUsers(db, currencyClient, stateClient)
+	accountClient := account.NewAccount(db)
+	recurringClient := recurring.NewRecurring(db, spendingClient)
+	digestClient := digest.NewDigest(db)
+	budgetClient := budget.NewBudget(db)
+	chatClient := chat.NewChats(db, stateClient)
+	debtClient := debt.NewDebt(db)
+	goalClient := goal.NewGoal(db)
+	tokenClient := token.NewToken(db)
+	webhookClient := webhook.NewWebhook(db)
 
 	return &Repository{
 		Spending:         spendingClient,
@@ -65,5 +107,14 @@ func NewRepository(db *sqlx.DB) (*Repository, error) {
 		CurrencyClient:   currencyClient,
 		StateClient:      stateClient,
 		CategoryLimitSet: categoryLimitSet,
+		AccountClient:    accountClient,
+		RecurringClient:  recurringClient,
+		DigestClient:     digestClient,
+		BudgetClient:     budgetClient,
+		ChatClient:       chatClient,
+		DebtClient:       debtClient,
+		GoalClient:       goalClient,
+		TokenClient:      tokenClient,
+		WebhookClient:    webhookClient,
 	}, nil
 }
@@ -0,0 +1,443 @@
+package dialog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/session"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Timeout is time dialog waits for the next step of user by default
+	Timeout = time.Hour
+
+	backButton   = "b"
+	cancelButton = "x"
+	inputPrefix  = "input_"
+)
+
+var (
+	StaleError   = errors.New("dialog is stale")
+	ForeignError = errors.New("dialog of another user")
+	ExpiredError = errors.New("dialog is expired")
+
+	// newId is id of keyboard of state
+	newId = session.NewId
+)
+
+// MessageError is shown to user, format is translated to language of user
+type MessageError struct {
+	Format string
+	Args   []any
+}
+
+func (e MessageError) Error() string {
+	return fmt.Sprintf(e.Format, e.Args...)
+}
+
+// Message returns error translated by printer
+func (e MessageError) Message(p *i18n.Printer) string {
+	return p.Sprintf(e.Format, e.Args...)
+}
+
+// Errorf returns error shown to user
+func Errorf(format string, a ...any) error {
+	return MessageError{Format: format, Args: a}
+}
+
+// Button moves dialog to state Next with Data
+type Button[T any] struct {
+	Title string
+	Next  string
+	Data  T
+}
+
+// Prompt is message of state with rows of buttons, back and cancel buttons are added by dialog
+type Prompt[T any] struct {
+	Text string
+	Rows [][]Button[T]
+	// Notes are sent as separate messages after prompt
+	Notes []string
+}
+
+// State of dialog, Prompt of final state runs action of dialog and shows its result
+type State[T any] struct {
+	Prompt func(context.Context, T) (Prompt[T], error)
+	// Input handles text written by user in state, returns the next state and its data
+	Input func(context.Context, T, string) (string, T, error)
+	Final bool
+}
+
+// Flow is multi-step dialog started by command, states keep data T between steps
+type Flow[T any] struct {
+	// Command starts dialog, it is also suggested when buttons are outdated
+	Command string
+	// Prefix of callback data of buttons
+	Prefix string
+	// Start returns the first state and data by arguments of command
+	Start  func(context.Context, tgbotapi.Update) (string, T, error)
+	States map[string]State[T]
+	// Timeout is time to answer on every step, Timeout by default, it is limited by ttl of store
+	Timeout time.Duration
+}
+
+type frame struct {
+	State string          `json:"s"`
+	Data  json.RawMessage `json:"d"`
+}
+
+// record is dialog kept in store between steps
+type record struct {
+	ChatId    int64     `json:"c"`
+	Current   frame     `json:"f"`
+	History   []frame   `json:"h,omitempty"`
+	Buttons   []frame   `json:"b,omitempty"`
+	ExpiresAt time.Time `json:"e"`
+}
+
+type runner interface {
+	command() string
+	prefix() string
+	start(context.Context, *Dialogs, tgbotapi.Update) error
+	press(context.Context, *Dialogs, tgbotapi.Update, record, string) error
+	input(context.Context, *Dialogs, tgbotapi.Update, record, string) error
+}
+
+// Dialogs routes commands, callbacks and text of users to registered flows
+type Dialogs struct {
+	client client.BotClient
+	store  session.Store
+	flows  []runner
+}
+
+func New(client client.BotClient, store session.Store) *Dialogs {
+	return &Dialogs{
+		client: client,
+		store:  store,
+	}
+}
+
+// Register adds flow to dialogs
+func Register[T any](d *Dialogs, f *Flow[T]) {
+	d.flows = append(d.flows, f)
+}
+
+// Handle runs step of dialog by update, handled is false when update does not belong to any dialog
+func (d *Dialogs) Handle(ctx context.Context, update tgbotapi.Update) (handled bool, err error) {
+	switch {
+	case update.Message != nil && update.Message.From != nil && update.Message.IsCommand():
+		for _, f := range d.flows {
+			if f.command() == update.Message.Command() {
+				return true, f.start(ctx, d, update)
+			}
+		}
+	case update.Message != nil && update.Message.From != nil && update.Message.Text != "":
+		return d.input(ctx, update)
+	case update.CallbackQuery != nil && update.CallbackQuery.From != nil:
+		for _, f := range d.flows {
+			if strings.HasPrefix(update.CallbackQuery.Data, f.prefix()) {
+				return true, d.press(ctx, f, update)
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func (d *Dialogs) press(ctx context.Context, f runner, update tgbotapi.Update) error {
+	// ids of dialogs are url safe base64 and may contain underscore, buttons do not
+	data := strings.TrimPrefix(update.CallbackQuery.Data, f.prefix())
+	sep := strings.LastIndex(data, "_")
+	if sep < 0 {
+		return d.fail(ctx, f, StaleError, update.CallbackQuery.Message.Chat.ID)
+	}
+	id, button := data[:sep], data[sep+1:]
+	rec, err := d.consume(ctx, id, update.CallbackQuery.From.ID)
+	if err != nil {
+		return d.fail(ctx, f, err, update.CallbackQuery.Message.Chat.ID)
+	}
+
+	return f.press(ctx, d, update, rec, button)
+}
+
+func (d *Dialogs) input(ctx context.Context, update tgbotapi.Update) (bool, error) {
+	key := inputKey(update.Message.Chat.ID, update.Message.From.ID)
+	pointer, err := d.store.Get(ctx, key)
+	if errors.Is(err, session.NotFoundError) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "get dialog input")
+	}
+	// pointer keeps id of dialog and prefix of its flow
+	if len(pointer.Values) != 2 {
+		return false, nil
+	}
+	sess, err := d.store.Get(ctx, pointer.Values[0])
+	if errors.Is(err, session.NotFoundError) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "get dialog")
+	}
+	rec, err := unmarshalRecord(sess)
+	if err != nil {
+		return false, err
+	}
+	if !time.Now().Before(rec.ExpiresAt) {
+		return false, nil
+	}
+
+	for _, f := range d.flows {
+		if pointer.Values[1] == f.prefix() {
+			return true, f.input(ctx, d, update, rec, pointer.Values[0])
+		}
+	}
+
+	return false, nil
+}
+
+// consume returns dialog of pressed keyboard and removes it, so repeated or concurrent presses are stale
+func (d *Dialogs) consume(ctx context.Context, id string, userId int) (rec record, err error) {
+	sess, err := d.store.Get(ctx, id)
+	if errors.Is(err, session.NotFoundError) {
+		return rec, StaleError
+	}
+	if err != nil {
+		return rec, errors.Wrap(err, "get dialog")
+	}
+	if sess.UserId != userId {
+		return rec, ForeignError
+	}
+	deleted, err := d.store.Delete(ctx, id)
+	if err != nil {
+		return rec, errors.Wrap(err, "delete dialog")
+	}
+	if !deleted {
+		return rec, StaleError
+	}
+	if rec, err = unmarshalRecord(sess); err != nil {
+		return rec, err
+	}
+	if !time.Now().Before(rec.ExpiresAt) {
+		return rec, ExpiredError
+	}
+
+	return rec, nil
+}
+
+// fail tells user why dialog does not go on
+func (d *Dialogs) fail(ctx context.Context, f runner, err error, chatId int64) error {
+	pr := i18n.FromContext(ctx)
+	var msgErr MessageError
+	switch {
+	case errors.As(err, &msgErr):
+		_ = d.client.SendMessage(msgErr.Message(pr), chatId)
+	case errors.Is(err, StaleError):
+		_ = d.client.SendMessage(pr.Sprintf("Buttons are outdated, please start again with /%s", f.command()), chatId)
+	case errors.Is(err, ForeignError):
+		_ = d.client.SendMessage(pr.Sprintf("These buttons were opened by another member, please use /%s",
+			f.command()), chatId)
+	case errors.Is(err, ExpiredError):
+		_ = d.client.SendMessage(pr.Sprintf("Time to answer is over, please start again with /%s", f.command()), chatId)
+	}
+
+	return errors.Wrap(err, "dialog "+f.command())
+}
+
+func (f *Flow[T]) command() string {
+	return f.Command
+}
+
+func (f *Flow[T]) prefix() string {
+	return f.Prefix
+}
+
+func (f *Flow[T]) start(ctx context.Context, d *Dialogs, update tgbotapi.Update) error {
+	state, data, err := f.Start(ctx, update)
+	if err != nil {
+		return d.fail(ctx, f, err, update.Message.Chat.ID)
+	}
+	current, err := newFrame(state, data)
+	if err != nil {
+		return err
+	}
+
+	return f.render(ctx, d, record{ChatId: update.Message.Chat.ID, Current: current}, update.Message.From.ID, 0)
+}
+
+func (f *Flow[T]) press(ctx context.Context, d *Dialogs, update tgbotapi.Update, rec record, button string) error {
+	messageId := update.CallbackQuery.Message.MessageID
+	switch button {
+	case cancelButton:
+		_, _ = d.store.Delete(ctx, inputKey(rec.ChatId, update.CallbackQuery.From.ID))
+		return d.client.SendCallbackQuery(nil, i18n.FromContext(ctx).Sprintf("Cancelled"), messageId, rec.ChatId)
+	case backButton:
+		if len(rec.History) == 0 {
+			return d.fail(ctx, f, StaleError, rec.ChatId)
+		}
+		rec.Current = rec.History[len(rec.History)-1]
+		rec.History = rec.History[:len(rec.History)-1]
+	default:
+		idx, err := strconv.Atoi(button)
+		if err != nil || idx < 0 || idx >= len(rec.Buttons) {
+			return d.fail(ctx, f, StaleError, rec.ChatId)
+		}
+		rec.History = append(rec.History, rec.Current)
+		rec.Current = rec.Buttons[idx]
+	}
+
+	return f.render(ctx, d, rec, update.CallbackQuery.From.ID, messageId)
+}
+
+func (f *Flow[T]) input(ctx context.Context, d *Dialogs, update tgbotapi.Update, rec record, id string) error {
+	state, ok := f.States[rec.Current.State]
+	if !ok || state.Input == nil {
+		return errors.Errorf("dialog %s has no input in state %s", f.Command, rec.Current.State)
+	}
+	var data T
+	if err := json.Unmarshal(rec.Current.Data, &data); err != nil {
+		return errors.Wrap(err, "unmarshal dialog data")
+	}
+	next, data, err := state.Input(ctx, data, update.Message.Text)
+	if err != nil {
+		// user corrects text and stays in state
+		return d.fail(ctx, f, err, rec.ChatId)
+	}
+	deleted, err := d.store.Delete(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "delete dialog")
+	}
+	if !deleted {
+		return d.fail(ctx, f, StaleError, rec.ChatId)
+	}
+	current, err := newFrame(next, data)
+	if err != nil {
+		return err
+	}
+	rec.History = append(rec.History, rec.Current)
+	rec.Current = current
+
+	return f.render(ctx, d, rec, update.Message.From.ID, 0)
+}
+
+// render shows prompt of current state by new message or by message of pressed keyboard and saves dialog
+func (f *Flow[T]) render(ctx context.Context, d *Dialogs, rec record, userId, messageId int) error {
+	state, ok := f.States[rec.Current.State]
+	if !ok {
+		return errors.Errorf("dialog %s has no state %s", f.Command, rec.Current.State)
+	}
+	var data T
+	if err := json.Unmarshal(rec.Current.Data, &data); err != nil {
+		return errors.Wrap(err, "unmarshal dialog data")
+	}
+	prompt, err := state.Prompt(ctx, data)
+	if err != nil {
+		_, _ = d.store.Delete(ctx, inputKey(rec.ChatId, userId))
+		return d.fail(ctx, f, err, rec.ChatId)
+	}
+
+	id := newId()
+	rec.Buttons = rec.Buttons[:0]
+	rows := make([]*client.KeyboardRow, 0, len(prompt.Rows)+1)
+	if !state.Final {
+		for _, buttons := range prompt.Rows {
+			row := client.NewKeyboardRow()
+			for _, b := range buttons {
+				next, err := newFrame(b.Next, b.Data)
+				if err != nil {
+					return err
+				}
+				row.Add(b.Title, f.Prefix+id+"_"+strconv.Itoa(len(rec.Buttons)))
+				rec.Buttons = append(rec.Buttons, next)
+			}
+			rows = append(rows, row)
+		}
+		pr := i18n.FromContext(ctx)
+		nav := client.NewKeyboardRow()
+		if len(rec.History) > 0 {
+			nav.Add(pr.Sprintf("<< Back"), f.Prefix+id+"_"+backButton)
+		}
+		nav.Add(pr.Sprintf("Cancel"), f.Prefix+id+"_"+cancelButton)
+		rows = append(rows, nav)
+
+		if err = f.save(ctx, d, rec, id, userId, state.Input != nil); err != nil {
+			return err
+		}
+	} else {
+		_, _ = d.store.Delete(ctx, inputKey(rec.ChatId, userId))
+	}
+
+	switch {
+	case messageId > 0:
+		err = d.client.SendCallbackQuery(rows, prompt.Text, messageId, rec.ChatId)
+	case len(rows) > 0:
+		err = d.client.SendInlineKeyboard(rows, prompt.Text, rec.ChatId)
+	default:
+		err = d.client.SendMessage(prompt.Text, rec.ChatId)
+	}
+	if err != nil {
+		return err
+	}
+	for _, note := range prompt.Notes {
+		_ = d.client.SendMessage(note, rec.ChatId)
+	}
+
+	return nil
+}
+
+// save keeps dialog until timeout, text of user goes to dialog when state waits for input
+func (f *Flow[T]) save(ctx context.Context, d *Dialogs, rec record, id string, userId int, input bool) error {
+	timeout := f.Timeout
+	if timeout <= 0 {
+		timeout = Timeout
+	}
+	rec.ExpiresAt = time.Now().Add(timeout)
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return errors.Wrap(err, "marshal dialog")
+	}
+	if err = d.store.Save(ctx, id, session.Session{UserId: userId, Values: []string{string(b)}}); err != nil {
+		return errors.Wrap(err, "save dialog")
+	}
+
+	key := inputKey(rec.ChatId, userId)
+	if !input {
+		_, err = d.store.Delete(ctx, key)
+		return errors.Wrap(err, "delete dialog input")
+	}
+	err = d.store.Save(ctx, key, session.Session{UserId: userId, Values: []string{id, f.Prefix}})
+
+	return errors.Wrap(err, "save dialog input")
+}
+
+func newFrame[T any](state string, data T) (frame, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return frame{}, errors.Wrap(err, "marshal dialog data")
+	}
+
+	return frame{State: state, Data: b}, nil
+}
+
+func unmarshalRecord(sess session.Session) (rec record, err error) {
+	if len(sess.Values) == 0 {
+		return rec, StaleError
+	}
+	if err = json.Unmarshal([]byte(sess.Values[0]), &rec); err != nil {
+		return rec, errors.Wrap(err, "unmarshal dialog")
+	}
+
+	return rec, nil
+}
+
+func inputKey(chatId int64, userId int) string {
+	return inputPrefix + strconv.FormatInt(chatId, 10) + "_" + strconv.Itoa(userId)
+}
@@ -0,0 +1,220 @@
+package dialog
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const chatId = 15
+
+type order struct {
+	Count int
+	Name  string
+}
+
+// botClient keeps the last message and its buttons
+type botClient struct {
+	text    string
+	buttons []string
+}
+
+func (c *botClient) SendMessage(message string, _ int64) error {
+	c.text, c.buttons = message, nil
+	return nil
+}
+
+func (c *botClient) SendInlineKeyboard(rows []*client.KeyboardRow, message string, _ int64) error {
+	c.text, c.buttons = message, nil
+	for _, row := range rows {
+		c.buttons = append(c.buttons, row.Values()...)
+	}
+	return nil
+}
+
+func (c *botClient) SendCallbackQuery(rows []*client.KeyboardRow, message string, _ int, chatId int64) error {
+	return c.SendInlineKeyboard(rows, message, chatId)
+}
+
+func orderFlow() *Flow[order] {
+	return &Flow[order]{
+		Command: "order",
+		Prefix:  "order_",
+		Start: func(ctx context.Context, update tgbotapi.Update) (string, order, error) {
+			if update.Message.CommandArguments() != "" {
+				return "", order{}, Errorf("Order takes no arguments")
+			}
+			return "count", order{}, nil
+		},
+		States: map[string]State[order]{
+			"count": {
+				Prompt: func(ctx context.Context, o order) (Prompt[order], error) {
+					return Prompt[order]{
+						Text: "Choose count:",
+						Rows: [][]Button[order]{{
+							{Title: "1", Next: "name", Data: order{Count: 1}},
+							{Title: "2", Next: "name", Data: order{Count: 2}},
+						}},
+					}, nil
+				},
+			},
+			"name": {
+				Prompt: func(ctx context.Context, o order) (Prompt[order], error) {
+					return Prompt[order]{Text: "Write name of order " + strconv.Itoa(o.Count) + ":"}, nil
+				},
+				Input: func(ctx context.Context, o order, text string) (string, order, error) {
+					if len(text) > 10 {
+						return "", o, Errorf("Name is too long")
+					}
+					o.Name = text
+					return "done", o, nil
+				},
+			},
+			"done": {
+				Prompt: func(ctx context.Context, o order) (Prompt[order], error) {
+					return Prompt[order]{Text: "Ordered " + strconv.Itoa(o.Count) + " " + o.Name}, nil
+				},
+				Final: true,
+			},
+		},
+	}
+}
+
+func command(text string, userId int) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		Text:     text,
+		From:     &tgbotapi.User{ID: userId},
+		Chat:     &tgbotapi.Chat{ID: chatId},
+		Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Length: len("/order")}},
+	}}
+}
+
+func text(text string, userId int) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		Text: text,
+		From: &tgbotapi.User{ID: userId},
+		Chat: &tgbotapi.Chat{ID: chatId},
+	}}
+}
+
+func press(data string, userId int) tgbotapi.Update {
+	return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		Data:    data,
+		From:    &tgbotapi.User{ID: userId},
+		Message: &tgbotapi.Message{MessageID: 1, Chat: &tgbotapi.Chat{ID: chatId}},
+	}}
+}
+
+func TestDialogs_Handle(t *testing.T) {
+	ctx := context.Background()
+	c := &botClient{}
+	d := New(c, session.NewMemory(time.Hour))
+	Register(d, orderFlow())
+
+	handled, err := d.Handle(ctx, text("hello", 5))
+	assert.False(t, handled)
+	assert.NoError(t, err)
+
+	handled, err = d.Handle(ctx, command("/order now", 5))
+	assert.True(t, handled)
+	assert.Error(t, err)
+	assert.Equal(t, "Order takes no arguments", c.text)
+
+	_, err = d.Handle(ctx, command("/order", 5))
+	require.NoError(t, err)
+	assert.Equal(t, "Choose count:", c.text)
+	// buttons of state and cancel, no back on the first step
+	require.Len(t, c.buttons, 3)
+	countButtons := c.buttons
+
+	_, err = d.Handle(ctx, press(countButtons[1], 6))
+	assert.ErrorIs(t, err, ForeignError)
+	assert.Contains(t, c.text, "another member")
+
+	_, err = d.Handle(ctx, press(countButtons[1], 5))
+	require.NoError(t, err)
+	assert.Equal(t, "Write name of order 2:", c.text)
+	require.Len(t, c.buttons, 2)
+	nameButtons := c.buttons
+
+	// pressed keyboard is consumed
+	_, err = d.Handle(ctx, press(countButtons[0], 5))
+	assert.ErrorIs(t, err, StaleError)
+	assert.Equal(t, "Buttons are outdated, please start again with /order", c.text)
+
+	// text of another member does not go to dialog
+	handled, _ = d.Handle(ctx, text("Bob", 6))
+	assert.False(t, handled)
+
+	handled, err = d.Handle(ctx, text("Very long name", 5))
+	assert.True(t, handled)
+	assert.Error(t, err)
+	assert.Equal(t, "Name is too long", c.text)
+
+	// back returns to previous state
+	_, err = d.Handle(ctx, press(nameButtons[0], 5))
+	require.NoError(t, err)
+	assert.Equal(t, "Choose count:", c.text)
+	require.Len(t, c.buttons, 3)
+
+	_, err = d.Handle(ctx, press(c.buttons[0], 5))
+	require.NoError(t, err)
+	handled, err = d.Handle(ctx, text("Tea", 5))
+	assert.True(t, handled)
+	require.NoError(t, err)
+	assert.Equal(t, "Ordered 1 Tea", c.text)
+	assert.Empty(t, c.buttons)
+
+	// dialog is over, text is not input anymore
+	handled, _ = d.Handle(ctx, text("Coffee", 5))
+	assert.False(t, handled)
+}
+
+func TestDialogs_CancelAndTimeout(t *testing.T) {
+	ctx := context.Background()
+	c := &botClient{}
+	d := New(c, session.NewMemory(time.Hour))
+	flow := orderFlow()
+	Register(d, flow)
+
+	_, err := d.Handle(ctx, command("/order", 5))
+	require.NoError(t, err)
+	_, err = d.Handle(ctx, press(c.buttons[0], 5))
+	require.NoError(t, err)
+	_, err = d.Handle(ctx, press(c.buttons[len(c.buttons)-1], 5))
+	require.NoError(t, err)
+	assert.Equal(t, "Cancelled", c.text)
+	handled, _ := d.Handle(ctx, text("Tea", 5))
+	assert.False(t, handled)
+
+	flow.Timeout = time.Nanosecond
+	_, err = d.Handle(ctx, command("/order", 5))
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	_, err = d.Handle(ctx, press(c.buttons[0], 5))
+	assert.ErrorIs(t, err, ExpiredError)
+	assert.Equal(t, "Time to answer is over, please start again with /order", c.text)
+}
+
+func TestDialogs_IdWithUnderscore(t *testing.T) {
+	defer func(f func() string) { newId = f }(newId)
+	newId = func() string { return "a_b_c" }
+	ctx := context.Background()
+	c := &botClient{}
+	d := New(c, session.NewMemory(time.Hour))
+	Register(d, orderFlow())
+
+	_, err := d.Handle(ctx, command("/order", 5))
+	require.NoError(t, err)
+	assert.Equal(t, "order_a_b_c_1", c.buttons[1])
+
+	_, err = d.Handle(ctx, press(c.buttons[1], 5))
+	require.NoError(t, err)
+	assert.Equal(t, "Write name of order 2:", c.text)
+}
@@ -6,20 +6,46 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/chat"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/rates"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/token"
 	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/auth"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/user"
+	"strings"
 )
 
 type Middleware struct {
+	*Auth
 	users  repository.Users
+	chats  chat.Client
 	rates  rates.Client
 	client client.BotClient
 }
 
-func NewMiddleware(users repository.Users, client client.BotClient, rates rates.Client) *Middleware {
+// Auth resolves api tokens to users, it is shared by bot and report service
+type Auth struct {
+	users  repository.Users
+	tokens token.Client
+	admins []int
+}
+
+// NewAuth returns auth, write tokens of admins with telegram ids get admin scope
+func NewAuth(users repository.Users, tokens token.Client, admins []int) *Auth {
+	return &Auth{
+		users:  users,
+		tokens: tokens,
+		admins: admins,
+	}
+}
+
+func NewMiddleware(users repository.Users, chats chat.Client, tokens token.Client, client client.BotClient,
+	rates rates.Client, admins []int) *Middleware {
 	return &Middleware{
+		Auth:   NewAuth(users, tokens, admins),
 		users:  users,
+		chats:  chats,
 		rates:  rates,
 		client: client,
 	}
@@ -29,24 +55,112 @@ func (m Middleware) DefineUser(ctx context.Context, update tgbotapi.Update) (con
 	span, ctx := opentracing.StartSpanFromContext(ctx, "DefineUser")
 	defer span.Finish()
 
-	userId := 0
+	userId, name, languageCode := 0, "", ""
+	var chatTg *tgbotapi.Chat
 	if update.Message != nil {
-		userId = update.Message.From.ID
+		userId, name = update.Message.From.ID, memberName(update.Message.From)
+		languageCode = update.Message.From.LanguageCode
+		chatTg = update.Message.Chat
 	} else if update.CallbackQuery != nil {
-		userId = update.CallbackQuery.From.ID
+		userId, name = update.CallbackQuery.From.ID, memberName(update.CallbackQuery.From)
+		languageCode = update.CallbackQuery.From.LanguageCode
+		if update.CallbackQuery.Message != nil {
+			chatTg = update.CallbackQuery.Message.Chat
+		}
 	}
 
 	span.SetTag("tgUserId", userId)
 
-	u, err := m.users.AddUser(ctx, userId)
+	u, err := m.users.AddUser(ctx, userId, name)
 	if err != nil {
 		return nil, errors.Wrap(err, "define user")
 	}
+	if chatTg != nil && !chatTg.IsPrivate() {
+		// in group chat user works with shared ledger of chat instead of own state
+		span.SetTag("tgChatId", chatTg.ID)
+		u.State, err = m.chats.AddChat(ctx, chatTg.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "define chat")
+		}
+		if err = m.chats.AddMember(ctx, u.State.Id, u.Id); err != nil {
+			return nil, errors.Wrap(err, "define chat member")
+		}
+	}
+	ctx = user.ToContext(ctx, u)
+	ctx = i18n.WithPrinter(ctx, i18n.NewPrinter(userLang(u.Language, languageCode)))
+
+	return ctx, nil
+}
+
+// userLang returns language chosen by /language or language of telegram client
+func userLang(language, languageCode string) i18n.Lang {
+	if language != "" {
+		return i18n.Parse(language)
+	}
+
+	return i18n.Parse(languageCode)
+}
+
+// Authenticate resolves api token to user in context like DefineUser does for telegram updates,
+// identity keeps scopes of token and states user has access to
+func (a *Auth) Authenticate(ctx context.Context, rawToken string) (context.Context, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Authenticate")
+	defer span.Finish()
+
+	t, err := a.tokens.UseToken(ctx, auth.Hash(rawToken))
+	if err != nil {
+		return nil, errors.Wrap(err, "authenticate")
+	}
+	span.SetTag("userId", t.UserId)
+
+	u, err := a.users.GetById(ctx, t.UserId)
+	if err != nil {
+		return nil, errors.Wrap(err, "authenticate user")
+	}
+	states, err := a.tokens.States(ctx, t.UserId)
+	if err != nil {
+		return nil, errors.Wrap(err, "authenticate states")
+	}
+
+	scopes := t.Scopes
+	if scopes.Has(auth.ScopeWrite) && a.isAdmin(u.TgId) {
+		scopes = append(append(auth.Scopes{}, scopes...), auth.ScopeAdmin)
+	}
+
 	ctx = user.ToContext(ctx, u)
+	ctx = i18n.WithPrinter(ctx, i18n.NewPrinter(userLang(u.Language, "")))
+	ctx = auth.ToContext(ctx, auth.Identity{
+		UserId: u.Id,
+		Scopes: scopes,
+		States: states,
+	})
 
 	return ctx, nil
 }
 
+func (a *Auth) isAdmin(tgId int) bool {
+	for _, id := range a.admins {
+		if id == tgId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// memberName returns name of member shown in reports of shared ledger
+func memberName(u *tgbotapi.User) string {
+	if u == nil {
+		return ""
+	}
+	name := strings.TrimSpace(u.FirstName + " " + u.LastName)
+	if name == "" {
+		name = u.UserName
+	}
+
+	return name
+}
+
 func (m Middleware) UpdateRatesSync(ctx context.Context) (run bool) {
 	return m.rates.UpdateRatesSync(ctx)
 }
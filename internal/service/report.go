@@ -2,26 +2,49 @@ package service
 
 import (
 	"context"
+	"github.com/Shopify/sarama"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/rates"
+	"github.com/sku4/ozon-route256-spending-bot/internal/service/middleware"
 	"github.com/sku4/ozon-route256-spending-bot/internal/service/spending"
-	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/model/kafka"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/api"
-	"time"
 )
 
 //go:generate mockgen -source=service.go -destination=mocks/report.go
 
 type BuildReport interface {
-	Build(context.Context, time.Time, time.Time, model.Currency, int64) error
+	Build(context.Context, kafka.Report) error
+}
+
+type DigestScheduler interface {
+	RunDigest(context.Context)
+}
+
+type QueryReport interface {
+	Query(context.Context, spending.ReportQuery) (spending.ReportData, error)
+}
+
+type Authenticator interface {
+	Authenticate(context.Context, string) (context.Context, error)
 }
 
 type ReportService struct {
 	BuildReport
+	DigestScheduler
+	QueryReport
+	Authenticator
 }
 
-func NewReportService(repos *repository.Repository, rates rates.Client, grpcClient api.SpendingClient) *ReportService {
+func NewReportService(repos *repository.Repository, rates rates.Client, grpcClient api.SpendingClient,
+	kafkaProducer sarama.AsyncProducer) *ReportService {
+	report := spending.NewReport(repos.Spending, repos.Categories, repos.RecurringClient, repos.StateClient,
+		repos.CurrencyClient, repos.GoalClient, repos.BudgetClient, rates, grpcClient)
+
 	return &ReportService{
-		BuildReport: spending.NewReport(repos.Spending, repos.Categories, rates, grpcClient),
+		BuildReport:     report,
+		DigestScheduler: spending.NewDigestScheduler(repos.DigestClient, kafkaProducer),
+		QueryReport:     report,
+		Authenticator:   middleware.NewAuth(repos.Users, repos.TokenClient, nil),
 	}
 }
@@ -8,61 +8,151 @@ import (
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/rates"
 	"github.com/sku4/ozon-route256-spending-bot/internal/service/middleware"
 	"github.com/sku4/ozon-route256-spending-bot/internal/service/spending"
+	"github.com/sku4/ozon-route256-spending-bot/model"
 	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/broadcast"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/pagination"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/session"
 	"time"
 )
 
 //go:generate mockgen -source=service.go -destination=mocks/service.go
 
 type Spending interface {
-	Start(context.Context, tgbotapi.Update) error
+	Help(context.Context, tgbotapi.Update, string) error
 	ErrorMessage(context.Context, tgbotapi.Update, string) error
 	NotFound(context.Context, tgbotapi.Update) error
-	SpendingAdd(context.Context, tgbotapi.Update) error
-	SpendingAddQuery(context.Context, tgbotapi.Update) error
+	Dialog(context.Context, tgbotapi.Update) (bool, error)
+	Language(context.Context, tgbotapi.Update) error
 	Categories
 	Report
-	Currency
 	CategoryLimit
+	Account
+	Recurring
+	Digest
+	Budget
+	PeriodStart
+	Split
+	Goal
+	Token
+	Webhook
+	Api
 }
 
 type Categories interface {
-	Categories(context.Context, tgbotapi.Update) error
 	CategoryAdd(context.Context, tgbotapi.Update) error
-	CategoriesQuery(context.Context, tgbotapi.Update) error
 }
 
 type CategoryLimit interface {
-	LimitAdd(context.Context, tgbotapi.Update) error
-	LimitQuery(context.Context, tgbotapi.Update) error
+	Thresholds(context.Context, tgbotapi.Update) error
+	Limits(context.Context, tgbotapi.Update) error
+	LimitsQuery(context.Context, tgbotapi.Update) error
+	StateLimits(context.Context, int) ([]spending.LimitSpend, model.Currency, error)
+	StateSetLimit(context.Context, int, int, decimal.Decimal, period.Range) error
+	StateDeleteLimit(context.Context, int, int) error
+}
+
+type Account interface {
+	Accounts(context.Context, tgbotapi.Update) error
+	AccountAdd(context.Context, tgbotapi.Update) error
+	AccountsQuery(context.Context, tgbotapi.Update) error
+	Transfer(context.Context, tgbotapi.Update) error
+	TransferQuery(context.Context, tgbotapi.Update) error
+	Balance(context.Context, tgbotapi.Update) error
+}
+
+type Recurring interface {
+	Recurring(context.Context, tgbotapi.Update) error
+	RecurringQuery(context.Context, tgbotapi.Update) error
+	RunRecurring(context.Context)
+}
+
+type Digest interface {
+	Digest(context.Context, tgbotapi.Update) error
+	DigestQuery(context.Context, tgbotapi.Update) error
+}
+
+type Budget interface {
+	Budget(context.Context, tgbotapi.Update) error
+	Plan(context.Context, tgbotapi.Update) error
+	PlanQuery(context.Context, tgbotapi.Update) error
+}
+
+type PeriodStart interface {
+	WeekStart(context.Context, tgbotapi.Update) error
+	MonthStart(context.Context, tgbotapi.Update) error
+	Timezone(context.Context, tgbotapi.Update) error
+}
+
+type Split interface {
+	Split(context.Context, tgbotapi.Update) error
+	Settle(context.Context, tgbotapi.Update) error
+	SettleQuery(context.Context, tgbotapi.Update) error
+}
+
+type Goal interface {
+	Goal(context.Context, tgbotapi.Update) error
+	GoalQuery(context.Context, tgbotapi.Update) error
+}
+
+type Token interface {
+	Token(context.Context, tgbotapi.Update) error
+	TokenQuery(context.Context, tgbotapi.Update) error
+}
+
+type Webhook interface {
+	Webhook(context.Context, tgbotapi.Update) error
+	WebhookQuery(context.Context, tgbotapi.Update) error
+	StateWebhooks(context.Context, int) ([]model.Webhook, error)
+	StateAddWebhook(context.Context, int, string, []string) (model.Webhook, error)
+	StateDeleteWebhook(context.Context, int, int) error
+	StateWebhookDeliveries(context.Context, int, int, pagination.Page) ([]model.WebhookDelivery, int, error)
+	ShutdownWebhooks(context.Context) error
+}
+
+// Api is data of states for grpc and rest api, amounts are in currency of state
+type Api interface {
+	StateEvents(context.Context, model.EventFilter, pagination.Page) ([]spending.EventSpend, model.Currency, int, error)
+	StateAddEvent(context.Context, int, int, int, time.Time, decimal.Decimal) (spending.EventSpend, model.Currency, error)
+	StateUpdateEvent(context.Context, int, int, int, time.Time, decimal.Decimal) (spending.EventSpend, model.Currency,
+		error)
+	StateDeleteEvent(context.Context, int, int) error
+	WatchEvents([]int, string) (*broadcast.Subscription[model.EventChange], error)
+	EventsCursor(uint64) string
+	StateEventSpend(context.Context, int, model.Event) (spending.EventSpend, model.Currency, error)
+	CategoriesPage(context.Context, string, pagination.Page) ([]model.Category, int, error)
+	AddCategory(context.Context, string) (*model.Category, error)
+	UpdateCategory(context.Context, int, string) (*model.Category, error)
+	DeleteCategory(context.Context, int, []int) error
+	StateUpdateLimit(context.Context, int, int, decimal.Decimal, period.Range) error
+	StateSettings(context.Context, int) (spending.Settings, error)
+	StateUpdateSettings(context.Context, int, spending.SettingsUpdate) (spending.Settings, error)
 }
 
 type Report interface {
 	Report7(context.Context, tgbotapi.Update) error
 	Report31(context.Context, tgbotapi.Update) error
 	Report365(context.Context, tgbotapi.Update) error
-	SendReport(context.Context, string, time.Time, time.Time, int64) error
+	SendReport(context.Context, string, time.Time, time.Time, int64, int) error
 }
 
 type Middleware interface {
 	DefineUser(context.Context, tgbotapi.Update) (context.Context, error)
+	Authenticate(context.Context, string) (context.Context, error)
 	UpdateRatesSync(context.Context) bool
 	RatesSyncChan(context.Context) <-chan error
 }
 
-type Currency interface {
-	Currency(context.Context, tgbotapi.Update) error
-	CurrencyQuery(context.Context, tgbotapi.Update) error
-}
-
 type Service struct {
 	Spending
 	Middleware
 }
 
-func NewService(repos *repository.Repository, client client.BotClient, rates rates.Client, kafkaProducer sarama.AsyncProducer) *Service {
+func NewService(repos *repository.Repository, sessions session.Store, client client.BotClient, rates rates.Client, kafkaProducer sarama.AsyncProducer, admins []int) *Service {
 	return &Service{
-		Spending:   spending.NewService(repos.Spending, repos.Categories, repos.CurrencyClient, client, rates, kafkaProducer),
-		Middleware: middleware.NewMiddleware(repos.Users, client, rates),
+		Spending:   spending.NewService(repos.Spending, repos.Categories, repos.CurrencyClient, repos.AccountClient, repos.RecurringClient, repos.DigestClient, repos.StateClient, repos.BudgetClient, repos.ChatClient, repos.DebtClient, repos.GoalClient, repos.TokenClient, repos.WebhookClient, sessions, client, rates, kafkaProducer),
+		Middleware: middleware.NewMiddleware(repos.Users, repos.ChatClient, repos.TokenClient, client, rates, admins),
 	}
 }
@@ -0,0 +1,399 @@
+package spending
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	accountsPrefix = "accounts_"
+	transferPrefix = "transfer_"
+)
+
+func (s *Service) Accounts(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	var inlineKeyboardRows []*client.KeyboardRow
+	inlineKeyboardRow := client.NewKeyboardRow()
+	inlineKeyboardRow.Add(pr.Sprintf("Add"), accountsPrefix+"add")
+	inlineKeyboardRow.Add(pr.Sprintf("List"), accountsPrefix+"list")
+	inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
+
+	err = s.client.SendInlineKeyboard(inlineKeyboardRows,
+		pr.Sprintf("Choose accounts command:"), update.Message.Chat.ID)
+	if err != nil {
+		return err
+	}
+
+	return
+}
+
+// AccountAdd parses `/accountadd Credit card USD 1000`, currency and opening balance are optional
+func (s *Service) AccountAdd(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	if !s.rates.IsLoaded(ctx) {
+		_ = s.client.SendMessage(pr.Sprintf("Rates not loaded, please repeat later"), update.Message.Chat.ID)
+		return errors.New("rates still not loaded")
+	}
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		_ = s.client.SendMessage(pr.Sprintf("Account title is empty, please set title"), update.Message.Chat.ID)
+		return errors.New("account title is empty")
+	}
+
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+	accCurrency, err := uState.GetCurrency(ctx)
+	if err != nil {
+		return errors.Wrap(err, "currency not found")
+	}
+
+	openingBalance := float64(0)
+	if len(args) > 1 {
+		if b, errParse := strconv.ParseFloat(args[len(args)-1], 64); errParse == nil {
+			openingBalance = b
+			args = args[:len(args)-1]
+		}
+	}
+	if len(args) > 1 {
+		if c, errCurr := s.reposCurr.GetByAbbr(ctx, strings.ToUpper(args[len(args)-1])); errCurr == nil {
+			accCurrency = c
+			args = args[:len(args)-1]
+		}
+	}
+	title := strings.Join(args, " ")
+
+	accRate, ok := s.rates.GetRate(ctx, accCurrency)
+	if !ok {
+		_ = s.client.SendMessage(pr.Sprintf(
+			"Rate *%s* not found", accCurrency.Abbr), update.Message.Chat.ID)
+		return errors.New("account rate not found")
+	}
+
+	_, err = s.reposAcc.AddAccount(ctx, uState.Id, title, accCurrency,
+		decimal.ToDecimal(openingBalance).Multiply(accRate.Rate))
+	if err != nil {
+		_ = s.client.SendMessage(pr.Sprintf(
+			"Error add account *%s*: %s", title, err.Error()), update.Message.Chat.ID)
+		return errors.Wrap(err, "add account")
+	}
+	err = s.client.SendMessage(pr.Sprintf(
+		"Account *%s* with balance *%.2f %s* success added\r\n"+
+			"Show /accounts /balance", title, openingBalance, accCurrency.Abbr), update.Message.Chat.ID)
+
+	return
+}
+
+func (s *Service) AccountsQuery(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	var inlineKeyboardRows []*client.KeyboardRow
+	inlineKeyboardRow := client.NewKeyboardRow()
+	chatId := update.CallbackQuery.Message.Chat.ID
+	messageId := update.CallbackQuery.Message.MessageID
+
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+
+	data := update.CallbackQuery.Data[len(accountsPrefix):]
+	switch {
+	case data == "home":
+		inlineKeyboardRow.Add(pr.Sprintf("Add"), accountsPrefix+"add")
+		inlineKeyboardRow.Add(pr.Sprintf("List"), accountsPrefix+"list")
+		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
+		err = s.client.SendCallbackQuery(inlineKeyboardRows, pr.Sprintf("Choose accounts command:"), messageId, chatId)
+	case data == "add":
+		err = s.client.SendMessage(pr.Sprintf("Write `/accountadd Cash USD 100` to added account"), chatId)
+	case data == "list" || strings.Index(data, "del_") == 0:
+		msg := pr.Sprintf("Accounts list:")
+		if strings.Index(data, "del_") == 0 {
+			accountId, errConv := strconv.Atoi(data[len("del_"):])
+			if errConv != nil {
+				return errors.Wrap(errConv, "account id convert")
+			}
+			if err = s.reposAcc.DeleteAccount(ctx, uState.Id, accountId); err != nil {
+				_ = s.client.SendMessage(pr.Sprintf("Account not deleted: %s", err.Error()), chatId)
+				return errors.Wrap(err, "delete account")
+			}
+			msg = pr.Sprintf("Account success deleted") + "\r\n" + msg
+		}
+		accounts, errList := s.reposAcc.Accounts(ctx, uState.Id)
+		if errList != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Accounts: %s", errList.Error()), chatId)
+			return errors.Wrap(errList, "accounts list")
+		}
+		if len(accounts) == 0 {
+			msg = pr.Sprintf("Accounts list is empty, write `/accountadd Cash USD 100` to added account")
+		}
+		for _, a := range accounts {
+			inlineKeyboardRow.Add(a.Title, accountsPrefix+"id_"+strconv.Itoa(a.Id))
+		}
+		inlineKeyboardRow2 := client.NewKeyboardRow()
+		inlineKeyboardRow2.Add(pr.Sprintf("<< Back"), accountsPrefix+"home")
+		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow, inlineKeyboardRow2)
+		err = s.client.SendCallbackQuery(inlineKeyboardRows, msg, messageId, chatId)
+		if err != nil {
+			return errors.Wrap(err, "send callback query")
+		}
+	case strings.Index(data, "id_") == 0:
+		accountId, errConv := strconv.Atoi(data[len("id_"):])
+		if errConv != nil {
+			return errors.Wrap(errConv, "account id convert")
+		}
+		a, errGet := s.reposAcc.GetById(ctx, uState.Id, accountId)
+		if errGet != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Account not found: %s", errGet.Error()), chatId)
+			return errors.Wrap(errGet, "account not found")
+		}
+		inlineKeyboardRow.Add(pr.Sprintf("Delete"), accountsPrefix+"del_"+strconv.Itoa(a.Id))
+		inlineKeyboardRow.Add(pr.Sprintf("<< Back"), accountsPrefix+"list")
+		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
+		err = s.client.SendCallbackQuery(inlineKeyboardRows, pr.Sprintf(
+			"Account *%s* (*%s*)", a.Title, a.Currency.Abbr), messageId, chatId)
+	}
+
+	return
+}
+
+func (s *Service) Transfer(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	if !s.rates.IsLoaded(ctx) {
+		_ = s.client.SendMessage(pr.Sprintf("Rates not loaded, please repeat later"), update.Message.Chat.ID)
+		return errors.New("rates still not loaded")
+	}
+
+	priceArg := update.Message.CommandArguments()
+	price, err := strconv.ParseFloat(priceArg, 64)
+	if err != nil {
+		_ = s.client.SendMessage(pr.Sprintf(
+			"Error convert price '*%s*'", priceArg), update.Message.Chat.ID)
+		return errors.Wrap(err, "convert price")
+	}
+	if price <= 0 {
+		_ = s.client.SendMessage(pr.Sprintf("Please set price over 0"), update.Message.Chat.ID)
+		return errors.New("Price less than 0")
+	}
+
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+	uCurrency, err := uState.GetCurrency(ctx)
+	if err != nil {
+		return errors.Wrap(err, "currency not found")
+	}
+
+	accounts, err := s.reposAcc.Accounts(ctx, uState.Id)
+	if err != nil {
+		return errors.Wrap(err, "transfer accounts")
+	}
+	if len(accounts) < 2 {
+		_ = s.client.SendMessage(pr.Sprintf("Transfer needs at least two accounts, please add /accounts"),
+			update.Message.Chat.ID)
+		return errors.New("not enough accounts")
+	}
+
+	inlineKeyboardRows := transferKeyboard(accounts, price, 0)
+	err = s.client.SendInlineKeyboard(inlineKeyboardRows,
+		pr.Sprintf("Transfer from (*%.2f %s*):", price, uCurrency.Abbr), update.Message.Chat.ID)
+	if err != nil {
+		return err
+	}
+
+	return
+}
+
+func (s *Service) TransferQuery(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	if !s.rates.IsLoaded(ctx) {
+		_ = s.client.SendMessage(pr.Sprintf("Rates not loaded, please repeat later"),
+			update.CallbackQuery.Message.Chat.ID)
+		return errors.New("rates still not loaded")
+	}
+
+	var inlineKeyboardRows []*client.KeyboardRow
+	chatId := update.CallbackQuery.Message.Chat.ID
+	messageId := update.CallbackQuery.Message.MessageID
+
+	args := strings.Split(update.CallbackQuery.Data[len(transferPrefix):], "_")
+	if len(args) != 3 {
+		return errors.New("transfer callback data")
+	}
+	price, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return errors.Wrap(err, "transfer price")
+	}
+	fromId, err := strconv.Atoi(args[1])
+	if err != nil {
+		return errors.Wrap(err, "transfer from account")
+	}
+	toId, err := strconv.Atoi(args[2])
+	if err != nil {
+		return errors.Wrap(err, "transfer to account")
+	}
+
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+	uCurrency, err := uState.GetCurrency(ctx)
+	if err != nil {
+		return errors.Wrap(err, "currency not found")
+	}
+
+	from, err := s.reposAcc.GetById(ctx, uState.Id, fromId)
+	if err != nil {
+		_ = s.client.SendMessage(pr.Sprintf("Account not found: %s", err.Error()), chatId)
+		return errors.Wrap(err, "transfer from account")
+	}
+
+	if toId > 0 {
+		to, err := s.reposAcc.GetById(ctx, uState.Id, toId)
+		if err != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Account not found: %s", err.Error()), chatId)
+			return errors.Wrap(err, "transfer to account")
+		}
+		amount, err := s.ConvertPrice(ctx, decimal.ToDecimal(price))
+		if err != nil {
+			return errors.Wrap(err, "transfer convert price")
+		}
+		_, err = s.reposAcc.AddTransfer(ctx, from.Id, to.Id, time.Now().In(uState.GetLocation(ctx)), amount)
+		if err != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Error add transfer: %s", err.Error()), chatId)
+			return errors.Wrap(err, "add transfer")
+		}
+		err = s.client.SendCallbackQuery(inlineKeyboardRows, pr.Sprintf(
+			"Transfer *%.2f %s* from *%s* to *%s* success added\r\n"+
+				"Show /balance", price, uCurrency.Abbr, from.Title, to.Title), messageId, chatId)
+
+		return err
+	}
+
+	accounts, err := s.reposAcc.Accounts(ctx, uState.Id)
+	if err != nil {
+		return errors.Wrap(err, "transfer accounts")
+	}
+	inlineKeyboardRows = transferKeyboard(accounts, price, from.Id)
+	err = s.client.SendCallbackQuery(inlineKeyboardRows, pr.Sprintf(
+		"Transfer to (*%.2f %s* > *%s*):", price, uCurrency.Abbr, from.Title), messageId, chatId)
+
+	return
+}
+
+// transferKeyboard shows source accounts when fromId is empty, otherwise destination accounts
+func transferKeyboard(accounts []model.Account, price float64, fromId int) []*client.KeyboardRow {
+	priceSer := strconv.FormatFloat(price, 'f', 2, 64)
+	inlineKeyboardRow := client.NewKeyboardRow()
+	for _, a := range accounts {
+		if a.Id == fromId {
+			continue
+		}
+		if fromId == 0 {
+			inlineKeyboardRow.Add(a.Title, transferPrefix+strings.Join([]string{
+				priceSer, strconv.Itoa(a.Id), "0"}, "_"))
+		} else {
+			inlineKeyboardRow.Add(a.Title, transferPrefix+strings.Join([]string{
+				priceSer, strconv.Itoa(fromId), strconv.Itoa(a.Id)}, "_"))
+		}
+	}
+
+	return []*client.KeyboardRow{inlineKeyboardRow}
+}
+
+func (s *Service) Balance(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	if !s.rates.IsLoaded(ctx) {
+		_ = s.client.SendMessage(pr.Sprintf("Rates not loaded, please repeat later"), update.Message.Chat.ID)
+		return errors.New("rates still not loaded")
+	}
+
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+	uCurrency, err := uState.GetCurrency(ctx)
+	if err != nil {
+		return errors.Wrap(err, "currency not found")
+	}
+	userRate, err := s.GetRateUserFloat(ctx)
+	if err != nil {
+		return errors.Wrap(err, "balance user rate")
+	}
+
+	accounts, err := s.reposAcc.Accounts(ctx, uState.Id)
+	if err != nil {
+		return errors.Wrap(err, "balance accounts")
+	}
+	// shared ledger of group shows debts of members after accounts
+	debtsMsg, err := s.debtsMessage(ctx, uState.Id, userRate, uCurrency)
+	if err != nil {
+		return errors.Wrap(err, "balance debts")
+	}
+	if len(accounts) == 0 && debtsMsg == "" {
+		err = s.client.SendMessage(pr.Sprintf("Accounts list is empty, please add /accounts"), update.Message.Chat.ID)
+		return
+	}
+	balances, err := s.reposAcc.Balances(ctx, uState.Id)
+	if err != nil {
+		return errors.Wrap(err, "balances")
+	}
+
+	msg := ""
+	if len(accounts) > 0 {
+		msg = pr.Sprintf("Balance:") + "\n"
+		total := decimal.Decimal(0)
+		for _, a := range accounts {
+			balance := balances[a.Id]
+			total += balance
+			accRate, ok := s.rates.GetRate(ctx, a.Currency)
+			if !ok {
+				msg += pr.Sprintf("_%s_ - %.2f %s\n", a.Title, balance.Divide(userRate), uCurrency.Abbr)
+				continue
+			}
+			msg += pr.Sprintf("_%s_ - %.2f %s\n", a.Title, balance.Divide(accRate.Rate), a.Currency.Abbr)
+		}
+		msg += pr.Sprintf("Total: *%.2f %s*", total.Divide(userRate), uCurrency.Abbr)
+	}
+	if debtsMsg != "" {
+		if msg != "" {
+			msg += "\n\n"
+		}
+		msg += debtsMsg
+	}
+
+	err = s.client.SendMessage(msg, update.Message.Chat.ID)
+
+	return
+}
@@ -0,0 +1,336 @@
+package spending
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/category"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/state"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/broadcast"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/pagination"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/schedule"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EventSpend is event with price in currency of state
+type EventSpend struct {
+	model.Event
+	Amount decimal.Decimal
+}
+
+// Settings of state changed by commands /currency, /weekstart, /monthstart, /timezone and /thresholds
+type Settings struct {
+	Currency   model.Currency
+	Start      period.Start
+	Timezone   string
+	Thresholds []int
+}
+
+// SettingsUpdate changes settings which are not nil
+type SettingsUpdate struct {
+	Currency   *string
+	WeekStart  *string
+	MonthStart *int
+	Timezone   *string
+	Thresholds []int
+}
+
+var InvalidSettingsError = errors.New("invalid settings")
+
+// StateEvents returns page of events of state by filter with prices in currency of state and number of all events
+func (s *Service) StateEvents(ctx context.Context, filter model.EventFilter,
+	page pagination.Page) (es []EventSpend, curr model.Currency, total int, err error) {
+	_, rate, curr, err := s.stateRate(ctx, filter.StateId)
+	if err != nil {
+		return
+	}
+
+	events, total, err := s.reposSpend.Events(ctx, filter, page.Offset, page.Limit)
+	if err != nil {
+		return nil, curr, 0, errors.Wrap(err, "state events")
+	}
+	for _, e := range events {
+		es = append(es, EventSpend{Event: e, Amount: decimal.Decimal(e.Price).Divide(rate)})
+	}
+
+	return
+}
+
+// StateAddEvent adds event without member to ledger of state, price is in currency of state,
+// account is optional and must be account of state
+func (s *Service) StateAddEvent(ctx context.Context, stateId, categoryId, accountId int, date time.Time,
+	price decimal.Decimal) (EventSpend, model.Currency, error) {
+	if accountId > 0 {
+		if _, err := s.reposAcc.GetById(ctx, stateId, accountId); err != nil {
+			return EventSpend{}, model.Currency{}, err
+		}
+	}
+	_, rate, curr, err := s.stateRate(ctx, stateId)
+	if err != nil {
+		return EventSpend{}, curr, err
+	}
+
+	eventId, err := s.reposSpend.AddEvent(ctx, stateId, 0, categoryId, accountId, date, price.Multiply(rate))
+	if err != nil {
+		return EventSpend{}, curr, errors.Wrap(err, "add event")
+	}
+
+	e, curr, err := s.stateEvent(ctx, stateId, eventId, rate, curr)
+	if err != nil {
+		return e, curr, err
+	}
+	s.webhooks.Notify(stateId, model.WebhookSpendingAdded, WebhookSpending{
+		EventId:       e.Id,
+		CategoryId:    e.Category.Id,
+		CategoryTitle: e.Category.Title,
+		Price:         e.Amount.Float64(),
+		Currency:      curr.Abbr,
+		Date:          e.Date.Format("2006-01-02"),
+		AccountId:     e.AccountId,
+	})
+
+	return e, curr, nil
+}
+
+// StateUpdateEvent changes category, date and price of event of state, price is in currency of state
+func (s *Service) StateUpdateEvent(ctx context.Context, stateId, id, categoryId int, date time.Time,
+	price decimal.Decimal) (EventSpend, model.Currency, error) {
+	_, rate, curr, err := s.stateRate(ctx, stateId)
+	if err != nil {
+		return EventSpend{}, curr, err
+	}
+	if _, err = s.reposCat.CategoryGetById(ctx, categoryId); err != nil {
+		return EventSpend{}, curr, err
+	}
+
+	if err = s.reposSpend.UpdateEvent(ctx, stateId, id, categoryId, date, price.Multiply(rate)); err != nil {
+		return EventSpend{}, curr, err
+	}
+
+	return s.stateEvent(ctx, stateId, id, rate, curr)
+}
+
+func (s *Service) StateDeleteEvent(ctx context.Context, stateId, id int) error {
+	if _, err := s.reposSpend.GetEvent(ctx, stateId, id); err != nil {
+		return err
+	}
+
+	return s.reposSpend.DeleteEvent(ctx, id)
+}
+
+// WatchEvents subscribes to changes of events of states after cursor
+func (s *Service) WatchEvents(stateIds []int, cursor string) (*broadcast.Subscription[model.EventChange], error) {
+	return s.reposSpend.Watch(cursor, stateIds)
+}
+
+// EventsCursor returns cursor to resume watching after change with seq
+func (s *Service) EventsCursor(seq uint64) string {
+	return s.reposSpend.Cursor(seq)
+}
+
+// StateEventSpend returns event with price in currency of state
+func (s *Service) StateEventSpend(ctx context.Context, stateId int, e model.Event) (EventSpend, model.Currency,
+	error) {
+	_, rate, curr, err := s.stateRate(ctx, stateId)
+	if err != nil {
+		return EventSpend{}, curr, err
+	}
+
+	return EventSpend{Event: e, Amount: decimal.Decimal(e.Price).Divide(rate)}, curr, nil
+}
+
+func (s *Service) stateEvent(ctx context.Context, stateId, id int, rate decimal.Decimal,
+	curr model.Currency) (EventSpend, model.Currency, error) {
+	e, err := s.reposSpend.GetEvent(ctx, stateId, id)
+	if err != nil {
+		return EventSpend{}, curr, err
+	}
+
+	return EventSpend{Event: e, Amount: decimal.Decimal(e.Price).Divide(rate)}, curr, nil
+}
+
+// stateRate returns state with rate of its currency
+func (s *Service) stateRate(ctx context.Context, stateId int) (st *state.State, rate decimal.Decimal,
+	curr model.Currency, err error) {
+	st, err = s.reposState.GetById(ctx, stateId)
+	if err != nil {
+		return nil, 0, curr, errors.Wrap(err, "state not found")
+	}
+	curr, err = st.GetCurrency(ctx)
+	if err != nil {
+		return nil, 0, curr, errors.Wrap(err, "currency not found")
+	}
+	r, ok := s.rates.GetRate(ctx, curr)
+	if !ok {
+		return nil, 0, curr, errors.New("rate not found")
+	}
+
+	return st, r.Rate, curr, nil
+}
+
+// CategoriesPage returns page of categories by part of title sorted by title and number of found categories
+func (s *Service) CategoriesPage(ctx context.Context, title string, page pagination.Page) ([]model.Category,
+	int, error) {
+	categories, err := s.reposCat.Categories(ctx)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "categories")
+	}
+
+	found := make([]model.Category, 0, len(categories))
+	for _, c := range categories {
+		if strings.Contains(strings.ToLower(c.Title), strings.ToLower(title)) {
+			found = append(found, c)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].Title < found[j].Title
+	})
+	from, to := page.Slice(len(found))
+
+	return found[from:to], len(found), nil
+}
+
+func (s *Service) AddCategory(ctx context.Context, title string) (*model.Category, error) {
+	if _, err := s.reposCat.CategoryGetByTitle(ctx, title); err == nil {
+		return nil, category.ExistsError
+	}
+	if _, err := s.reposCat.AddCategory(ctx, title); err != nil {
+		return nil, err
+	}
+
+	return s.reposCat.CategoryGetByTitle(ctx, title)
+}
+
+func (s *Service) UpdateCategory(ctx context.Context, id int, title string) (*model.Category, error) {
+	if err := s.reposCat.UpdateCategory(ctx, id, title); err != nil {
+		return nil, err
+	}
+
+	return s.reposCat.CategoryGetById(ctx, id)
+}
+
+// DeleteCategory deletes category with its events, category having events of states
+// other than stateIds is not deleted
+func (s *Service) DeleteCategory(ctx context.Context, id int, stateIds []int) error {
+	if _, err := s.reposCat.CategoryGetById(ctx, id); err != nil {
+		return err
+	}
+	states, err := s.reposCat.CategoryStates(ctx, id)
+	if err != nil {
+		return err
+	}
+	own := make(map[int]bool, len(stateIds))
+	for _, st := range stateIds {
+		own[st] = true
+	}
+	for _, st := range states {
+		if !own[st] {
+			return category.InUseError
+		}
+	}
+
+	return s.reposCat.DeleteCategory(ctx, id)
+}
+
+// StateUpdateLimit changes amount and period of limit of state, limit is in currency of state
+func (s *Service) StateUpdateLimit(ctx context.Context, stateId, limitId int, limit decimal.Decimal,
+	r period.Range) error {
+	st, err := s.reposState.GetById(ctx, stateId)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+	l, err := stateLimit(ctx, st, limitId)
+	if err != nil {
+		return err
+	}
+
+	return s.StateSetLimit(ctx, stateId, l.Category.Id, limit, r)
+}
+
+// StateSettings returns settings of state
+func (s *Service) StateSettings(ctx context.Context, stateId int) (Settings, error) {
+	st, err := s.reposState.GetById(ctx, stateId)
+	if err != nil {
+		return Settings{}, errors.Wrap(err, "state not found")
+	}
+
+	return stateSettings(ctx, st)
+}
+
+// StateUpdateSettings changes settings of state, digests are moved to new periods and time zone
+func (s *Service) StateUpdateSettings(ctx context.Context, stateId int, update SettingsUpdate) (Settings, error) {
+	st, err := s.reposState.GetById(ctx, stateId)
+	if err != nil {
+		return Settings{}, errors.Wrap(err, "state not found")
+	}
+
+	if update.Currency != nil {
+		curr, err := s.reposCurr.GetByAbbr(ctx, strings.ToUpper(*update.Currency))
+		if err != nil {
+			return Settings{}, errors.Wrap(InvalidSettingsError, "unknown currency")
+		}
+		if err = st.SetCurrency(ctx, curr); err != nil {
+			return Settings{}, err
+		}
+	}
+
+	start := st.GetStart(ctx)
+	if update.WeekStart != nil {
+		weekday, ok := schedule.ParseWeekday(*update.WeekStart)
+		if !ok {
+			return Settings{}, errors.Wrap(InvalidSettingsError, "unknown weekday")
+		}
+		start.Weekday = weekday
+	}
+	if update.MonthStart != nil {
+		start.MonthDay = *update.MonthStart
+	}
+	if err = start.Validate(); err != nil {
+		return Settings{}, errors.Wrap(InvalidSettingsError, err.Error())
+	}
+	if start != st.GetStart(ctx) {
+		if err = s.setStart(ctx, st, start); err != nil {
+			return Settings{}, errors.Wrap(err, "set start")
+		}
+	}
+
+	if update.Timezone != nil {
+		if err = st.SetTimezone(ctx, *update.Timezone); err != nil {
+			return Settings{}, errors.Wrap(InvalidSettingsError, "unknown time zone")
+		}
+		if err = s.rescheduleDigests(ctx, st); err != nil {
+			return Settings{}, errors.Wrap(err, "timezone reschedule digests")
+		}
+	}
+
+	if update.Thresholds != nil {
+		for _, threshold := range update.Thresholds {
+			if threshold < 1 || threshold > maxThreshold {
+				return Settings{}, errors.Wrap(InvalidSettingsError, "threshold out of range")
+			}
+		}
+		if err = st.SetThresholds(ctx, update.Thresholds); err != nil {
+			return Settings{}, err
+		}
+	}
+
+	return stateSettings(ctx, st)
+}
+
+func stateSettings(ctx context.Context, st *state.State) (Settings, error) {
+	curr, err := st.GetCurrency(ctx)
+	if err != nil {
+		return Settings{}, errors.Wrap(err, "currency not found")
+	}
+
+	return Settings{
+		Currency:   curr,
+		Start:      st.GetStart(ctx),
+		Timezone:   st.GetLocation(ctx).String(),
+		Thresholds: st.GetThresholds(ctx),
+	}, nil
+}
@@ -0,0 +1,87 @@
+//go:build integration
+// +build integration
+
+package spending
+
+import (
+	"context"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/account"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/category"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// stateAccounts has accounts by id in their states
+type stateAccounts struct {
+	account.Client
+	states map[int]int
+}
+
+func (a stateAccounts) GetById(_ context.Context, stateId, id int) (*model.Account, error) {
+	if st, ok := a.states[id]; !ok || st != stateId {
+		return nil, account.NotFoundError
+	}
+
+	return &model.Account{Id: id, StateId: stateId}, nil
+}
+
+// addedEvents fails test when event is added
+type addedEvents struct {
+	repository.Spending
+	t *testing.T
+}
+
+func (e addedEvents) AddEvent(context.Context, int, int, int, int, time.Time, decimal.Decimal) (int, error) {
+	e.t.Error("event must not be added")
+	return 0, nil
+}
+
+func TestService_StateAddEvent(t *testing.T) {
+	s := &Service{
+		reposAcc:   stateAccounts{states: map[int]int{3: 7}},
+		reposSpend: addedEvents{t: t},
+	}
+
+	_, _, err := s.StateAddEvent(context.Background(), 5, 1, 3, time.Now(), decimal.ToDecimal(10))
+	assert.ErrorIs(t, err, account.NotFoundError)
+}
+
+// categoriesRepos is embedded under other name than its method Categories
+type categoriesRepos repository.Categories
+
+// categoryEvents has states with events of categories
+type categoryEvents struct {
+	categoriesRepos
+	states  map[int][]int
+	deleted []int
+}
+
+func (c *categoryEvents) CategoryGetById(_ context.Context, id int) (*model.Category, error) {
+	return &model.Category{Id: id}, nil
+}
+
+func (c *categoryEvents) CategoryStates(_ context.Context, id int) ([]int, error) {
+	return c.states[id], nil
+}
+
+func (c *categoryEvents) DeleteCategory(_ context.Context, id int) error {
+	c.deleted = append(c.deleted, id)
+	return nil
+}
+
+func TestService_DeleteCategory(t *testing.T) {
+	cats := &categoryEvents{states: map[int][]int{1: {5}, 2: {5, 7}, 3: {0}}}
+	s := &Service{reposCat: cats}
+	ctx := context.Background()
+
+	assert.NoError(t, s.DeleteCategory(ctx, 1, []int{5}))
+	assert.ErrorIs(t, s.DeleteCategory(ctx, 2, []int{5}), category.InUseError)
+	// events without state belong to nobody
+	assert.ErrorIs(t, s.DeleteCategory(ctx, 3, []int{5}), category.InUseError)
+	assert.NoError(t, s.DeleteCategory(ctx, 4, []int{5}))
+	assert.Equal(t, []int{1, 4}, cats.deleted)
+}
@@ -0,0 +1,313 @@
+package spending
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/budget"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/state"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const planPrefix = "plan_"
+
+// Budget shows budget of current month or sets it by `/budget 50000 rollover`
+func (s *Service) Budget(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+	b, err := s.monthBudget(ctx, uState, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "month budget")
+	}
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) > 0 {
+		total, errParse := strconv.ParseFloat(args[0], 64)
+		if errParse != nil || total < 0 {
+			_ = s.client.SendMessage(pr.Sprintf(
+				"Error convert budget '*%s*', example `/budget 50000 rollover`", args[0]), update.Message.Chat.ID)
+			return errors.New("convert budget")
+		}
+		rollover := b.Rollover
+		if len(args) > 1 {
+			switch strings.ToLower(args[1]) {
+			case "rollover":
+				rollover = true
+			case "norollover":
+				rollover = false
+			default:
+				_ = s.client.SendMessage(pr.Sprintf(
+					"Unknown option '*%s*', use `rollover` or `norollover`", args[1]), update.Message.Chat.ID)
+				return errors.New("budget option")
+			}
+		}
+		totalBase, errConv := s.ConvertPrice(ctx, decimal.ToDecimal(total))
+		if errConv != nil {
+			return errors.Wrap(errConv, "budget convert price")
+		}
+		if err = s.reposBudget.SetTotal(ctx, b.Id, totalBase, rollover); err != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Budget not set: %s", err.Error()), update.Message.Chat.ID)
+			return errors.Wrap(err, "set budget total")
+		}
+		b.Total, b.Rollover = totalBase.Original(), rollover
+	}
+
+	uCurrency, err := uState.GetCurrency(ctx)
+	if err != nil {
+		return errors.Wrap(err, "currency not found")
+	}
+	rate, err := s.GetRateUserFloat(ctx)
+	if err != nil {
+		return errors.Wrap(err, "budget rate")
+	}
+	spent, err := s.monthSpent(ctx, uState.Id, uCurrency, uState.GetStart(ctx), b.Month)
+	if err != nil {
+		return errors.Wrap(err, "budget spent")
+	}
+	available := decimal.Decimal(b.Total + b.Carried).Divide(rate)
+	rolloverMess := pr.Sprintf("off")
+	if b.Rollover {
+		rolloverMess = pr.Sprintf("on")
+	}
+
+	return s.client.SendMessage(pr.Sprintf("Budget for *%s*: *%.2f %s*, carried *%.2f %s*, rollover %s\r\n"+
+		"Spent *%.2f %s*, left *%.2f %s*\r\n"+
+		"Show /plan, change by `/budget 50000 rollover`",
+		pr.MonthYear(b.Month), decimal.Decimal(b.Total).Divide(rate), uCurrency.Abbr,
+		decimal.Decimal(b.Carried).Divide(rate), uCurrency.Abbr, rolloverMess,
+		spent, uCurrency.Abbr, available-spent, uCurrency.Abbr), update.Message.Chat.ID)
+}
+
+// Plan shows planned, actual and difference by categories or assigns plan by `/plan 5000`
+func (s *Service) Plan(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	priceArg := update.Message.CommandArguments()
+	if priceArg == "" {
+		msg, err := s.planView(ctx)
+		if err != nil {
+			return errors.Wrap(err, "plan view")
+		}
+		return s.client.SendMessage(msg, update.Message.Chat.ID)
+	}
+
+	price, err := strconv.ParseFloat(priceArg, 64)
+	if err != nil || price < 0 {
+		_ = s.client.SendMessage(pr.Sprintf(
+			"Error convert plan '*%s*', example `/plan 5000`", priceArg), update.Message.Chat.ID)
+		return errors.New("convert plan")
+	}
+
+	categories, err := s.reposCat.Categories(ctx)
+	if err != nil {
+		return errors.Wrap(err, "plan categories")
+	}
+	if len(categories) == 0 {
+		_ = s.client.SendMessage(pr.Sprintf("Categories list is empty, please add /categories"),
+			update.Message.Chat.ID)
+		return errors.New("Categories list is empty")
+	}
+
+	inlineKeyboardRow := client.NewKeyboardRow()
+	for _, c := range categories {
+		inlineKeyboardRow.Add(c.Title, planPrefix+strconv.FormatFloat(price, 'f', 2, 64)+"_"+strconv.Itoa(c.Id))
+	}
+
+	return s.client.SendInlineKeyboard([]*client.KeyboardRow{inlineKeyboardRow},
+		pr.Sprintf("Choose category to plan *%.2f*, zero removes plan:", price), update.Message.Chat.ID)
+}
+
+func (s *Service) PlanQuery(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	chatId := update.CallbackQuery.Message.Chat.ID
+	messageId := update.CallbackQuery.Message.MessageID
+
+	args := strings.Split(update.CallbackQuery.Data[len(planPrefix):], "_")
+	if len(args) != 2 {
+		return errors.New("plan callback data")
+	}
+	price, errParse := strconv.ParseFloat(args[0], 64)
+	categoryId, errConv := strconv.Atoi(args[1])
+	if errParse != nil || errConv != nil {
+		return errors.New("plan callback data")
+	}
+
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+	b, err := s.monthBudget(ctx, uState, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "month budget")
+	}
+	planned, err := s.ConvertPrice(ctx, decimal.ToDecimal(price))
+	if err != nil {
+		return errors.Wrap(err, "plan convert price")
+	}
+	if err = s.reposBudget.SetPlan(ctx, b.Id, categoryId, planned); err != nil {
+		_ = s.client.SendMessage(pr.Sprintf("Plan not set: %s", err.Error()), chatId)
+		return errors.Wrap(err, "set plan")
+	}
+
+	msg, err := s.planView(ctx)
+	if err != nil {
+		return errors.Wrap(err, "plan view")
+	}
+
+	return s.client.SendCallbackQuery(nil, msg, messageId, chatId)
+}
+
+func (s *Service) planView(ctx context.Context) (msg string, err error) {
+	pr := i18n.FromContext(ctx)
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "state not found")
+	}
+	uCurrency, err := uState.GetCurrency(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "currency not found")
+	}
+	rate, err := s.GetRateUserFloat(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "plan rate")
+	}
+	b, err := s.monthBudget(ctx, uState, time.Now())
+	if err != nil {
+		return "", errors.Wrap(err, "month budget")
+	}
+
+	f1, f2 := uState.GetStart(ctx).Month(b.Month)
+	actual, err := s.reposSpend.Report(ctx, uState.Id, f1, f2, s.rates, uCurrency)
+	if err != nil {
+		return "", errors.Wrap(err, "plan report")
+	}
+	categories, err := s.reposCat.Categories(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "plan categories")
+	}
+	planned := make(map[int]decimal.Decimal)
+	for _, p := range b.Plans {
+		planned[p.Category.Id] = decimal.Decimal(p.Planned).Divide(rate)
+	}
+
+	msg = pr.Sprintf("Plan for *%s* (%s):\n", pr.MonthYear(b.Month), uCurrency.Abbr)
+	var totalPlanned, totalActual decimal.Decimal
+	for _, c := range categories {
+		p, okPlan := planned[c.Id]
+		a, okActual := actual[c.Id]
+		if !okPlan && !okActual {
+			continue
+		}
+		msg += pr.Sprintf("_%s_ - plan %.2f, actual %.2f, diff %s\n", c.Title, p, a, signed(pr, p-a))
+		totalPlanned += p
+		totalActual += a
+	}
+	if totalPlanned == 0 && totalActual == 0 {
+		msg += pr.Sprintf("nothing planned yet, write `/plan 5000` to plan category\n")
+	} else {
+		msg += pr.Sprintf("*Total* - plan %.2f, actual %.2f, diff %s\n",
+			totalPlanned, totalActual, signed(pr, totalPlanned-totalActual))
+	}
+
+	available := decimal.Decimal(b.Total + b.Carried).Divide(rate)
+	msg += pr.Sprintf("Budget *%.2f %s* (carried %.2f), unallocated *%.2f %s*\nChange by /budget",
+		available, uCurrency.Abbr, decimal.Decimal(b.Carried).Divide(rate), available-totalPlanned, uCurrency.Abbr)
+
+	return
+}
+
+// monthBudget returns budget of month with t beginning on month start of state, new month takes total,
+// rollover option and plans of previous month, unused money of previous month is carried when rollover is on
+func (s *Service) monthBudget(ctx context.Context, st *state.State, t time.Time) (*model.Budget, error) {
+	start := st.GetStart(ctx)
+	month, _ := start.Month(t.In(st.GetLocation(ctx)))
+	b, err := s.reposBudget.GetBudget(ctx, st.Id, month)
+	if err == nil {
+		// date of month is read without location
+		b.Month = month
+		return b, nil
+	}
+	if !errors.Is(err, budget.NotFoundError) {
+		return nil, errors.Wrap(err, "get budget")
+	}
+
+	next := model.Budget{StateId: st.Id, Month: month}
+	prevMonth := month.AddDate(0, -1, 0)
+	prev, err := s.reposBudget.GetBudget(ctx, st.Id, prevMonth)
+	if err != nil && !errors.Is(err, budget.NotFoundError) {
+		return nil, errors.Wrap(err, "get previous budget")
+	}
+	if prev != nil {
+		next.Total, next.Rollover, next.Plans = prev.Total, prev.Rollover, prev.Plans
+		if prev.Rollover {
+			curr, err := st.GetCurrency(ctx)
+			if err != nil {
+				return nil, errors.Wrap(err, "currency not found")
+			}
+			rate, ok := s.rates.GetRate(ctx, curr)
+			if !ok {
+				return nil, errors.New("rate not found")
+			}
+			spent, err := s.monthSpent(ctx, st.Id, curr, start, prevMonth)
+			if err != nil {
+				return nil, errors.Wrap(err, "previous budget spent")
+			}
+			if left := decimal.Decimal(prev.Total+prev.Carried) - spent.Multiply(rate.Rate); left > 0 {
+				next.Carried = left.Original()
+			}
+		}
+	}
+
+	b, err = s.reposBudget.CreateBudget(ctx, next)
+	if err != nil {
+		return nil, errors.Wrap(err, "create budget")
+	}
+	b.Month = month
+
+	return b, nil
+}
+
+// monthSpent returns sum of spending of state in month with t beginning on start in currency curr
+func (s *Service) monthSpent(ctx context.Context, stateId int, curr model.Currency, start period.Start,
+	t time.Time) (spent decimal.Decimal, err error) {
+	f1, f2 := start.Month(t)
+	m, err := s.reposSpend.Report(ctx, stateId, f1, f2, s.rates, curr)
+	if err != nil {
+		return 0, errors.Wrap(err, "month report")
+	}
+	for _, sum := range m {
+		spent += sum
+	}
+
+	return
+}
+
+func signed(pr *i18n.Printer, d decimal.Decimal) string {
+	if d > 0 {
+		return pr.Sprintf("+%.2f", d)
+	}
+
+	return pr.Sprintf("%.2f", d)
+}
@@ -2,87 +2,109 @@ package spending
 
 import (
 	"context"
-	"fmt"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 	"github.com/pkg/errors"
-	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
-	"strconv"
+	"github.com/sku4/ozon-route256-spending-bot/internal/service/dialog"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"strings"
 )
 
 //go:generate mockgen -source=categories.go -destination=mocks/categories.go
 
-func (s *Service) Categories(ctx context.Context, update tgbotapi.Update) (err error) {
-	_ = ctx
+const categoriesPrefix = "categories_"
 
-	var inlineKeyboardRows []*client.KeyboardRow
-	inlineKeyboardRow := client.NewKeyboardRow()
-	inlineKeyboardRow.Add("Add", "categories_add")
-	inlineKeyboardRow.Add("List", "categories_list")
-	inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
+// categoriesDialog is category added by title written in /categories
+type categoriesDialog struct {
+	Title string `json:"t"`
+}
+
+func (s *Service) categoriesFlow() *dialog.Flow[categoriesDialog] {
+	return &dialog.Flow[categoriesDialog]{
+		Command: "categories",
+		Prefix:  categoriesPrefix,
+		Start: func(ctx context.Context, update tgbotapi.Update) (string, categoriesDialog, error) {
+			return "home", categoriesDialog{}, nil
+		},
+		States: map[string]dialog.State[categoriesDialog]{
+			"home": {Prompt: func(ctx context.Context, c categoriesDialog) (dialog.Prompt[categoriesDialog], error) {
+				pr := i18n.FromContext(ctx)
+				return dialog.Prompt[categoriesDialog]{
+					Text: pr.Sprintf("Choose categories command:"),
+					Rows: [][]dialog.Button[categoriesDialog]{{
+						{Title: pr.Sprintf("Add"), Next: "add", Data: c},
+						{Title: pr.Sprintf("List"), Next: "list", Data: c},
+					}},
+				}, nil
+			}},
+			"add": {
+				Prompt: func(ctx context.Context, c categoriesDialog) (dialog.Prompt[categoriesDialog], error) {
+					return dialog.Prompt[categoriesDialog]{
+						Text: i18n.FromContext(ctx).Sprintf("Write title of category:"),
+					}, nil
+				},
+				Input: s.categoryInput,
+			},
+			"list":  {Prompt: s.categoriesList},
+			"added": {Prompt: s.categorySave, Final: true},
+		},
+	}
+}
 
-	err = s.client.SendInlineKeyboard(inlineKeyboardRows,
-		"Choose categories command:", update.Message.Chat.ID)
+func (s *Service) categoriesList(ctx context.Context, _ categoriesDialog) (p dialog.Prompt[categoriesDialog], err error) {
+	pr := i18n.FromContext(ctx)
+	categories, err := s.reposCat.Categories(ctx)
 	if err != nil {
-		return err
+		return p, dialog.Errorf("Categories: %s", err.Error())
+	}
+	if len(categories) == 0 {
+		p.Text = pr.Sprintf("Categories list is empty")
+		return p, nil
+	}
+	titles := make([]string, 0, len(categories))
+	for _, category := range categories {
+		titles = append(titles, "- "+category.Title)
 	}
+	p.Text = pr.Sprintf("Categories list:") + "\r\n" + strings.Join(titles, "\r\n")
 
-	return
+	return p, nil
+}
+
+func (s *Service) categoryInput(_ context.Context, c categoriesDialog, text string) (string, categoriesDialog, error) {
+	c.Title = strings.TrimSpace(text)
+	if c.Title == "" {
+		return "", c, dialog.Errorf("Category title is empty, please write title")
+	}
+
+	return "added", c, nil
+}
+
+func (s *Service) categorySave(ctx context.Context, c categoriesDialog) (p dialog.Prompt[categoriesDialog], err error) {
+	pr := i18n.FromContext(ctx)
+	if _, err = s.reposCat.AddCategory(ctx, c.Title); err != nil {
+		return p, dialog.Errorf("Error add category *%s*: %s", c.Title, err.Error())
+	}
+	p.Text = pr.Sprintf("Category *%s* success added\r\n"+
+		"Show /categories", c.Title)
+
+	return p, nil
 }
 
 func (s *Service) CategoryAdd(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
 	title := update.Message.CommandArguments()
 	if title == "" {
-		_ = s.client.SendMessage("Category title is empty, please set title", update.Message.Chat.ID)
+		_ = s.client.SendMessage(pr.Sprintf("Category title is empty, please set title"), update.Message.Chat.ID)
 		return errors.Wrap(err, "category title is empty")
 	}
 	_, err = s.reposCat.AddCategory(ctx, title)
 	if err != nil {
-		_ = s.client.SendMessage(fmt.Sprintf(
+		_ = s.client.SendMessage(pr.Sprintf(
 			"Error add category *%s*: %s", title, err.Error()), update.Message.Chat.ID)
 		return errors.Wrap(err, "add category")
 	}
-	err = s.client.SendMessage(fmt.Sprintf(
+	err = s.client.SendMessage(pr.Sprintf(
 		"Category *%s* success added\r\n"+
 			"Show /categories", title), update.Message.Chat.ID)
 
 	return
 }
-
-func (s *Service) CategoriesQuery(ctx context.Context, update tgbotapi.Update) (err error) {
-	var inlineKeyboardRows []*client.KeyboardRow
-	inlineKeyboardRow := client.NewKeyboardRow()
-
-	switch update.CallbackQuery.Data {
-	case "categories_home":
-		msg := "Choose categories command:"
-		inlineKeyboardRow.Add("Add", "categories_add")
-		inlineKeyboardRow.Add("List", "categories_list")
-		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
-		err = s.client.SendCallbackQuery(inlineKeyboardRows, msg,
-			update.CallbackQuery.Message.MessageID, update.CallbackQuery.Message.Chat.ID)
-	case "categories_list":
-		msg := "Categories list:"
-		categories, err := s.reposCat.Categories(ctx)
-		if err != nil {
-			_ = s.client.SendMessage(fmt.Sprintf(
-				"Categories: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
-			return errors.Wrap(err, "categories list")
-		}
-		for _, category := range categories {
-			inlineKeyboardRow.Add(category.Title, "categories_id_"+strconv.Itoa(category.Id))
-		}
-		inlineKeyboardRow2 := client.NewKeyboardRow()
-		inlineKeyboardRow2.Add("<< Back", "categories_home")
-		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow, inlineKeyboardRow2)
-		err = s.client.SendCallbackQuery(inlineKeyboardRows, msg,
-			update.CallbackQuery.Message.MessageID, update.CallbackQuery.Message.Chat.ID)
-		if err != nil {
-			return errors.Wrap(err, "send callback query")
-		}
-	case "categories_add":
-		msg := "Write `/categoryadd Food` to added category"
-		err = s.client.SendMessage(msg, update.CallbackQuery.Message.Chat.ID)
-	}
-
-	return
-}
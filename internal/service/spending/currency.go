@@ -2,88 +2,74 @@ package spending
 
 import (
 	"context"
-	"fmt"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 	"github.com/pkg/errors"
-	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
-	"github.com/sku4/ozon-route256-spending-bot/pkg/user"
-	"strconv"
+	"github.com/sku4/ozon-route256-spending-bot/internal/service/dialog"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
 )
 
 //go:generate mockgen -source=currency.go -destination=mocks/currency.go
 
 const currencyPrefix = "currency_"
 
-func (s *Service) Currency(ctx context.Context, update tgbotapi.Update) (err error) {
-	if !s.rates.IsLoaded(ctx) {
-		_ = s.client.SendMessage("Rates not loaded, please repeat later", update.Message.Chat.ID)
-		return errors.New("rates still not loaded")
-	}
+// currencyDialog is currency of state chosen by /currency
+type currencyDialog struct {
+	CurrencyId int `json:"c"`
+}
 
-	var inlineKeyboardRows []*client.KeyboardRow
-	inlineKeyboardRow := client.NewKeyboardRow()
-	for _, c := range s.reposCurr.All(ctx) {
-		inlineKeyboardRow.Add(c.Abbr, currencyPrefix+strconv.Itoa(c.Id))
+func (s *Service) currencyFlow() *dialog.Flow[currencyDialog] {
+	return &dialog.Flow[currencyDialog]{
+		Command: "currency",
+		Prefix:  currencyPrefix,
+		Start: func(ctx context.Context, update tgbotapi.Update) (string, currencyDialog, error) {
+			if !s.rates.IsLoaded(ctx) {
+				return "", currencyDialog{}, dialog.Errorf("Rates not loaded, please repeat later")
+			}
+			return "currency", currencyDialog{}, nil
+		},
+		States: map[string]dialog.State[currencyDialog]{
+			"currency": {Prompt: s.currencyChoose},
+			"save":     {Prompt: s.currencySave, Final: true},
+		},
 	}
-	inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
+}
 
-	err = s.client.SendInlineKeyboard(inlineKeyboardRows, "Change currency:", update.Message.Chat.ID)
-	if err != nil {
-		return err
+func (s *Service) currencyChoose(ctx context.Context, _ currencyDialog) (dialog.Prompt[currencyDialog], error) {
+	pr := i18n.FromContext(ctx)
+	currencies := s.reposCurr.All(ctx)
+	row := make([]dialog.Button[currencyDialog], 0, len(currencies))
+	for _, c := range currencies {
+		row = append(row, dialog.Button[currencyDialog]{
+			Title: c.Abbr,
+			Next:  "save",
+			Data:  currencyDialog{CurrencyId: c.Id},
+		})
 	}
 
-	return
+	return dialog.Prompt[currencyDialog]{
+		Text: pr.Sprintf("Change currency:"),
+		Rows: [][]dialog.Button[currencyDialog]{row},
+	}, nil
 }
 
-func (s *Service) CurrencyQuery(ctx context.Context, update tgbotapi.Update) (err error) {
+func (s *Service) currencySave(ctx context.Context, c currencyDialog) (p dialog.Prompt[currencyDialog], err error) {
+	pr := i18n.FromContext(ctx)
 	if !s.rates.IsLoaded(ctx) {
-		_ = s.client.SendMessage("Rates not loaded, please repeat later", update.Message.Chat.ID)
-		return errors.New("rates still not loaded")
+		return p, dialog.Errorf("Rates not loaded, please repeat later")
 	}
-
-	var inlineKeyboardRows []*client.KeyboardRow
-
-	postfixCur, err := strconv.Atoi(update.CallbackQuery.Data[len(currencyPrefix):])
+	userCurrency, err := s.reposCurr.GetById(ctx, c.CurrencyId)
 	if err != nil {
-		_ = s.client.SendMessage(fmt.Sprintf(
-			"Error currency not found: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
-		return errors.Wrap(err, "currency user not convert")
+		return p, dialog.Errorf("Currency not found: %s", err.Error())
 	}
-
-	userCurrency, err := s.reposCurr.GetById(ctx, postfixCur)
+	uState, _, err := s.userCurrency(ctx)
 	if err != nil {
-		_ = s.client.SendMessage(fmt.Sprintf(
-			"Currency not found: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
-		return errors.Wrap(err, "Currency not found")
+		return p, err
 	}
-	userCurrAbbr := userCurrency.Abbr
-
-	if userCurrency.Id > -1 {
-		// change currency
-		u, err := user.FromContext(ctx)
-		if err != nil {
-			_ = s.client.SendMessage(fmt.Sprintf(
-				"User not found: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
-			return errors.Wrap(err, "user not found")
-		}
-
-		uState, err := u.GetState(ctx)
-		if err != nil {
-			return err
-		}
-		err = uState.SetCurrency(ctx, userCurrency)
-		if err != nil {
-			return err
-		}
-
-		err = s.client.SendCallbackQuery(inlineKeyboardRows, fmt.Sprintf(
-			"Currency success changed to *%s*\r\n"+
-				"Show /report7 /report31 /report365", userCurrAbbr),
-			update.CallbackQuery.Message.MessageID, update.CallbackQuery.Message.Chat.ID)
-		if err != nil {
-			return err
-		}
+	if err = uState.SetCurrency(ctx, userCurrency); err != nil {
+		return p, errors.Wrap(err, "set currency")
 	}
+	p.Text = pr.Sprintf("Currency success changed to *%s*\r\n"+
+		"Show /report7 /report31 /report365", userCurrency.Abbr)
 
-	return
+	return p, nil
 }
@@ -0,0 +1,46 @@
+package spending
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/state"
+	"github.com/sku4/ozon-route256-spending-bot/internal/service/dialog"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/user"
+	"time"
+)
+
+// SessionTTL is time dialogs are kept in store of sessions
+const SessionTTL = time.Hour
+
+// registerDialogs adds multi-step flows, every flow is routed by its command and prefix of buttons
+func (s *Service) registerDialogs() {
+	dialog.Register(s.dialogs, s.spendingAddFlow())
+	dialog.Register(s.dialogs, s.limitFlow())
+	dialog.Register(s.dialogs, s.currencyFlow())
+	dialog.Register(s.dialogs, s.categoriesFlow())
+}
+
+// Dialog runs step of dialog by command, button or text of user, handled is false for other updates
+func (s *Service) Dialog(ctx context.Context, update tgbotapi.Update) (bool, error) {
+	return s.dialogs.Handle(ctx, update)
+}
+
+// userCurrency returns state of user from context and its currency
+func (s *Service) userCurrency(ctx context.Context) (*state.State, model.Currency, error) {
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return nil, model.Currency{}, errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return nil, model.Currency{}, errors.Wrap(err, "state not found")
+	}
+	uCurrency, err := uState.GetCurrency(ctx)
+	if err != nil {
+		return nil, model.Currency{}, errors.Wrap(err, "currency not found")
+	}
+
+	return uState, uCurrency, nil
+}
@@ -0,0 +1,288 @@
+package spending
+
+import (
+	"context"
+	"github.com/Shopify/sarama"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/digest"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/model/kafka"
+	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/logger"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/schedule"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/timezone"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	digestPrefix   = "digest_"
+	digestInterval = time.Minute
+	digestClock    = "20:00"
+)
+
+// digestPeriod sends digest on the last day of period with the same window as manual reports,
+// both depend on week and month start of state
+type digestPeriod struct {
+	period   period.Period
+	schedule func(period.Start) schedule.Schedule
+}
+
+var digestPeriods = map[string]digestPeriod{
+	string(schedule.Week): {
+		period: period.PeriodWeek,
+		schedule: func(start period.Start) schedule.Schedule {
+			return schedule.Schedule{Period: schedule.Week, Day: (int(start.Weekday) + 6) % 7}
+		},
+	},
+	string(schedule.Month): {
+		period: period.PeriodMonth,
+		schedule: func(start period.Start) schedule.Schedule {
+			if start.MonthDay <= 1 {
+				return schedule.Schedule{Period: schedule.Month, Day: 31}
+			}
+			return schedule.Schedule{Period: schedule.Month, Day: start.MonthDay - 1}
+		},
+	},
+	string(schedule.Year): {
+		period: period.PeriodYear,
+		schedule: func(start period.Start) schedule.Schedule {
+			if start.MonthDay <= 1 {
+				return schedule.Schedule{Period: schedule.Year, Month: time.December, Day: 31}
+			}
+			return schedule.Schedule{Period: schedule.Year, Month: time.January, Day: start.MonthDay - 1}
+		},
+	},
+}
+
+// Digest shows digest subscriptions or subscribes chat by `/digest week 20:00`
+func (s *Service) Digest(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		inlineKeyboardRows, msg, err := s.digestList(ctx)
+		if err != nil {
+			return errors.Wrap(err, "digest list")
+		}
+		return s.client.SendInlineKeyboard(inlineKeyboardRows, msg, update.Message.Chat.ID)
+	}
+
+	dp, ok := digestPeriods[strings.ToLower(args[0])]
+	if !ok {
+		_ = s.client.SendMessage(pr.Sprintf(
+			"Unknown period '*%s*', use `week`, `month` or `year`", args[0]), update.Message.Chat.ID)
+		return errors.New("digest period")
+	}
+	clockArg := digestClock
+	if len(args) > 1 {
+		clockArg = args[1]
+	}
+	clock, err := time.Parse("15:04", clockArg)
+	if err != nil {
+		_ = s.client.SendMessage(pr.Sprintf(
+			"Error time '*%s*', example `/digest week 20:00`", clockArg), update.Message.Chat.ID)
+		return errors.Wrap(err, "digest clock")
+	}
+
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+
+	minutes := clock.Hour()*60 + clock.Minute()
+	sched := dp.schedule(uState.GetStart(ctx))
+	// clock is time of day in time zone of user
+	nextAt := sched.NextAt(time.Now().In(uState.GetLocation(ctx)), time.Duration(minutes)*time.Minute)
+	_, err = s.reposDigest.AddDigest(ctx, model.Digest{
+		StateId:  uState.Id,
+		ChatId:   update.Message.Chat.ID,
+		Period:   string(sched.Period),
+		Clock:    minutes,
+		NextAt:   nextAt,
+		Language: string(pr.Lang()),
+	})
+	if err != nil {
+		_ = s.client.SendMessage(pr.Sprintf("Error add digest: %s", err.Error()), update.Message.Chat.ID)
+		return errors.Wrap(err, "add digest")
+	}
+
+	return s.client.SendMessage(pr.Sprintf("Digest %s at *%s* success subscribed, next on *%s*\r\n"+
+		"Show /digest", digestTitle(pr, sched.Period), clock.Format("15:04"), pr.Date(nextAt)),
+		update.Message.Chat.ID)
+}
+
+func (s *Service) DigestQuery(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	chatId := update.CallbackQuery.Message.Chat.ID
+	messageId := update.CallbackQuery.Message.MessageID
+
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+
+	data := update.CallbackQuery.Data[len(digestPrefix):]
+	if strings.Index(data, "del_") == 0 {
+		digestId, errConv := strconv.Atoi(data[len("del_"):])
+		if errConv != nil {
+			return errors.Wrap(errConv, "digest id convert")
+		}
+		if err = s.reposDigest.DeleteDigest(ctx, uState.Id, digestId); err != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Digest not cancelled: %s", err.Error()), chatId)
+			return errors.Wrap(err, "delete digest")
+		}
+		inlineKeyboardRows, msg, err := s.digestList(ctx)
+		if err != nil {
+			return errors.Wrap(err, "digest list")
+		}
+		return s.client.SendCallbackQuery(inlineKeyboardRows, msg, messageId, chatId)
+	}
+
+	return
+}
+
+func (s *Service) digestList(ctx context.Context) (inlineKeyboardRows []*client.KeyboardRow, msg string, err error) {
+	pr := i18n.FromContext(ctx)
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "state not found")
+	}
+
+	digests, err := s.reposDigest.Digests(ctx, uState.Id)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "digests")
+	}
+	if len(digests) == 0 {
+		return nil, pr.Sprintf("Digest list is empty, write `/digest week 20:00` to receive weekly report " +
+			"on the last day of week at 20:00, also `month` or `year`"), nil
+	}
+
+	msg = pr.Sprintf("Digest list, choose to cancel:") + "\n"
+	for i, d := range digests {
+		title := digestTitle(pr, schedule.Period(d.Period))
+		msg += pr.Sprintf("%d. _%s_ at %02d:%02d, next on %s\n", i+1, title, d.Clock/60, d.Clock%60,
+			pr.Date(d.NextAt.In(uState.GetLocation(ctx))))
+		inlineKeyboardRow := client.NewKeyboardRow()
+		inlineKeyboardRow.Add(pr.Sprintf("Cancel %d. %s", i+1, title), digestPrefix+"del_"+strconv.Itoa(d.Id))
+		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
+	}
+
+	return
+}
+
+func digestTitle(pr *i18n.Printer, p schedule.Period) string {
+	switch p {
+	case schedule.Week:
+		return pr.Sprintf("weekly")
+	case schedule.Month:
+		return pr.Sprintf("monthly")
+	case schedule.Year:
+		return pr.Sprintf("yearly")
+	}
+
+	return string(p)
+}
+
+type DigestScheduler struct {
+	reposDigest   digest.Client
+	kafkaProducer sarama.AsyncProducer
+}
+
+func NewDigestScheduler(reposDigest digest.Client, kafkaProducer sarama.AsyncProducer) *DigestScheduler {
+	return &DigestScheduler{
+		reposDigest:   reposDigest,
+		kafkaProducer: kafkaProducer,
+	}
+}
+
+// RunDigest publishes due digests as report requests until context is done
+func (d *DigestScheduler) RunDigest(ctx context.Context) {
+	ticker := time.NewTicker(digestInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.publishDigests(ctx); err != nil {
+			logger.Infos("publish digests error:", err.Error())
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *DigestScheduler) publishDigests(ctx context.Context) error {
+	now := time.Now()
+	digests, err := d.reposDigest.Due(ctx, now)
+	if err != nil {
+		return errors.Wrap(err, "due digests")
+	}
+
+	for _, dg := range digests {
+		dp, ok := digestPeriods[dg.Period]
+		if !ok {
+			logger.Infos("unknown digest period:", dg.Period)
+			continue
+		}
+
+		// missed digests are not repeated, next one is planned after now
+		start := period.Start{Weekday: time.Weekday(dg.WeekStart), MonthDay: dg.MonthStart}
+		loc, errLoc := timezone.Load(dg.Timezone)
+		if errLoc != nil {
+			loc = time.Local
+		}
+		sentAt := dg.NextAt.In(loc)
+		next := dp.schedule(start).NextAt(now.In(loc), time.Duration(dg.Clock)*time.Minute)
+		ok, err = d.reposDigest.Claim(ctx, dg, next)
+		if err != nil {
+			return errors.Wrap(err, "claim digest")
+		}
+		if !ok {
+			// already sent by another instance
+			continue
+		}
+
+		f1, f2 := period.Range{Period: dp.period}.Window(sentAt, start)
+		err = publishReport(d.kafkaProducer, kafka.Report{
+			F1:       f1,
+			F2:       f2,
+			ChatId:   dg.ChatId,
+			UserCurr: dg.Currency,
+			StateId:  dg.StateId,
+			Language: dg.Language,
+		})
+		if err != nil {
+			logger.Infos("publish digest error:", dg.ChatId, err.Error())
+			// digest is released to be sent on the next tick, the rest of digests are still sent
+			claimed := dg
+			claimed.NextAt = next
+			if _, err = d.reposDigest.Claim(ctx, claimed, dg.NextAt); err != nil {
+				logger.Infos("release digest error:", dg.ChatId, err.Error())
+			}
+			continue
+		}
+
+		logger.Infos("digest published:", dg.ChatId, dg.Period, f1, f2)
+	}
+
+	return nil
+}
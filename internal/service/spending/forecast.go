@@ -0,0 +1,104 @@
+package spending
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/rates"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/recurring"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/forecast"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/schedule"
+	"time"
+)
+
+const (
+	// forecastHistory is number of previous periods averaged by forecast
+	forecastHistory = 3
+	// forecastThreshold marks warning about projection over limit among sent threshold warnings
+	forecastThreshold = 0
+)
+
+// Forecaster projects spending to the end of period
+type Forecaster struct {
+	reposSpend repository.Spending
+	reposRec   recurring.Client
+	rates      rates.Client
+}
+
+func NewForecaster(reposSpending repository.Spending, reposRecurring recurring.Client, rates rates.Client) *Forecaster {
+	return &Forecaster{
+		reposSpend: reposSpending,
+		reposRec:   reposRecurring,
+		rates:      rates,
+	}
+}
+
+// Forecast returns spending by category expected at the end of period r with t in currency curr,
+// recurring spending of state is counted as known items and is not projected at daily rate
+func (f *Forecaster) Forecast(ctx context.Context, stateId int, curr model.Currency, r period.Range,
+	start period.Start, t time.Time) (map[int]decimal.Decimal, error) {
+	f1, f2 := r.Window(t, start)
+	_, today := period.Day(t)
+
+	// recurring spending is known, so only the rest of spending is projected at daily rate
+	actual, recurringActual, err := f.reposSpend.ReportRecurring(ctx, stateId, f1, today, f.rates, curr)
+	if err != nil {
+		return nil, errors.Wrap(err, "forecast actual")
+	}
+	in := forecast.Input{
+		Actual:    actual,
+		Recurring: recurringActual,
+		Elapsed:   days(f1, today),
+		Total:     days(f1, f2),
+	}
+
+	// rolling period always ends today, so it has no history of the same period
+	if r.Period != period.PeriodRolling {
+		prev := f1
+		for i := 0; i < forecastHistory; i++ {
+			pf1, pf2 := r.Window(prev.AddDate(0, 0, -1), start)
+			history, _, err := f.reposSpend.ReportRecurring(ctx, stateId, pf1, pf2, f.rates, curr)
+			if err != nil {
+				return nil, errors.Wrap(err, "forecast history")
+			}
+			if len(history) > 0 {
+				in.History = append(in.History, history)
+			}
+			prev = pf1
+		}
+	}
+
+	rate, ok := f.rates.GetRate(ctx, curr)
+	if !ok {
+		return nil, errors.New("forecast rate not found")
+	}
+	recurrings, err := f.reposRec.Recurrings(ctx, stateId)
+	if err != nil {
+		return nil, errors.Wrap(err, "forecast recurrings")
+	}
+	// occurrences are compared as dates read from database
+	first, last := period.Date(f1), period.Date(f2)
+	for _, rec := range recurrings {
+		from := rec.LastOccurrence
+		if from.Before(first) {
+			from = first.AddDate(0, 0, -1)
+		}
+		sched := schedule.Schedule{Period: schedule.Period(rec.Period), Day: rec.Day, Month: time.Month(rec.Month)}
+		occurrences := len(sched.Between(from, last))
+		if occurrences > 0 {
+			price := decimal.Decimal(rec.Price).Divide(rate.Rate)
+			in.Recurring[rec.Category.Id] += price * decimal.Decimal(occurrences)
+		}
+	}
+
+	return forecast.Project(in), nil
+}
+
+// days returns number of calendar days from f1 to f2 including both, dates are compared in UTC
+// so days of daylight saving time changes are not shorter or longer
+func days(f1, f2 time.Time) int {
+	return int(period.Date(f2).Sub(period.Date(f1)).Hours()/24) + 1
+}
@@ -0,0 +1,96 @@
+//go:build integration
+// +build integration
+
+package spending
+
+import (
+	"context"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/rates"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/recurring"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// monthEvents has spending in march only, part of it is added by recurring spending
+type monthEvents struct {
+	repository.Spending
+	other, recurring map[int]decimal.Decimal
+}
+
+func (m *monthEvents) ReportRecurring(_ context.Context, _ int, f1, _ time.Time, _ rates.Client,
+	_ model.Currency) (map[int]decimal.Decimal, map[int]decimal.Decimal, error) {
+	if f1.Month() != time.March {
+		return map[int]decimal.Decimal{}, map[int]decimal.Decimal{}, nil
+	}
+	return m.other, m.recurring, nil
+}
+
+type noRecurrings struct {
+	recurring.Client
+}
+
+func (noRecurrings) Recurrings(context.Context, int) ([]model.Recurring, error) {
+	return nil, nil
+}
+
+type oneRates struct {
+	rates.Client
+}
+
+func (oneRates) GetRate(_ context.Context, curr model.Currency) (*rates.Rate, bool) {
+	return &rates.Rate{Currency: curr, Rate: decimal.ToDecimal(1)}, true
+}
+
+func TestForecaster_Forecast(t *testing.T) {
+	events := &monthEvents{
+		other:     map[int]decimal.Decimal{1: decimal.ToDecimal(100)},
+		recurring: map[int]decimal.Decimal{1: decimal.ToDecimal(50)},
+	}
+	f := NewForecaster(events, noRecurrings{}, oneRates{})
+
+	got, err := f.Forecast(context.Background(), 5, model.Currency{Abbr: "RUB"},
+		period.Range{Period: period.PeriodMonth}, period.Start{}, time.Date(2022, 3, 10, 12, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	// 100 spent in 10 days is projected to the rest 21 days, recurring 50 is counted once
+	assert.Equal(t, map[int]decimal.Decimal{1: decimal.ToDecimal(360)}, got)
+}
+
+func Test_days(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		f1, f2 time.Time
+		want   int
+	}{
+		{
+			name: "Same day",
+			f1:   time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+			f2:   time.Date(2022, 3, 1, 23, 59, 59, 0, time.UTC),
+			want: 1,
+		},
+		{
+			name: "Summer time",
+			f1:   time.Date(2022, 3, 1, 0, 0, 0, 0, berlin),
+			f2:   time.Date(2022, 3, 31, 0, 0, 0, 0, berlin),
+			want: 31,
+		},
+		{
+			name: "Winter time",
+			f1:   time.Date(2022, 10, 1, 0, 0, 0, 0, berlin),
+			f2:   time.Date(2022, 10, 31, 23, 59, 59, 0, berlin),
+			want: 31,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, days(tt.f1, tt.f2))
+		})
+	}
+}
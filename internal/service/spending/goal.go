@@ -0,0 +1,414 @@
+package spending
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/budget"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/goal"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/rates"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/state"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	goalCalc "github.com/sku4/ozon-route256-spending-bot/pkg/goal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const goalPrefix = "goal_"
+
+// GoalProgress is goal with amounts in default currency
+type GoalProgress struct {
+	model.Goal
+	Saved      decimal.Decimal
+	Required   decimal.Decimal
+	MonthsLeft int
+	OnTrack    bool
+}
+
+// GoalTracker evaluates progress of savings goals
+type GoalTracker struct {
+	reposGoal   goal.Client
+	reposBudget budget.Client
+	reposSpend  repository.Spending
+	rates       rates.Client
+}
+
+func NewGoalTracker(reposGoal goal.Client, reposBudget budget.Client, reposSpending repository.Spending,
+	rates rates.Client) *GoalTracker {
+	return &GoalTracker{
+		reposGoal:   reposGoal,
+		reposBudget: reposBudget,
+		reposSpend:  reposSpending,
+		rates:       rates,
+	}
+}
+
+// Progress returns goals of state with saved amount at t: manual contributions and, for savings goals,
+// unused budget of closed months without rollover, which fills goals by deadline one after another
+func (g *GoalTracker) Progress(ctx context.Context, st *state.State, t time.Time) ([]GoalProgress, error) {
+	goals, err := g.reposGoal.Goals(ctx, st.Id)
+	if err != nil {
+		return nil, errors.Wrap(err, "goals")
+	}
+
+	progress := make([]GoalProgress, len(goals))
+	for i, gl := range goals {
+		progress[i] = GoalProgress{Goal: gl, Saved: decimal.Decimal(gl.Contributed)}
+	}
+	if err = g.addSavings(ctx, st, progress, t); err != nil {
+		return nil, err
+	}
+	for i := range progress {
+		p := &progress[i]
+		p.MonthsLeft = goalCalc.MonthsLeft(t, p.Deadline)
+		p.Required = goalCalc.Required(decimal.Decimal(p.Target), p.Saved, p.MonthsLeft)
+		p.OnTrack = goalCalc.OnTrack(decimal.Decimal(p.Target), p.Saved, p.CreatedAt, p.Deadline, t)
+	}
+
+	return progress, nil
+}
+
+// addSavings adds unused budget of every closed month since the first savings goal was created
+func (g *GoalTracker) addSavings(ctx context.Context, st *state.State, progress []GoalProgress, t time.Time) error {
+	var first time.Time
+	for _, p := range progress {
+		if p.Savings && (first.IsZero() || p.CreatedAt.Before(first)) {
+			first = p.CreatedAt
+		}
+	}
+	if first.IsZero() {
+		return nil
+	}
+
+	start, loc := st.GetStart(ctx), st.GetLocation(ctx)
+	curr, err := st.GetCurrency(ctx)
+	if err != nil {
+		return errors.Wrap(err, "currency not found")
+	}
+	rate, ok := g.rates.GetRate(ctx, curr)
+	if !ok {
+		return errors.New("goal rate not found")
+	}
+
+	current, _ := start.Month(t.In(loc))
+	for f1, f2 := start.Month(first.In(loc)); f1.Before(current); f1, f2 = start.Month(f2.AddDate(0, 0, 1)) {
+		b, err := g.reposBudget.GetBudget(ctx, st.Id, f1)
+		if errors.Is(err, budget.NotFoundError) {
+			continue
+		}
+		if err != nil {
+			return errors.Wrap(err, "goal budget")
+		}
+		// unused budget with rollover is carried to next month instead of savings
+		if b.Rollover {
+			continue
+		}
+		m, err := g.reposSpend.Report(ctx, st.Id, f1, f2, g.rates, curr)
+		if err != nil {
+			return errors.Wrap(err, "goal month report")
+		}
+		var spent decimal.Decimal
+		for _, sum := range m {
+			spent += sum
+		}
+		left := decimal.Decimal(b.Total+b.Carried) - spent.Multiply(rate.Rate)
+		if left <= 0 {
+			continue
+		}
+
+		needs := make([]decimal.Decimal, len(progress))
+		for i, p := range progress {
+			if p.Savings && p.CreatedAt.Before(f2) {
+				needs[i] = decimal.Decimal(p.Target) - p.Saved
+			}
+		}
+		for i, part := range goalCalc.Allocate(left, needs) {
+			progress[i].Saved += part
+		}
+	}
+
+	return nil
+}
+
+// goalsMessage returns progress of goals in currencies of goals
+func goalsMessage(ctx context.Context, progress []GoalProgress, rates rates.Client,
+	defaultCurr model.Currency) string {
+	pr := i18n.FromContext(ctx)
+	msg := ""
+	for _, p := range progress {
+		curr := p.Currency
+		if curr.Id == 0 {
+			curr = defaultCurr
+		}
+		rate, ok := rates.GetRate(ctx, curr)
+		if !ok {
+			continue
+		}
+		target, saved := decimal.Decimal(p.Target).Divide(rate.Rate), p.Saved.Divide(rate.Rate)
+		percent := 0
+		if p.Target > 0 {
+			percent = int(int64(p.Saved) * 100 / p.Target)
+		}
+		msg += pr.Sprintf("_%s_ - %.2f of %.2f %s (%d%%) by %s", p.Title, saved, target, curr.Abbr,
+			percent, pr.Date(p.Deadline))
+		switch {
+		case p.Saved >= decimal.Decimal(p.Target):
+			msg += pr.Sprintf(" - reached\n")
+		case p.MonthsLeft == 0:
+			msg += pr.Sprintf(" - deadline passed\n")
+		default:
+			status := pr.Sprintf("on track")
+			if !p.OnTrack {
+				status = pr.Sprintf("behind")
+			}
+			msg += pr.Sprintf(", %.2f %s a month for %s - %s\n", p.Required.Divide(rate.Rate), curr.Abbr,
+				pr.Plural(p.MonthsLeft, "%d month", "%d months", p.MonthsLeft), status)
+		}
+	}
+
+	return msg
+}
+
+// Goal shows goals or manages them: `/goal Vacation 150000 EUR by August` adds goal,
+// `savings` at the end fills it with unused budget, `/goal Vacation +5000` logs contribution
+func (s *Service) Goal(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	if !s.rates.IsLoaded(ctx) {
+		_ = s.client.SendMessage(pr.Sprintf("Rates not loaded, please repeat later"), update.Message.Chat.ID)
+		return errors.New("rates still not loaded")
+	}
+	chatId := update.Message.Chat.ID
+
+	fields := strings.Fields(update.Message.CommandArguments())
+	switch {
+	case len(fields) == 0:
+		msg, inlineKeyboardRows, errList := s.goalList(ctx)
+		if errList != nil {
+			return errList
+		}
+		if len(inlineKeyboardRows) == 0 {
+			return s.client.SendMessage(msg, chatId)
+		}
+		return s.client.SendInlineKeyboard(inlineKeyboardRows, msg, chatId)
+	case byIndex(fields) > 0:
+		return s.goalAdd(ctx, fields, chatId)
+	default:
+		return s.goalContribute(ctx, fields, chatId)
+	}
+}
+
+// GoalQuery deletes goal by button of goals list
+func (s *Service) GoalQuery(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	chatId := update.CallbackQuery.Message.Chat.ID
+	messageId := update.CallbackQuery.Message.MessageID
+
+	uState, err := stateFromContext(ctx)
+	if err != nil {
+		return
+	}
+
+	data := update.CallbackQuery.Data[len(goalPrefix):]
+	if strings.Index(data, "del_") != 0 {
+		return errors.New("goal callback data")
+	}
+	goalId, err := strconv.Atoi(data[len("del_"):])
+	if err != nil {
+		return errors.Wrap(err, "goal id convert")
+	}
+	if err = s.reposGoal.DeleteGoal(ctx, uState.Id, goalId); err != nil {
+		_ = s.client.SendMessage(pr.Sprintf("Goal not deleted: %s", err.Error()), chatId)
+		return errors.Wrap(err, "delete goal")
+	}
+
+	msg, inlineKeyboardRows, err := s.goalList(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.client.SendCallbackQuery(inlineKeyboardRows, pr.Sprintf("Goal success deleted")+"\r\n"+msg,
+		messageId, chatId)
+}
+
+func (s *Service) goalList(ctx context.Context) (msg string, inlineKeyboardRows []*client.KeyboardRow, err error) {
+	pr := i18n.FromContext(ctx)
+	uState, err := stateFromContext(ctx)
+	if err != nil {
+		return
+	}
+	uCurrency, err := uState.GetCurrency(ctx)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "currency not found")
+	}
+	progress, err := s.goals.Progress(ctx, uState, time.Now())
+	if err != nil {
+		return "", nil, errors.Wrap(err, "goal progress")
+	}
+	if len(progress) == 0 {
+		return pr.Sprintf("Goals list is empty, write `/goal Vacation 150000 EUR by August` to add goal"), nil, nil
+	}
+
+	msg = pr.Sprintf("Goals:") + "\n" + goalsMessage(ctx, progress, s.rates, uCurrency) +
+		pr.Sprintf("Contribute by `/goal Vacation +5000`")
+	for _, p := range progress {
+		inlineKeyboardRow := client.NewKeyboardRow()
+		inlineKeyboardRow.Add(pr.Sprintf("Delete %s", p.Title), goalPrefix+"del_"+strconv.Itoa(p.Id))
+		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
+	}
+
+	return
+}
+
+// goalAdd parses `Vacation 150000 EUR by August savings`, currency and savings are optional
+func (s *Service) goalAdd(ctx context.Context, fields []string, chatId int64) (err error) {
+	pr := i18n.FromContext(ctx)
+	uState, err := stateFromContext(ctx)
+	if err != nil {
+		return
+	}
+	curr, err := uState.GetCurrency(ctx)
+	if err != nil {
+		return errors.Wrap(err, "currency not found")
+	}
+
+	idx := byIndex(fields)
+	args, deadlineArgs := fields[:idx], fields[idx+1:]
+	savings := false
+	if n := len(deadlineArgs); n > 0 && strings.EqualFold(deadlineArgs[n-1], "savings") {
+		savings = true
+		deadlineArgs = deadlineArgs[:n-1]
+	}
+	if len(args) > 2 {
+		if c, errCurr := s.reposCurr.GetByAbbr(ctx, strings.ToUpper(args[len(args)-1])); errCurr == nil {
+			curr = c
+			args = args[:len(args)-1]
+		}
+	}
+	if len(args) < 2 {
+		_ = s.client.SendMessage(pr.Sprintf(
+			"Goal title or amount is empty, example `/goal Vacation 150000 EUR by August`"), chatId)
+		return errors.New("goal args")
+	}
+	target, err := strconv.ParseFloat(args[len(args)-1], 64)
+	if err != nil || target <= 0 {
+		_ = s.client.SendMessage(pr.Sprintf(
+			"Error convert amount '*%s*', set amount over 0", args[len(args)-1]), chatId)
+		return errors.New("convert goal amount")
+	}
+	title := strings.Join(args[:len(args)-1], " ")
+	now := time.Now().In(uState.GetLocation(ctx))
+	deadline, err := goalCalc.ParseDeadline(strings.Join(deadlineArgs, " "), now)
+	if err != nil {
+		_ = s.client.SendMessage(pr.Sprintf("Error deadline: %s, example `by August`, `by 2023-08-31`",
+			deadlineError(pr, err, strings.Join(deadlineArgs, " "))), chatId)
+		return errors.Wrap(err, "goal deadline")
+	}
+
+	rate, ok := s.rates.GetRate(ctx, curr)
+	if !ok {
+		_ = s.client.SendMessage(pr.Sprintf("Rate *%s* not found", curr.Abbr), chatId)
+		return errors.New("goal rate not found")
+	}
+	_, err = s.reposGoal.AddGoal(ctx, model.Goal{
+		StateId:  uState.Id,
+		Title:    title,
+		Target:   decimal.ToDecimal(target).Multiply(rate.Rate).Original(),
+		Currency: curr,
+		Deadline: deadline,
+		Savings:  savings,
+	})
+	if err != nil {
+		_ = s.client.SendMessage(pr.Sprintf("Error add goal *%s*: %s", title, err.Error()), chatId)
+		return errors.Wrap(err, "add goal")
+	}
+
+	monthsLeft := goalCalc.MonthsLeft(now, deadline)
+	required := goalCalc.Required(decimal.ToDecimal(target), 0, monthsLeft)
+
+	return s.client.SendMessage(pr.Sprintf("Goal *%s* - *%.2f %s* by *%s* success added, "+
+		"save *%.2f %s* a month for %s\r\nShow /goal", title, target, curr.Abbr, pr.Date(deadline), required,
+		curr.Abbr, pr.Plural(monthsLeft, "%d month", "%d months", monthsLeft)), chatId)
+}
+
+// goalContribute parses `Vacation +5000` in currency of goal, negative amount withdraws money from goal
+func (s *Service) goalContribute(ctx context.Context, fields []string, chatId int64) (err error) {
+	pr := i18n.FromContext(ctx)
+	uState, err := stateFromContext(ctx)
+	if err != nil {
+		return
+	}
+	uCurrency, err := uState.GetCurrency(ctx)
+	if err != nil {
+		return errors.Wrap(err, "currency not found")
+	}
+
+	amountArg := fields[len(fields)-1]
+	amount, err := strconv.ParseFloat(amountArg, 64)
+	if len(fields) < 2 || err != nil || amount == 0 {
+		_ = s.client.SendMessage(pr.Sprintf("Error contribution, example `/goal Vacation +5000` "+
+			"or `/goal Vacation 150000 EUR by August` to add goal"), chatId)
+		return errors.New("goal contribution args")
+	}
+	title := strings.Join(fields[:len(fields)-1], " ")
+
+	goals, err := s.reposGoal.Goals(ctx, uState.Id)
+	if err != nil {
+		return errors.Wrap(err, "goals")
+	}
+	var gl *model.Goal
+	for i := range goals {
+		if strings.EqualFold(goals[i].Title, title) {
+			gl = &goals[i]
+			break
+		}
+	}
+	if gl == nil {
+		_ = s.client.SendMessage(pr.Sprintf("Goal *%s* not found, show /goal", title), chatId)
+		return errors.New("goal not found")
+	}
+
+	curr := gl.Currency
+	if curr.Id == 0 {
+		curr = uCurrency
+	}
+	rate, ok := s.rates.GetRate(ctx, curr)
+	if !ok {
+		_ = s.client.SendMessage(pr.Sprintf("Rate *%s* not found", curr.Abbr), chatId)
+		return errors.New("goal rate not found")
+	}
+	now := time.Now().In(uState.GetLocation(ctx))
+	_, err = s.reposGoal.AddContribution(ctx, gl.Id, now, decimal.ToDecimal(amount).Multiply(rate.Rate))
+	if err != nil {
+		_ = s.client.SendMessage(pr.Sprintf("Error add contribution: %s", err.Error()), chatId)
+		return errors.Wrap(err, "add contribution")
+	}
+
+	return s.client.SendMessage(pr.Sprintf("Contribution *%.2f %s* to goal *%s* success added\r\nShow /goal",
+		amount, curr.Abbr, gl.Title), chatId)
+}
+
+// byIndex returns position of deadline keyword `by`, 0 if there is no goal title and amount before it
+func byIndex(fields []string) int {
+	for i := len(fields) - 1; i > 0; i-- {
+		if strings.EqualFold(fields[i], "by") {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// deadlineError returns reason of error of deadline in language of user
+func deadlineError(pr *i18n.Printer, err error, rawDeadline string) string {
+	var past goalCalc.PastDeadlineError
+	if errors.As(err, &past) {
+		return pr.Sprintf("deadline %s is in the past", pr.Date(past.Deadline))
+	}
+
+	return pr.Sprintf("unknown deadline '%s'", rawDeadline)
+}
@@ -0,0 +1,107 @@
+package spending
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/service/dialog"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/schedule"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/user"
+	"strings"
+	"time"
+)
+
+// Language shows or sets language of bot messages by `/language ru`,
+// `/language auto` returns to language of telegram client
+func (s *Service) Language(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+
+	code := strings.TrimSpace(update.Message.CommandArguments())
+	if code != "" {
+		lang, ok := i18n.Lookup(code)
+		if !ok && !strings.EqualFold(code, "auto") {
+			return s.client.SendMessage(pr.Sprintf(
+				"Unknown language '*%s*', example `/language ru`", code), update.Message.Chat.ID)
+		}
+		if err = userCtx.SetLanguage(ctx, string(lang)); err != nil {
+			return errors.Wrap(err, "set language")
+		}
+		if !ok {
+			lang = i18n.Parse(update.Message.From.LanguageCode)
+		}
+		// answer already speaks chosen language
+		pr = i18n.NewPrinter(lang)
+	}
+
+	codes := make([]string, 0, len(i18n.Langs))
+	for _, l := range i18n.Langs {
+		codes = append(codes, "`"+string(l)+"`")
+	}
+
+	return s.client.SendMessage(pr.Sprintf("Language *%s*\r\n"+
+		"Change by `/language ru`, available %s or `auto` for language of telegram",
+		pr.Lang().Title(), strings.Join(codes, ", ")), update.Message.Chat.ID)
+}
+
+// errorText returns error in language of user, errors of dialog.Errorf are translated
+func errorText(pr *i18n.Printer, err error) string {
+	var msgErr dialog.MessageError
+	if errors.As(err, &msgErr) {
+		return msgErr.Message(pr)
+	}
+
+	return err.Error()
+}
+
+// rangeTitle is range of limits and reports used after `per`
+func rangeTitle(pr *i18n.Printer, r period.Range) string {
+	switch r.Period {
+	case period.PeriodDay:
+		return pr.Sprintf("day")
+	case period.PeriodWeek:
+		return pr.Sprintf("week")
+	case period.PeriodMonth:
+		return pr.Sprintf("month")
+	case period.PeriodYear:
+		return pr.Sprintf("year")
+	case period.PeriodRolling:
+		return pr.Plural(r.Days, "%d day", "%d days", r.Days)
+	}
+
+	return r.String()
+}
+
+// periodTitle is title of period button
+func periodTitle(pr *i18n.Printer, p period.Period) string {
+	switch p {
+	case period.PeriodDay:
+		return pr.Sprintf("Day")
+	case period.PeriodWeek:
+		return pr.Sprintf("Week")
+	case period.PeriodMonth:
+		return pr.Sprintf("Month")
+	case period.PeriodYear:
+		return pr.Sprintf("Year")
+	}
+
+	return string(p)
+}
+
+func scheduleTitle(pr *i18n.Printer, s schedule.Schedule) string {
+	switch s.Period {
+	case schedule.Week:
+		return pr.Sprintf("weekly on %s", pr.Weekday(time.Weekday(s.Day)))
+	case schedule.Month:
+		return pr.Sprintf("monthly on day %d", s.Day)
+	case schedule.Year:
+		return pr.Sprintf("yearly on %s", pr.DayMonth(s.Day, s.Month))
+	}
+
+	return s.String()
+}
@@ -2,144 +2,172 @@ package spending
 
 import (
 	"context"
-	"fmt"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 	"github.com/pkg/errors"
-	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
+	"github.com/sku4/ozon-route256-spending-bot/internal/service/dialog"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/user"
 	"strconv"
+	"strings"
 )
 
 //go:generate mockgen -source=limit.go -destination=mocks/limit.go
 
-const limitPrefix = "limit_"
+const (
+	limitPrefix  = "limit_"
+	maxThreshold = 1000
+	// maxThresholds of up to 4 digits fit in limit_thresholds varchar(50)
+	maxThresholds = 10
+)
+
+// limitDialog is limit of category set by `/limit 100 week`
+type limitDialog struct {
+	Price      float64      `json:"p"`
+	Range      period.Range `json:"r"`
+	CategoryId int          `json:"c"`
+}
 
-func (s *Service) LimitAdd(ctx context.Context, update tgbotapi.Update) (err error) {
+func (s *Service) limitFlow() *dialog.Flow[limitDialog] {
+	return &dialog.Flow[limitDialog]{
+		Command: "limit",
+		Prefix:  limitPrefix,
+		Start:   s.limitStart,
+		States: map[string]dialog.State[limitDialog]{
+			"category": {Prompt: s.limitCategory},
+			"save":     {Prompt: s.limitSave, Final: true},
+		},
+	}
+}
+
+func (s *Service) limitStart(ctx context.Context, update tgbotapi.Update) (string, limitDialog, error) {
 	if !s.rates.IsLoaded(ctx) {
-		_ = s.client.SendMessage("Rates not loaded, please repeat later", update.Message.Chat.ID)
-		return errors.New("rates still not loaded")
+		return "", limitDialog{}, dialog.Errorf("Rates not loaded, please repeat later")
 	}
 
-	priceArg := update.Message.CommandArguments()
+	args := strings.Fields(update.Message.CommandArguments())
+	priceArg := ""
+	if len(args) > 0 {
+		priceArg = args[0]
+	}
 	priceLimit, err := strconv.ParseFloat(priceArg, 64)
 	if err != nil {
-		_ = s.client.SendMessage(fmt.Sprintf(
-			"Error convert price '*%s*'", priceArg), update.Message.Chat.ID)
-		return errors.Wrap(err, "convert price")
+		return "", limitDialog{}, dialog.Errorf("Error convert price '*%s*'", priceArg)
 	}
 	if priceLimit <= 0 {
-		_ = s.client.SendMessage("Please set price over 0", update.Message.Chat.ID)
-		return errors.New("Price less than 0")
-	}
-
-	userCtx, err := user.FromContext(ctx)
-	if err != nil {
-		_ = s.client.SendMessage(fmt.Sprintf(
-			"User not found: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
-		return errors.Wrap(err, "user not found")
+		return "", limitDialog{}, dialog.Errorf("Please set price over 0")
 	}
-	uState, err := userCtx.GetState(ctx)
+	r, err := period.Parse(args[1:])
 	if err != nil {
-		_ = s.client.SendMessage(fmt.Sprintf(
-			"State not found: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
-		return errors.Wrap(err, "state not found")
+		return "", limitDialog{}, dialog.Errorf(
+			"Error period: %s\r\nExamples: `/limit 100 day`, `/limit 100 week`, `/limit 100 days 10`", err.Error())
 	}
-	uCurrency, err := uState.GetCurrency(ctx)
+
+	return "category", limitDialog{Price: priceLimit, Range: r, CategoryId: -1}, nil
+}
+
+func (s *Service) limitCategory(ctx context.Context, l limitDialog) (p dialog.Prompt[limitDialog], err error) {
+	pr := i18n.FromContext(ctx)
+	_, uCurrency, err := s.userCurrency(ctx)
 	if err != nil {
-		_ = s.client.SendMessage(fmt.Sprintf(
-			"Currency not found: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
-		return errors.Wrap(err, "currency not found")
+		return p, err
 	}
-	userCurrAbbr := uCurrency.Abbr
-
-	var inlineKeyboardRows []*client.KeyboardRow
-	inlineKeyboardRow := client.NewKeyboardRow()
-	event := NewEvent(priceLimit)
 	cats, err := s.reposCat.Categories(ctx)
 	if err != nil {
-		return errors.New("limit add categories")
+		return p, errors.Wrap(err, "limit add categories")
 	}
 	if len(cats) == 0 {
-		_ = s.client.SendMessage("Categories list is empty, please add /categories", update.Message.Chat.ID)
-		return errors.New("Categories list is empty")
+		return p, dialog.Errorf("Categories list is empty, please add /categories")
 	}
-	for _, c := range cats {
-		event.CategoryId = c.Id
-		eventSer := EventSerialize(event)
-		inlineKeyboardRow.Add(c.Title, limitPrefix+string(eventSer))
-	}
-	inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
 
-	err = s.client.SendInlineKeyboard(inlineKeyboardRows,
-		fmt.Sprintf("Choose category limit (*%.2f %s*):", priceLimit, userCurrAbbr), update.Message.Chat.ID)
-	if err != nil {
-		return err
+	row := make([]dialog.Button[limitDialog], 0, len(cats))
+	for _, c := range cats {
+		l.CategoryId = c.Id
+		row = append(row, dialog.Button[limitDialog]{Title: c.Title, Next: "save", Data: l})
 	}
 
-	return
+	return dialog.Prompt[limitDialog]{
+		Text: pr.Sprintf("Choose category limit (*%.2f %s* per *%s*):", l.Price, uCurrency.Abbr,
+			rangeTitle(pr, l.Range)),
+		Rows: [][]dialog.Button[limitDialog]{row},
+	}, nil
 }
 
-func (s *Service) LimitQuery(ctx context.Context, update tgbotapi.Update) (err error) {
+func (s *Service) limitSave(ctx context.Context, l limitDialog) (p dialog.Prompt[limitDialog], err error) {
+	pr := i18n.FromContext(ctx)
 	if !s.rates.IsLoaded(ctx) {
-		_ = s.client.SendMessage("Rates not loaded, please repeat later", update.Message.Chat.ID)
-		return errors.New("rates still not loaded")
+		return p, dialog.Errorf("Rates not loaded, please repeat later")
 	}
-
-	var inlineKeyboardRows []*client.KeyboardRow
-
-	eventSer := update.CallbackQuery.Data[len(limitPrefix):]
-	event, err := eventUnserialize(eventSer)
+	uState, uCurrency, err := s.userCurrency(ctx)
+	if err != nil {
+		return p, err
+	}
+	catSelected, err := s.reposCat.CategoryGetById(ctx, l.CategoryId)
 	if err != nil {
-		return errors.Wrap(err, "event unserialize")
+		return p, dialog.Errorf("Category not found: %s", err.Error())
+	}
+	if err = l.Range.Validate(); err != nil {
+		return p, errors.Wrap(err, "limit period")
 	}
 
-	catSelected, err := s.reposCat.CategoryGetById(ctx, event.CategoryId)
+	price, err := s.ConvertPrice(ctx, decimal.ToDecimal(l.Price))
 	if err != nil {
-		_ = s.client.SendMessage(fmt.Sprintf(
-			"Category not found: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
-		return errors.Wrap(err, "Category not found")
+		return p, errors.Wrap(err, "limit convert price")
 	}
+	if err = uState.AddLimit(ctx, catSelected.Id, price, l.Range); err != nil {
+		return p, dialog.Errorf("Limit not add: %s", err.Error())
+	}
+	p.Text = pr.Sprintf("Limit *%.2f %s* per *%s* for category *%s* success added",
+		l.Price, uCurrency.Abbr, rangeTitle(pr, l.Range), catSelected.Title)
+
+	return p, nil
+}
 
+// Thresholds shows or sets percents of limit to warn about by `/thresholds 50 80 100`
+func (s *Service) Thresholds(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
 	userCtx, err := user.FromContext(ctx)
 	if err != nil {
-		_ = s.client.SendMessage(fmt.Sprintf(
-			"User not found: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
 		return errors.Wrap(err, "user not found")
 	}
 	uState, err := userCtx.GetState(ctx)
 	if err != nil {
-		_ = s.client.SendMessage(fmt.Sprintf(
-			"State not found: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
 		return errors.Wrap(err, "state not found")
 	}
-	uCurrency, err := uState.GetCurrency(ctx)
-	if err != nil {
-		_ = s.client.SendMessage(fmt.Sprintf(
-			"Currency not found: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
-		return errors.Wrap(err, "currency not found")
-	}
-	userCurrAbbr := uCurrency.Abbr
 
-	if catSelected.Id > -1 {
-		price, err := s.ConvertPrice(ctx, decimal.ToDecimal(event.Price))
-		if err != nil {
-			return errors.Wrap(err, "limit convert price")
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) > 0 {
+		thresholds := make([]int, 0, len(args))
+		seen := make(map[int]bool, len(args))
+		for _, arg := range args {
+			threshold, errConv := strconv.Atoi(strings.TrimSuffix(arg, "%"))
+			if errConv != nil || threshold < 1 || threshold > maxThreshold {
+				_ = s.client.SendMessage(pr.Sprintf(
+					"Error threshold '*%s*', set percents from 1 to %d", arg, maxThreshold), update.Message.Chat.ID)
+				return errors.New("threshold out of range")
+			}
+			if !seen[threshold] {
+				seen[threshold] = true
+				thresholds = append(thresholds, threshold)
+			}
 		}
-		err = uState.AddLimit(ctx, catSelected.Id, price)
-		if err != nil {
-			_ = s.client.SendMessage(fmt.Sprintf(
-				"Limit not add: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
-			return errors.Wrap(err, "limit not add")
+		if len(thresholds) > maxThresholds {
+			_ = s.client.SendMessage(pr.Sprintf("Too many thresholds, set up to %d percents", maxThresholds),
+				update.Message.Chat.ID)
+			return errors.New("too many thresholds")
 		}
-
-		err = s.client.SendCallbackQuery(inlineKeyboardRows, fmt.Sprintf(
-			"Limit *%.2f %s* for category *%s* success added", event.Price, userCurrAbbr, catSelected.Title),
-			update.CallbackQuery.Message.MessageID, update.CallbackQuery.Message.Chat.ID)
-		if err != nil {
-			return err
+		if err = uState.SetThresholds(ctx, thresholds); err != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Thresholds not set: %s", err.Error()), update.Message.Chat.ID)
+			return errors.Wrap(err, "set thresholds")
 		}
 	}
 
-	return
+	percents := make([]string, 0)
+	for _, threshold := range uState.GetThresholds(ctx) {
+		percents = append(percents, strconv.Itoa(threshold)+"%")
+	}
+
+	return s.client.SendMessage(pr.Sprintf("Limit warnings at *%s*\r\n"+
+		"Change by `/thresholds 50 80 100`", strings.Join(percents, ", ")), update.Message.Chat.ID)
 }
@@ -0,0 +1,311 @@
+package spending
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/category_limit"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/state"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	limitsPrefix      = "limits_"
+	progressBarLength = 10
+)
+
+var limitSteps = []int{-25, -10, 10, 25}
+
+// LimitSpend is category limit with spending in its current period, amounts in currency of state
+type LimitSpend struct {
+	Id       int
+	Category model.Category
+	Range    period.Range
+	Limit    decimal.Decimal
+	Spent    decimal.Decimal
+}
+
+// Limits shows limits with spending in current period and buttons to change or remove them
+func (s *Service) Limits(ctx context.Context, update tgbotapi.Update) (err error) {
+	inlineKeyboardRows, msg, err := s.limitsList(ctx)
+	if err != nil {
+		return errors.Wrap(err, "limits list")
+	}
+
+	return s.client.SendInlineKeyboard(inlineKeyboardRows, msg, update.Message.Chat.ID)
+}
+
+func (s *Service) LimitsQuery(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	chatId := update.CallbackQuery.Message.Chat.ID
+	messageId := update.CallbackQuery.Message.MessageID
+
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+
+	data := update.CallbackQuery.Data[len(limitsPrefix):]
+	args := strings.Split(data, "_")
+	if args[0] == "list" {
+		inlineKeyboardRows, msg, err := s.limitsList(ctx)
+		if err != nil {
+			return errors.Wrap(err, "limits list")
+		}
+		return s.client.SendCallbackQuery(inlineKeyboardRows, msg, messageId, chatId)
+	}
+	if len(args) < 2 {
+		return errors.New("limits callback data")
+	}
+	limitId, err := strconv.Atoi(args[1])
+	if err != nil {
+		return errors.Wrap(err, "limit id convert")
+	}
+	limit, err := stateLimit(ctx, uState, limitId)
+	if err != nil {
+		_ = s.client.SendMessage(pr.Sprintf("Limit not found, show /limits"), chatId)
+		return errors.Wrap(err, "limits query")
+	}
+
+	switch args[0] {
+	case "del":
+		if err = uState.DeleteLimit(ctx, limitId); err != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Limit not removed: %s", err.Error()), chatId)
+			return errors.Wrap(err, "delete limit")
+		}
+		inlineKeyboardRows, msg, err := s.limitsList(ctx)
+		if err != nil {
+			return errors.Wrap(err, "limits list")
+		}
+		return s.client.SendCallbackQuery(inlineKeyboardRows, msg, messageId, chatId)
+	case "pct":
+		if len(args) != 3 {
+			return errors.New("limits callback data")
+		}
+		step, errConv := strconv.Atoi(args[2])
+		if errConv != nil {
+			return errors.Wrap(errConv, "limit step convert")
+		}
+		newLimit := decimal.Decimal(int64(limit.Limit) * int64(100+step) / 100)
+		if newLimit <= 0 {
+			return errors.New("limit less than 0")
+		}
+		if err = uState.AddLimit(ctx, limit.Category.Id, newLimit, limit.Range); err != nil {
+			return errors.Wrap(err, "change limit")
+		}
+	case "period":
+		if len(args) != 3 {
+			return errors.New("limits callback data")
+		}
+		r := period.Range{Period: period.Period(args[2])}
+		if err = r.Validate(); err != nil {
+			return errors.Wrap(err, "limit period")
+		}
+		if err = uState.AddLimit(ctx, limit.Category.Id, limit.Limit, r); err != nil {
+			return errors.Wrap(err, "change limit period")
+		}
+	case "edit":
+	default:
+		return errors.New("limits callback data")
+	}
+
+	inlineKeyboardRows, msg, err := s.limitEdit(ctx, uState, limitId)
+	if err != nil {
+		return errors.Wrap(err, "limit edit")
+	}
+
+	return s.client.SendCallbackQuery(inlineKeyboardRows, msg, messageId, chatId)
+}
+
+func (s *Service) limitsList(ctx context.Context) (inlineKeyboardRows []*client.KeyboardRow, msg string, err error) {
+	pr := i18n.FromContext(ctx)
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "state not found")
+	}
+
+	limits, curr, err := s.limitsSpend(ctx, uState)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "limits spend")
+	}
+	if len(limits) == 0 {
+		return nil, pr.Sprintf("Limits list is empty, write `/limit 100 week` to add limit"), nil
+	}
+
+	msg = pr.Sprintf("Limits list, choose to change or remove:") + "\n"
+	for i, l := range limits {
+		msg += pr.Sprintf("%d. _%s_ - %.2f of %.2f %s per %s\n`%s`\n", i+1, l.Category.Title,
+			l.Spent, l.Limit, curr.Abbr, rangeTitle(pr, l.Range), progressBar(l.Spent, l.Limit))
+		inlineKeyboardRow := client.NewKeyboardRow()
+		inlineKeyboardRow.Add(pr.Sprintf("Change %d. %s", i+1, l.Category.Title),
+			limitsPrefix+"edit_"+strconv.Itoa(l.Id))
+		inlineKeyboardRow.Add(pr.Sprintf("Remove %d. %s", i+1, l.Category.Title),
+			limitsPrefix+"del_"+strconv.Itoa(l.Id))
+		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
+	}
+
+	return
+}
+
+func (s *Service) limitEdit(ctx context.Context, st *state.State, limitId int) (
+	inlineKeyboardRows []*client.KeyboardRow, msg string, err error) {
+	pr := i18n.FromContext(ctx)
+	limits, curr, err := s.limitsSpend(ctx, st)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "limits spend")
+	}
+
+	for _, l := range limits {
+		if l.Id != limitId {
+			continue
+		}
+
+		msg = pr.Sprintf("Limit *%s* - %.2f of *%.2f %s* per *%s*\n`%s`\nChange sum or period:",
+			l.Category.Title, l.Spent, l.Limit, curr.Abbr, rangeTitle(pr, l.Range), progressBar(l.Spent, l.Limit))
+		id := strconv.Itoa(l.Id)
+		stepsRow := client.NewKeyboardRow()
+		for _, step := range limitSteps {
+			stepsRow.Add(fmt.Sprintf("%+d%%", step), limitsPrefix+"pct_"+id+"_"+strconv.Itoa(step))
+		}
+		periodsRow := client.NewKeyboardRow()
+		for _, p := range []period.Period{period.PeriodDay, period.PeriodWeek, period.PeriodMonth, period.PeriodYear} {
+			title := periodTitle(pr, p)
+			if p == l.Range.Period {
+				title = "✓ " + title
+			}
+			periodsRow.Add(title, limitsPrefix+"period_"+id+"_"+string(p))
+		}
+		backRow := client.NewKeyboardRow()
+		backRow.Add(pr.Sprintf("<< Back"), limitsPrefix+"list")
+
+		return []*client.KeyboardRow{stepsRow, periodsRow, backRow}, msg, nil
+	}
+
+	return nil, "", category_limit.NotFoundError
+}
+
+// limitsSpend returns limits of state with spending in current period of every limit
+func (s *Service) limitsSpend(ctx context.Context, st *state.State) (ls []LimitSpend, curr model.Currency, err error) {
+	curr, err = st.GetCurrency(ctx)
+	if err != nil {
+		return nil, model.Currency{}, errors.Wrap(err, "currency not found")
+	}
+	rate, ok := s.rates.GetRate(ctx, curr)
+	if !ok {
+		return nil, model.Currency{}, errors.New("rate not found")
+	}
+	limits, err := st.GetLimits(ctx)
+	if err != nil {
+		return nil, model.Currency{}, errors.Wrap(err, "get limits")
+	}
+
+	now, start := time.Now().In(st.GetLocation(ctx)), st.GetStart(ctx)
+	reports := make(map[period.Range]map[int]decimal.Decimal)
+	for _, l := range limits {
+		m, ok := reports[l.Range]
+		if !ok {
+			f1, f2 := l.Range.Window(now, start)
+			m, err = s.reposSpend.Report(ctx, st.Id, f1, f2, s.rates, curr)
+			if err != nil {
+				return nil, model.Currency{}, errors.Wrap(err, "limits report")
+			}
+			reports[l.Range] = m
+		}
+		ls = append(ls, LimitSpend{
+			Id:       l.Id,
+			Category: *l.Category,
+			Range:    l.Range,
+			Limit:    l.Limit.Divide(rate.Rate),
+			Spent:    m[l.Category.Id],
+		})
+	}
+
+	return
+}
+
+// StateLimits returns limits of state with spending, used by api
+func (s *Service) StateLimits(ctx context.Context, stateId int) ([]LimitSpend, model.Currency, error) {
+	st, err := s.reposState.GetById(ctx, stateId)
+	if err != nil {
+		return nil, model.Currency{}, errors.Wrap(err, "state not found")
+	}
+
+	return s.limitsSpend(ctx, st)
+}
+
+// StateSetLimit adds or changes limit of category, limit is in currency of state
+func (s *Service) StateSetLimit(ctx context.Context, stateId, categoryId int, limit decimal.Decimal,
+	r period.Range) error {
+	if err := r.Validate(); err != nil {
+		return errors.Wrap(err, "limit period")
+	}
+	st, err := s.reposState.GetById(ctx, stateId)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+	curr, err := st.GetCurrency(ctx)
+	if err != nil {
+		return errors.Wrap(err, "currency not found")
+	}
+	rate, ok := s.rates.GetRate(ctx, curr)
+	if !ok {
+		return errors.New("rate not found")
+	}
+
+	return st.AddLimit(ctx, categoryId, limit.Multiply(rate.Rate), r)
+}
+
+func (s *Service) StateDeleteLimit(ctx context.Context, stateId, limitId int) error {
+	st, err := s.reposState.GetById(ctx, stateId)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+
+	return st.DeleteLimit(ctx, limitId)
+}
+
+func stateLimit(ctx context.Context, st *state.State, limitId int) (*category_limit.CategoryLimit, error) {
+	limits, err := st.GetLimits(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get limits")
+	}
+	for _, l := range limits {
+		if l.Id == limitId {
+			return l, nil
+		}
+	}
+
+	return nil, category_limit.NotFoundError
+}
+
+// progressBar draws spent part of limit, for example `▓▓▓▓▓▓▓▓░░ 80%`
+func progressBar(spent, limit decimal.Decimal) string {
+	percent := 0
+	if limit > 0 {
+		percent = int(int64(spent) * 100 / int64(limit))
+	}
+	filled := percent * progressBarLength / 100
+	if filled > progressBarLength {
+		filled = progressBarLength
+	}
+
+	return strings.Repeat("▓", filled) + strings.Repeat("░", progressBarLength-filled) +
+		fmt.Sprintf(" %d%%", percent)
+}
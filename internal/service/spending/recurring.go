@@ -0,0 +1,312 @@
+package spending
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/logger"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/schedule"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/timezone"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	recurringPrefix   = "recurring_"
+	recurringInterval = 10 * time.Minute
+)
+
+// Recurring shows recurring spendings or defines new one by `/recurring 500 month 10`
+func (s *Service) Recurring(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		inlineKeyboardRows, msg, err := s.recurringList(ctx)
+		if err != nil {
+			return errors.Wrap(err, "recurring list")
+		}
+		return s.client.SendInlineKeyboard(inlineKeyboardRows, msg, update.Message.Chat.ID)
+	}
+
+	if !s.rates.IsLoaded(ctx) {
+		_ = s.client.SendMessage(pr.Sprintf("Rates not loaded, please repeat later"), update.Message.Chat.ID)
+		return errors.New("rates still not loaded")
+	}
+
+	price, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		_ = s.client.SendMessage(pr.Sprintf(
+			"Error convert price '*%s*'", args[0]), update.Message.Chat.ID)
+		return errors.Wrap(err, "convert price")
+	}
+	if price <= 0 {
+		_ = s.client.SendMessage(pr.Sprintf("Please set price over 0"), update.Message.Chat.ID)
+		return errors.New("Price less than 0")
+	}
+	sched, err := schedule.Parse(args[1:])
+	if err != nil {
+		_ = s.client.SendMessage(pr.Sprintf(
+			"Error schedule: %s\r\nExamples: `month 10`, `week mon`, `year 03-15`", err.Error()),
+			update.Message.Chat.ID)
+		return errors.Wrap(err, "recurring schedule")
+	}
+
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+	uCurrency, err := uState.GetCurrency(ctx)
+	if err != nil {
+		return errors.Wrap(err, "currency not found")
+	}
+
+	categories, err := s.reposCat.Categories(ctx)
+	if err != nil {
+		return errors.Wrap(err, "recurring categories")
+	}
+	if len(categories) == 0 {
+		_ = s.client.SendMessage(pr.Sprintf("Categories list is empty, please add /categories"), update.Message.Chat.ID)
+		return errors.New("Categories list is empty")
+	}
+
+	inlineKeyboardRow := client.NewKeyboardRow()
+	for _, c := range categories {
+		inlineKeyboardRow.Add(c.Title, recurringPrefix+"add_"+strings.Join([]string{
+			strconv.FormatFloat(price, 'f', 2, 64),
+			string(sched.Period),
+			strconv.Itoa(sched.Day),
+			strconv.Itoa(int(sched.Month)),
+			strconv.Itoa(c.Id),
+		}, "_"))
+	}
+
+	err = s.client.SendInlineKeyboard([]*client.KeyboardRow{inlineKeyboardRow}, pr.Sprintf(
+		"Choose category (*%.2f %s* %s):", price, uCurrency.Abbr, scheduleTitle(pr, sched)), update.Message.Chat.ID)
+
+	return
+}
+
+func (s *Service) RecurringQuery(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	var inlineKeyboardRows []*client.KeyboardRow
+	chatId := update.CallbackQuery.Message.Chat.ID
+	messageId := update.CallbackQuery.Message.MessageID
+
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+
+	data := update.CallbackQuery.Data[len(recurringPrefix):]
+	switch {
+	case strings.Index(data, "add_") == 0:
+		args := strings.Split(data[len("add_"):], "_")
+		if len(args) != 5 {
+			return errors.New("recurring callback data")
+		}
+		price, errParse := strconv.ParseFloat(args[0], 64)
+		if errParse != nil {
+			return errors.Wrap(errParse, "recurring price")
+		}
+		day, errDay := strconv.Atoi(args[2])
+		month, errMonth := strconv.Atoi(args[3])
+		categoryId, errCat := strconv.Atoi(args[4])
+		if errDay != nil || errMonth != nil || errCat != nil {
+			return errors.New("recurring callback data")
+		}
+		sched := schedule.Schedule{Period: schedule.Period(args[1]), Day: day, Month: time.Month(month)}
+		if err = sched.Validate(); err != nil {
+			return errors.Wrap(err, "recurring schedule")
+		}
+		cat, err := s.reposCat.CategoryGetById(ctx, categoryId)
+		if err != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Category not found: %s", err.Error()), chatId)
+			return errors.Wrap(err, "recurring category")
+		}
+		uCurrency, err := uState.GetCurrency(ctx)
+		if err != nil {
+			return errors.Wrap(err, "currency not found")
+		}
+		basePrice, err := s.ConvertPrice(ctx, decimal.ToDecimal(price))
+		if err != nil {
+			return errors.Wrap(err, "recurring convert price")
+		}
+
+		now := time.Now().In(uState.GetLocation(ctx))
+		_, err = s.reposRec.AddRecurring(ctx, model.Recurring{
+			StateId:   uState.Id,
+			ChatId:    chatId,
+			Category:  *cat,
+			AccountId: uState.GetLastAccount(ctx),
+			Currency:  uCurrency,
+			Price:     basePrice.Original(),
+			Period:    string(sched.Period),
+			Day:       sched.Day,
+			Month:     int(sched.Month),
+			StartAt:   now,
+			Language:  string(pr.Lang()),
+		})
+		if err != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Error add recurring: %s", err.Error()), chatId)
+			return errors.Wrap(err, "add recurring")
+		}
+
+		return s.client.SendCallbackQuery(inlineKeyboardRows, pr.Sprintf(
+			"Recurring *%.2f %s* to *%s* %s success added, next on *%s*\r\n"+
+				"Show /recurring", price, uCurrency.Abbr, cat.Title, scheduleTitle(pr, sched),
+			pr.Date(sched.Next(now))), messageId, chatId)
+	case strings.Index(data, "del_") == 0:
+		recurringId, errConv := strconv.Atoi(data[len("del_"):])
+		if errConv != nil {
+			return errors.Wrap(errConv, "recurring id convert")
+		}
+		if err = s.reposRec.DeleteRecurring(ctx, uState.Id, recurringId); err != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Recurring not deleted: %s", err.Error()), chatId)
+			return errors.Wrap(err, "delete recurring")
+		}
+		inlineKeyboardRows, msg, err := s.recurringList(ctx)
+		if err != nil {
+			return errors.Wrap(err, "recurring list")
+		}
+		return s.client.SendCallbackQuery(inlineKeyboardRows, msg, messageId, chatId)
+	case strings.Index(data, "undo_") == 0:
+		eventId, errConv := strconv.Atoi(data[len("undo_"):])
+		if errConv != nil {
+			return errors.Wrap(errConv, "recurring event id convert")
+		}
+		// event may be undone only by owner of recurring
+		if _, err = s.reposRec.GetByEvent(ctx, uState.Id, eventId); err != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Event already undone or not found"), chatId)
+			return errors.Wrap(err, "recurring by event")
+		}
+		if err = s.reposSpend.DeleteEvent(ctx, eventId); err != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Event not deleted: %s", err.Error()), chatId)
+			return errors.Wrap(err, "undo recurring event")
+		}
+
+		return s.client.SendCallbackQuery(inlineKeyboardRows, pr.Sprintf("Recurring event undone"), messageId, chatId)
+	}
+
+	return
+}
+
+func (s *Service) recurringList(ctx context.Context) (inlineKeyboardRows []*client.KeyboardRow, msg string, err error) {
+	pr := i18n.FromContext(ctx)
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "state not found")
+	}
+
+	recurrings, err := s.reposRec.Recurrings(ctx, uState.Id)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "recurrings")
+	}
+	if len(recurrings) == 0 {
+		return nil, pr.Sprintf("Recurring list is empty, write `/recurring 500 month 10` to added " +
+			"monthly spending on day 10"), nil
+	}
+
+	msg = pr.Sprintf("Recurring list, choose to delete:") + "\n"
+	for i, r := range recurrings {
+		sched := schedule.Schedule{Period: schedule.Period(r.Period), Day: r.Day, Month: time.Month(r.Month)}
+		msg += pr.Sprintf("%d. _%s_ - %.2f %s %s\n", i+1, r.Category.Title,
+			s.recurringPrice(ctx, r), r.Currency.Abbr, scheduleTitle(pr, sched))
+		inlineKeyboardRow := client.NewKeyboardRow()
+		inlineKeyboardRow.Add(pr.Sprintf("Delete %d. %s", i+1, r.Category.Title),
+			recurringPrefix+"del_"+strconv.Itoa(r.Id))
+		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
+	}
+
+	return
+}
+
+// recurringPrice converts price from the default currency to currency of recurring
+func (s *Service) recurringPrice(ctx context.Context, r model.Recurring) decimal.Decimal {
+	price := decimal.Decimal(r.Price)
+	if rate, ok := s.rates.GetRate(ctx, r.Currency); ok {
+		return price.Divide(rate.Rate)
+	}
+
+	return price
+}
+
+// RunRecurring materialises due recurring spendings until context is done
+func (s *Service) RunRecurring(ctx context.Context) {
+	ticker := time.NewTicker(recurringInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.materializeRecurring(ctx); err != nil {
+			logger.Infos("materialize recurring error:", err.Error())
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Service) materializeRecurring(ctx context.Context) error {
+	recurrings, err := s.reposRec.All(ctx)
+	if err != nil {
+		return errors.Wrap(err, "all recurrings")
+	}
+
+	now := time.Now()
+	for _, r := range recurrings {
+		loc, err := timezone.Load(r.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+		// occurrences are dates of state time zone, compared with dates read from database
+		today := period.Date(now.In(loc))
+		sched := schedule.Schedule{Period: schedule.Period(r.Period), Day: r.Day, Month: time.Month(r.Month)}
+		// notification speaks language of member who added recurring
+		pr := i18n.NewPrinter(i18n.Lang(r.Language))
+		for _, occurrence := range sched.Between(r.LastOccurrence, today) {
+			eventId, ok, err := s.reposRec.Materialize(ctx, r, occurrence)
+			if err != nil {
+				// broken recurring must not block recurrings of other users, it is retried on next run
+				logger.Infos("materialize recurring error:", errors.Wrapf(err, "recurring %d", r.Id).Error())
+				break
+			}
+			if !ok {
+				// already materialised by another instance
+				continue
+			}
+
+			inlineKeyboardRow := client.NewKeyboardRow()
+			inlineKeyboardRow.Add(pr.Sprintf("Undo"), recurringPrefix+"undo_"+strconv.Itoa(eventId))
+			err = s.client.SendInlineKeyboard([]*client.KeyboardRow{inlineKeyboardRow}, pr.Sprintf(
+				"Recurring event with price *%.2f %s* on *%s* success added to *%s*",
+				s.recurringPrice(ctx, r), r.Currency.Abbr, pr.Date(occurrence), r.Category.Title),
+				r.ChatId)
+			if err != nil {
+				logger.Infos("recurring notification error:", err.Error())
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+//go:build integration
+// +build integration
+
+package spending
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/rates"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/recurring"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// recurringRepos materialises recurrings of its list, recurrings of broken fail
+type recurringRepos struct {
+	recurring.Client
+	list         []model.Recurring
+	broken       map[int]bool
+	materialized []int
+}
+
+func (r *recurringRepos) All(context.Context) ([]model.Recurring, error) {
+	return r.list, nil
+}
+
+func (r *recurringRepos) Materialize(_ context.Context, rec model.Recurring, _ time.Time) (int, bool, error) {
+	if r.broken[rec.Id] {
+		return 0, false, errors.New("category not found")
+	}
+	r.materialized = append(r.materialized, rec.Id)
+
+	return len(r.materialized), true, nil
+}
+
+type noRates struct {
+	rates.Client
+}
+
+func (noRates) GetRate(context.Context, model.Currency) (*rates.Rate, bool) {
+	return nil, false
+}
+
+type sentMessages struct {
+	client.BotClient
+	chats []int64
+}
+
+func (m *sentMessages) SendInlineKeyboard(_ []*client.KeyboardRow, _ string, chatId int64) error {
+	m.chats = append(m.chats, chatId)
+	return nil
+}
+
+func TestService_materializeRecurring(t *testing.T) {
+	yesterday := time.Now().AddDate(0, 0, -1)
+	// weekly on today, so every recurring is due once
+	weekly := func(id int, chatId int64) model.Recurring {
+		return model.Recurring{
+			Id:             id,
+			ChatId:         chatId,
+			Period:         "week",
+			Day:            int(time.Now().Weekday()),
+			Currency:       model.Currency{Abbr: "RUB"},
+			LastOccurrence: yesterday,
+		}
+	}
+	repos := &recurringRepos{
+		list:   []model.Recurring{weekly(1, 10), weekly(2, 20), weekly(3, 30)},
+		broken: map[int]bool{2: true},
+	}
+	bot := &sentMessages{}
+	s := &Service{reposRec: repos, rates: noRates{}, client: bot}
+
+	assert.NoError(t, s.materializeRecurring(context.Background()))
+	assert.Equal(t, []int{1, 3}, repos.materialized)
+	assert.Equal(t, []int64{10, 30}, bot.chats)
+}
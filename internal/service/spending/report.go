@@ -10,14 +10,25 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/budget"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/currency"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/goal"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/rates"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/recurring"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/state"
 	"github.com/sku4/ozon-route256-spending-bot/model"
 	"github.com/sku4/ozon-route256-spending-bot/model/kafka"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/api"
 	apiReport "github.com/sku4/ozon-route256-spending-bot/pkg/api/report"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/forecast"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/logger"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/user"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -32,26 +43,72 @@ var (
 	)
 )
 
+const (
+	GroupByCategory = "category"
+	GroupByMember   = "member"
+)
+
+var (
+	InvalidReportError  = errors.New("invalid report query")
+	RatesNotLoadedError = errors.New("rates not loaded")
+)
+
+// ReportQuery requests spending of state by period, empty currency is currency of state
+type ReportQuery struct {
+	StateId  int
+	Range    period.Range
+	Currency string
+	GroupBy  string
+}
+
+// ReportGroup is spending of category or member
+type ReportGroup struct {
+	Id    int
+	Title string
+	Sum   decimal.Decimal
+}
+
+// ReportData is spending of period in Currency grouped by category or member, largest groups first
+type ReportData struct {
+	From, To time.Time
+	Currency model.Currency
+	GroupBy  string
+	Total    decimal.Decimal
+	Groups   []ReportGroup
+}
+
 type Report struct {
 	reposSpend repository.Spending
 	reposCat   repository.Categories
+	reposState state.Client
+	reposCurr  currency.Client
 	rates      rates.Client
 	grpcClient api.SpendingClient
+	forecaster *Forecaster
+	goals      *GoalTracker
 }
 
-func NewReport(reposSpending repository.Spending, reposCategories repository.Categories, rates rates.Client,
-	grpcClient api.SpendingClient) *Report {
+func NewReport(reposSpending repository.Spending, reposCategories repository.Categories,
+	reposRecurring recurring.Client, reposState state.Client, reposCurrencies currency.Client, reposGoal goal.Client,
+	reposBudget budget.Client, rates rates.Client, grpcClient api.SpendingClient) *Report {
 	return &Report{
 		reposSpend: reposSpending,
 		reposCat:   reposCategories,
+		reposState: reposState,
+		reposCurr:  reposCurrencies,
 		rates:      rates,
 		grpcClient: grpcClient,
+		forecaster: NewForecaster(reposSpending, reposRecurring, rates),
+		goals:      NewGoalTracker(reposGoal, reposBudget, reposSpending, rates),
 	}
 }
 
-func (r *Report) Build(ctx context.Context, f1, f2 time.Time, userCurr model.Currency, chatId int64) (err error) {
+func (r *Report) Build(ctx context.Context, req kafka.Report) (err error) {
+	ctx = i18n.WithPrinter(ctx, i18n.NewPrinter(i18n.Lang(req.Language)))
+	pr := i18n.FromContext(ctx)
+	f1, f2, userCurr, chatId := req.F1, req.F2, req.UserCurr, req.ChatId
 	report := ""
-	m, err := r.reposSpend.Report(ctx, f1, f2, r.rates, userCurr)
+	m, err := r.reposSpend.Report(ctx, req.StateId, f1, f2, r.rates, userCurr)
 	if err != nil {
 		return
 	}
@@ -64,11 +121,43 @@ func (r *Report) Build(ctx context.Context, f1, f2 time.Time, userCurr model.Cur
 	}
 	for _, category := range categories {
 		if sum, ok := m[category.Id]; ok {
-			report += fmt.Sprintf("_%s_ - %.2f %s\n", category.Title, sum, userCurrAbbr)
+			report += pr.Sprintf("_%s_ - %.2f %s\n", category.Title, sum, userCurrAbbr)
+		}
+	}
+
+	// shared ledger of group is broken down by members
+	members, err := r.reposSpend.ReportByMember(ctx, req.StateId, f1, f2, r.rates, userCurr)
+	if err != nil {
+		return errors.Wrap(err, "report by member")
+	}
+	if len(members) > 1 {
+		report += "\n" + pr.Sprintf("*By member:*") + "\n"
+		for _, member := range members {
+			name := member.Name
+			if member.UserId == 0 {
+				name = pr.Sprintf("recurring and other")
+			}
+			report += pr.Sprintf("_%s_ - %.2f %s\n", name, member.Sum, userCurrAbbr)
+		}
+	}
+
+	if now := time.Now(); req.Forecast && req.StateId > 0 && now.After(f1) && now.Before(f2) {
+		forecastReport, err := r.forecast(ctx, req.StateId, userCurr, categories, now)
+		if err != nil {
+			return errors.Wrap(err, "report forecast")
+		}
+		report += forecastReport
+	}
+
+	if req.StateId > 0 {
+		goalsReport, err := r.goalsReport(ctx, req.StateId, userCurr)
+		if err != nil {
+			return errors.Wrap(err, "report goals")
 		}
+		report += goalsReport
 	}
 
-	_, err = r.grpcClient.SendReport(ctx, &apiReport.Report{
+	_, err = r.grpcClient.SendReport(i18n.ToMetadata(ctx, pr.Lang()), &apiReport.Report{
 		F1:     timestamppb.New(f1),
 		F2:     timestamppb.New(f2),
 		ChatId: chatId,
@@ -77,6 +166,7 @@ func (r *Report) Build(ctx context.Context, f1, f2 time.Time, userCurr model.Cur
 			Abbr: userCurr.Abbr,
 		},
 		Message: report,
+		StateId: int64(req.StateId),
 	})
 	if err != nil {
 		return errors.Wrap(err, "could not greet build")
@@ -87,19 +177,142 @@ func (r *Report) Build(ctx context.Context, f1, f2 time.Time, userCurr model.Cur
 	return
 }
 
-func (s *Service) Report7(ctx context.Context, update tgbotapi.Update) (err error) {
-	now := time.Now()
-	f1 := now.UTC()
-	if now.Weekday() < 1 {
-		f1 = now.AddDate(0, 0, -6)
-	} else if now.Weekday() > 1 {
-		f1 = now.AddDate(0, 0, int(-now.Weekday())+1)
+// Query returns aggregates of report for api, the same cached sums Build sends to telegram
+func (r *Report) Query(ctx context.Context, q ReportQuery) (data ReportData, err error) {
+	if !r.rates.IsLoaded(ctx) {
+		return data, RatesNotLoadedError
+	}
+	st, err := r.reposState.GetById(ctx, q.StateId)
+	if err != nil {
+		return data, errors.Wrap(err, "report state")
+	}
+	curr, err := st.GetCurrency(ctx)
+	if err != nil {
+		return data, errors.Wrap(err, "report currency")
+	}
+	if q.Currency != "" {
+		if curr, err = r.reposCurr.GetByAbbr(ctx, strings.ToUpper(q.Currency)); err != nil {
+			return data, errors.Wrap(InvalidReportError, fmt.Sprintf("unknown currency '%s'", q.Currency))
+		}
+	}
+	if q.Range.Period == "" {
+		q.Range = period.Range{Period: period.PeriodMonth}
+	}
+	if err = q.Range.Validate(); err != nil {
+		return data, errors.Wrap(InvalidReportError, err.Error())
+	}
+
+	data.From, data.To = q.Range.Window(time.Now().In(st.GetLocation(ctx)), st.GetStart(ctx))
+	data.Currency = curr
+	switch q.GroupBy {
+	case GroupByMember:
+		data.GroupBy = GroupByMember
+		members, err := r.reposSpend.ReportByMember(ctx, st.Id, data.From, data.To, r.rates, curr)
+		if err != nil {
+			return data, errors.Wrap(err, "report by member")
+		}
+		for _, member := range members {
+			name := member.Name
+			if member.UserId == 0 {
+				name = "recurring and other"
+			}
+			data.Groups = append(data.Groups, ReportGroup{Id: member.UserId, Title: name, Sum: member.Sum})
+		}
+	default:
+		data.GroupBy = GroupByCategory
+		m, err := r.reposSpend.Report(ctx, st.Id, data.From, data.To, r.rates, curr)
+		if err != nil {
+			return data, errors.Wrap(err, "report by category")
+		}
+		categories, err := r.reposCat.Categories(ctx)
+		if err != nil {
+			return data, errors.Wrap(err, "report categories")
+		}
+		for _, category := range categories {
+			if sum, ok := m[category.Id]; ok {
+				data.Groups = append(data.Groups, ReportGroup{Id: category.Id, Title: category.Title, Sum: sum})
+			}
+		}
 	}
-	f1 = time.Date(f1.Year(), f1.Month(), f1.Day(), 0, 0, 0, 0, f1.Location())
-	f2 := f1.AddDate(0, 0, 6)
-	f2 = time.Date(f2.Year(), f2.Month(), f2.Day(), 23, 59, 59, 0, f2.Location())
 
-	err = s.buildReport(ctx, update, f1, f2)
+	sort.SliceStable(data.Groups, func(i, j int) bool {
+		return data.Groups[i].Sum > data.Groups[j].Sum
+	})
+	for _, g := range data.Groups {
+		data.Total += g.Sum
+	}
+
+	return data, nil
+}
+
+// forecast returns projected spending by the end of month by categories and in total, categories
+// projected over monthly limit of state are marked
+func (r *Report) forecast(ctx context.Context, stateId int, userCurr model.Currency, categories []model.Category,
+	t time.Time) (report string, err error) {
+	pr := i18n.FromContext(ctx)
+	st, err := r.reposState.GetById(ctx, stateId)
+	if err != nil {
+		return "", errors.Wrap(err, "forecast state")
+	}
+	projection, err := r.forecaster.Forecast(ctx, stateId, userCurr, period.Range{Period: period.PeriodMonth},
+		st.GetStart(ctx), t.In(st.GetLocation(ctx)))
+	if err != nil {
+		return "", err
+	}
+	if len(projection) == 0 {
+		return
+	}
+	limits, err := st.GetLimits(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "forecast limits")
+	}
+	rate, ok := r.rates.GetRate(ctx, userCurr)
+	if !ok {
+		return "", errors.New("forecast rate not found")
+	}
+	monthLimits := make(map[int]decimal.Decimal)
+	for _, cl := range limits {
+		if cl.Range.Period == period.PeriodMonth && cl.Limit > 0 {
+			monthLimits[cl.Category.Id] = cl.Limit.Divide(rate.Rate)
+		}
+	}
+
+	report = "\n" + pr.Sprintf("*Forecast by the end of month:*") + "\n"
+	for _, category := range categories {
+		sum, ok := projection[category.Id]
+		if !ok {
+			continue
+		}
+		report += pr.Sprintf("_%s_ - %.2f %s", category.Title, sum, userCurr.Abbr)
+		if limit, ok := monthLimits[category.Id]; ok && sum > limit {
+			report += pr.Sprintf(" - over limit *%.2f %s*", limit, userCurr.Abbr)
+		}
+		report += "\n"
+	}
+	report += pr.Sprintf("*Total* - %.2f %s\n", forecast.Total(projection), userCurr.Abbr)
+
+	return
+}
+
+// goalsReport returns progress of goals of state
+func (r *Report) goalsReport(ctx context.Context, stateId int, userCurr model.Currency) (string, error) {
+	st, err := r.reposState.GetById(ctx, stateId)
+	if err != nil {
+		return "", errors.Wrap(err, "goals state")
+	}
+	progress, err := r.goals.Progress(ctx, st, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if len(progress) == 0 {
+		return "", nil
+	}
+
+	return "\n" + i18n.FromContext(ctx).Sprintf("*Goals:*") + "\n" + goalsMessage(ctx, progress, r.rates, userCurr), nil
+}
+
+func (s *Service) Report7(ctx context.Context, update tgbotapi.Update) (err error) {
+	err = s.buildReport(ctx, update, period.PeriodWeek, false)
 	if err != nil {
 		return errors.Wrap(err, "build report 7")
 	}
@@ -108,11 +321,7 @@ func (s *Service) Report7(ctx context.Context, update tgbotapi.Update) (err erro
 }
 
 func (s *Service) Report31(ctx context.Context, update tgbotapi.Update) (err error) {
-	now := time.Now()
-	f1 := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-	f2 := time.Date(f1.Year(), f1.Month()+1, 0, 23, 59, 59, 0, f1.Location())
-
-	err = s.buildReport(ctx, update, f1, f2)
+	err = s.buildReport(ctx, update, period.PeriodMonth, true)
 	if err != nil {
 		return errors.Wrap(err, "build report 31")
 	}
@@ -121,12 +330,7 @@ func (s *Service) Report31(ctx context.Context, update tgbotapi.Update) (err err
 }
 
 func (s *Service) Report365(ctx context.Context, update tgbotapi.Update) (err error) {
-	now := time.Now()
-	f1 := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
-	f2 := f1.AddDate(1, 0, -1)
-	f2 = time.Date(f2.Year(), f2.Month(), f2.Day(), 23, 59, 59, f2.Nanosecond(), f2.Location())
-
-	err = s.buildReport(ctx, update, f1, f2)
+	err = s.buildReport(ctx, update, period.PeriodYear, false)
 	if err != nil {
 		return errors.Wrap(err, "build report 365")
 	}
@@ -134,7 +338,8 @@ func (s *Service) Report365(ctx context.Context, update tgbotapi.Update) (err er
 	return
 }
 
-func (s *Service) buildReport(ctx context.Context, update tgbotapi.Update, f1, f2 time.Time) error {
+// buildReport requests report by period with today in time zone of user beginning on week and month start of user
+func (s *Service) buildReport(ctx context.Context, update tgbotapi.Update, p period.Period, withForecast bool) error {
 	userCtx, err := user.FromContext(ctx)
 	if err != nil {
 		return errors.Wrap(err, "buildReport")
@@ -147,39 +352,53 @@ func (s *Service) buildReport(ctx context.Context, update tgbotapi.Update, f1, f
 	if err != nil {
 		return errors.Wrap(err, "buildReport")
 	}
+	f1, f2 := period.Range{Period: p}.Window(time.Now().In(userState.GetLocation(ctx)), userState.GetStart(ctx))
 
-	reportJson, err := json.Marshal(kafka.Report{
+	err = publishReport(s.kafkaProducer, kafka.Report{
 		F1:       f1,
 		F2:       f2,
 		ChatId:   update.Message.Chat.ID,
 		UserCurr: userCurrency,
+		StateId:  userState.Id,
+		Forecast: withForecast,
+		Language: string(i18n.FromContext(ctx).Lang()),
 	})
 	if err != nil {
 		return err
 	}
 
+	reportTotal.WithLabelValues(fmt.Sprintf("%.0f", f2.Sub(f1).Hours()/24)).Inc()
+
+	return nil
+}
+
+// publishReport sends report request to the report service
+func publishReport(kafkaProducer sarama.AsyncProducer, report kafka.Report) error {
+	reportJson, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
 	msgReport := sarama.ProducerMessage{
 		Topic: kafka.TopicReport,
 		Key:   sarama.StringEncoder("report"),
 		Value: sarama.StringEncoder(reportJson),
 	}
 
-	s.kafkaProducer.Input() <- &msgReport
-
-	reportTotal.WithLabelValues(fmt.Sprintf("%.0f", f2.Sub(f1).Hours()/24)).Inc()
+	kafkaProducer.Input() <- &msgReport
 
 	return nil
 }
 
-func (s *Service) SendReport(ctx context.Context, report string, f1, f2 time.Time, chatId int64) (err error) {
-	_ = ctx
+func (s *Service) SendReport(ctx context.Context, report string, f1, f2 time.Time, chatId int64,
+	stateId int) (err error) {
+	pr := i18n.FromContext(ctx)
 
 	r := ""
-	f := "2 Jan 06"
 	if report == "" {
-		r = fmt.Sprintf("Report by week (*%s - %s*): spending not found", f1.Format(f), f2.Format(f))
+		r = pr.Sprintf("Report by week (*%s - %s*): spending not found", pr.Date(f1), pr.Date(f2))
 	} else {
-		r = fmt.Sprintf("Report by week (*%s - %s*):\n", f1.Format(f), f2.Format(f)) + report
+		r = pr.Sprintf("Report by week (*%s - %s*):", pr.Date(f1), pr.Date(f2)) + "\n" + report
 	}
 
 	err = s.client.SendMessage(r, chatId)
@@ -189,5 +408,13 @@ func (s *Service) SendReport(ctx context.Context, report string, f1, f2 time.Tim
 
 	logger.Infos("report sent to telegram:", r, chatId)
 
+	if stateId > 0 {
+		s.webhooks.Notify(stateId, model.WebhookReportGenerated, WebhookReport{
+			From:    f1.Format("2006-01-02"),
+			To:      f2.Format("2006-01-02"),
+			Message: report,
+		})
+	}
+
 	return nil
 }
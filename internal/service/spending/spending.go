@@ -8,12 +8,28 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/account"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/budget"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/category_limit"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/chat"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/currency"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/debt"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/digest"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/goal"
 	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/rates"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/recurring"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/state"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/token"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/webhook"
+	"github.com/sku4/ozon-route256-spending-bot/internal/service/dialog"
 	"github.com/sku4/ozon-route256-spending-bot/model"
 	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/session"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/user"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -25,19 +41,32 @@ type Service struct {
 	reposSpend    repository.Spending
 	reposCurr     currency.Client
 	reposCat      repository.Categories
+	reposAcc      account.Client
+	reposRec      recurring.Client
+	reposDigest   digest.Client
+	reposState    state.Client
+	reposBudget   budget.Client
+	reposChat     chat.Client
+	reposDebt     debt.Client
+	reposGoal     goal.Client
+	reposToken    token.Client
+	reposWebhook  webhook.Client
 	client        client.BotClient
 	rates         rates.Client
 	kafkaProducer sarama.AsyncProducer
+	forecaster    *Forecaster
+	goals         *GoalTracker
+	webhooks      *WebhookNotifier
+	dialogs       *dialog.Dialogs
 }
 
 type Event struct {
-	Price         float64 `json:"Price"`
-	CategoryId    int     `json:"CategoryId"`
-	D             int     `json:"D"`
-	M             int     `json:"M"`
-	Y             int     `json:"Y"`
-	Today         bool    `json:"Today"`
-	SelectedToday bool    `json:"SelectedToday"`
+	Price      float64 `json:"Price"`
+	CategoryId int     `json:"CategoryId"`
+	AccountId  int     `json:"AccountId"`
+	D          int     `json:"D"`
+	M          int     `json:"M"`
+	Y          int     `json:"Y"`
 }
 
 func NewEvent(price float64) *Event {
@@ -47,36 +76,48 @@ func NewEvent(price float64) *Event {
 		M:          -1,
 		Y:          -1,
 		CategoryId: -1,
+		AccountId:  -1,
 	}
 }
 
-func NewService(reposSpending repository.Spending, reposCategories repository.Categories, reposCurrencies currency.Client, client client.BotClient,
-	rates rates.Client, kafkaProducer sarama.AsyncProducer) *Service {
-	return &Service{
+func NewService(reposSpending repository.Spending, reposCategories repository.Categories, reposCurrencies currency.Client,
+	reposAccounts account.Client, reposRecurring recurring.Client, reposDigest digest.Client, reposState state.Client,
+	reposBudget budget.Client, reposChat chat.Client, reposDebt debt.Client, reposGoal goal.Client, reposToken token.Client,
+	reposWebhook webhook.Client, sessions session.Store, client client.BotClient, rates rates.Client,
+	kafkaProducer sarama.AsyncProducer) *Service {
+	s := &Service{
 		reposCat:      reposCategories,
 		reposSpend:    reposSpending,
 		reposCurr:     reposCurrencies,
+		reposAcc:      reposAccounts,
+		reposRec:      reposRecurring,
+		reposDigest:   reposDigest,
+		reposState:    reposState,
+		reposBudget:   reposBudget,
+		reposChat:     reposChat,
+		reposDebt:     reposDebt,
+		reposGoal:     reposGoal,
+		reposToken:    reposToken,
+		reposWebhook:  reposWebhook,
 		client:        client,
 		rates:         rates,
 		kafkaProducer: kafkaProducer,
+		forecaster:    NewForecaster(reposSpending, reposRecurring, rates),
+		goals:         NewGoalTracker(reposGoal, reposBudget, reposSpending, rates),
+		webhooks:      NewWebhookNotifier(reposWebhook, newWebhookSender()),
+		dialogs:       dialog.New(client, sessions),
 	}
+	s.registerDialogs()
+
+	return s
 }
 
 const AddPrefix = "spendingadd_"
 
-func (s *Service) Start(ctx context.Context, update tgbotapi.Update) (err error) {
-	_ = ctx
-
-	msg := "Available commands:\n" +
-		"/categories\n" +
-		"`/categoryadd Food` _- where Food is category name_\n" +
-		"`/spendingadd 100` _- where 100 is price_\n" +
-		"/report7 _- report by current week_\n" +
-		"/report31 _- report by current month_\n" +
-		"/report365 _- report by current year_\n" +
-		"/currency _- change currency_\n" +
-		"`/limit 100` _- limit category by sum spending on month_"
-	err = s.client.SendMessage(msg, update.Message.Chat.ID)
+// Help sends commands of bot listed by router
+func (s *Service) Help(ctx context.Context, update tgbotapi.Update, commands string) (err error) {
+	err = s.client.SendMessage(i18n.FromContext(ctx).Sprintf("Available commands:")+"\n"+commands,
+		update.Message.Chat.ID)
 	if err != nil {
 		return err
 	}
@@ -87,7 +128,16 @@ func (s *Service) Start(ctx context.Context, update tgbotapi.Update) (err error)
 func (s *Service) ErrorMessage(ctx context.Context, update tgbotapi.Update, message string) (err error) {
 	_ = ctx
 
-	err = s.client.SendMessage(message, update.Message.Chat.ID)
+	var chatId int64
+	switch {
+	case update.Message != nil:
+		chatId = update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		chatId = update.CallbackQuery.Message.Chat.ID
+	default:
+		return errors.New("update without chat")
+	}
+	err = s.client.SendMessage(message, chatId)
 	if err != nil {
 		return err
 	}
@@ -96,9 +146,7 @@ func (s *Service) ErrorMessage(ctx context.Context, update tgbotapi.Update, mess
 }
 
 func (s *Service) NotFound(ctx context.Context, update tgbotapi.Update) (err error) {
-	_ = ctx
-
-	err = s.client.SendMessage("Command not found", update.Message.Chat.ID)
+	err = s.client.SendMessage(i18n.FromContext(ctx).Sprintf("Command not found"), update.Message.Chat.ID)
 	if err != nil {
 		return err
 	}
@@ -106,258 +154,308 @@ func (s *Service) NotFound(ctx context.Context, update tgbotapi.Update) (err err
 	return
 }
 
-func (s *Service) SpendingAdd(ctx context.Context, update tgbotapi.Update) (err error) {
+// spendingAddFlow adds event by `/spendingadd 100`: category, account when state has accounts and date
+func (s *Service) spendingAddFlow() *dialog.Flow[Event] {
+	return &dialog.Flow[Event]{
+		Command: "spendingadd",
+		Prefix:  AddPrefix,
+		Start:   s.spendingAddStart,
+		States: map[string]dialog.State[Event]{
+			"category": {Prompt: s.spendingAddCategory},
+			"account":  {Prompt: s.spendingAddAccount},
+			"date":     {Prompt: s.spendingAddDate, Input: s.spendingAddDateInput},
+			"year":     {Prompt: s.spendingAddYear},
+			"month":    {Prompt: s.spendingAddMonth},
+			"day":      {Prompt: s.spendingAddDay},
+			"save":     {Prompt: s.spendingAddSave, Final: true},
+		},
+	}
+}
+
+func (s *Service) spendingAddStart(ctx context.Context, update tgbotapi.Update) (string, Event, error) {
 	if !s.rates.IsLoaded(ctx) {
-		_ = s.client.SendMessage("Rates not loaded, please repeat later", update.Message.Chat.ID)
-		return errors.New("rates still not loaded")
+		return "", Event{}, dialog.Errorf("Rates not loaded, please repeat later")
 	}
 
 	priceArg := update.Message.CommandArguments()
 	price, err := strconv.ParseFloat(priceArg, 64)
 	if err != nil {
-		_ = s.client.SendMessage(fmt.Sprintf(
-			"Error convert price '*%s*'", priceArg), update.Message.Chat.ID)
-		return errors.Wrap(err, "convert price")
+		return "", Event{}, dialog.Errorf("Error convert price '*%s*'", priceArg)
 	}
 	if price <= 0 {
-		_ = s.client.SendMessage("Please set price over 0", update.Message.Chat.ID)
-		return errors.New("Price less than 0")
+		return "", Event{}, dialog.Errorf("Please set price over 0")
 	}
 
-	userCtx, err := user.FromContext(ctx)
-	if err != nil {
-		_ = s.client.SendMessage(fmt.Sprintf(
-			"User not found: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
-		return errors.Wrap(err, "user not found")
-	}
-	uState, err := userCtx.GetState(ctx)
-	if err != nil {
-		_ = s.client.SendMessage(fmt.Sprintf(
-			"State not found: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
-		return errors.Wrap(err, "state not found")
-	}
-	uCurrency, err := uState.GetCurrency(ctx)
+	return "category", *NewEvent(price), nil
+}
+
+func (s *Service) spendingAddCategory(ctx context.Context, e Event) (p dialog.Prompt[Event], err error) {
+	uState, uCurrency, err := s.userCurrency(ctx)
 	if err != nil {
-		_ = s.client.SendMessage(fmt.Sprintf(
-			"Currency not found: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
-		return errors.Wrap(err, "currency not found")
+		return p, err
 	}
-	userCurrAbbr := uCurrency.Abbr
-
-	var inlineKeyboardRows []*client.KeyboardRow
-	inlineKeyboardRow := client.NewKeyboardRow()
-	event := NewEvent(price)
 	categories, err := s.reposCat.Categories(ctx)
 	if err != nil {
-		return errors.Wrap(err, "event add categories")
+		return p, errors.Wrap(err, "event add categories")
 	}
 	if len(categories) == 0 {
-		_ = s.client.SendMessage("Categories list is empty, please add /categories", update.Message.Chat.ID)
-		return errors.New("Categories list is empty")
+		return p, dialog.Errorf("Categories list is empty, please add /categories")
 	}
-	for _, category := range categories {
-		event.CategoryId = category.Id
-		eventSer := EventSerialize(event)
-		inlineKeyboardRow.Add(category.Title, AddPrefix+string(eventSer))
+	accounts, err := s.reposAcc.Accounts(ctx, uState.Id)
+	if err != nil {
+		return p, errors.Wrap(err, "event add accounts")
+	}
+	next := "date"
+	if len(accounts) > 0 {
+		next = "account"
 	}
-	inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
 
-	err = s.client.SendInlineKeyboard(inlineKeyboardRows,
-		fmt.Sprintf("Choose category (*%.2f %s*):", price, userCurrAbbr), update.Message.Chat.ID)
-	if err != nil {
-		return err
+	row := make([]dialog.Button[Event], 0, len(categories))
+	for _, c := range categories {
+		e.CategoryId = c.Id
+		row = append(row, dialog.Button[Event]{Title: c.Title, Next: next, Data: e})
 	}
 
-	return
+	return dialog.Prompt[Event]{
+		Text: i18n.FromContext(ctx).Sprintf("Choose category (*%.2f %s*):", e.Price, uCurrency.Abbr),
+		Rows: [][]dialog.Button[Event]{row},
+	}, nil
 }
 
-func (s *Service) SpendingAddQuery(ctx context.Context, update tgbotapi.Update) (err error) {
-	if !s.rates.IsLoaded(ctx) {
-		_ = s.client.SendMessage("Rates not loaded, please repeat later", update.Message.Chat.ID)
-		return errors.New("rates still not loaded")
+// spendingAddAccount chooses account, last used goes first
+func (s *Service) spendingAddAccount(ctx context.Context, e Event) (p dialog.Prompt[Event], err error) {
+	uState, uCurrency, err := s.userCurrency(ctx)
+	if err != nil {
+		return p, err
+	}
+	category, err := s.reposCat.CategoryGetById(ctx, e.CategoryId)
+	if err != nil {
+		return p, errors.Wrap(err, "event add category")
+	}
+	accounts, err := s.reposAcc.Accounts(ctx, uState.Id)
+	if err != nil {
+		return p, errors.Wrap(err, "event add accounts")
+	}
+
+	pr := i18n.FromContext(ctx)
+	lastAccountId := uState.GetLastAccount(ctx)
+	sort.SliceStable(accounts, func(i, j int) bool {
+		return accounts[i].Id == lastAccountId && accounts[j].Id != lastAccountId
+	})
+	row := make([]dialog.Button[Event], 0, len(accounts)+1)
+	for _, a := range accounts {
+		title := a.Title
+		if a.Id == lastAccountId {
+			title = "✓ " + title
+		}
+		e.AccountId = a.Id
+		row = append(row, dialog.Button[Event]{Title: title, Next: "date", Data: e})
 	}
+	e.AccountId = 0
+	row = append(row, dialog.Button[Event]{Title: pr.Sprintf("Without account"), Next: "date", Data: e})
 
-	var inlineKeyboardRows []*client.KeyboardRow
-	inlineKeyboardRow := client.NewKeyboardRow()
+	return dialog.Prompt[Event]{
+		Text: pr.Sprintf("Choose account (*%.2f %s* > *%s*):", e.Price, uCurrency.Abbr, category.Title),
+		Rows: [][]dialog.Button[Event]{row},
+	}, nil
+}
 
-	eventSer := update.CallbackQuery.Data[len(AddPrefix):]
-	event, err := eventUnserialize(eventSer)
+func (s *Service) spendingAddDate(ctx context.Context, e Event) (p dialog.Prompt[Event], err error) {
+	uState, uCurrency, err := s.userCurrency(ctx)
 	if err != nil {
-		return errors.Wrap(err, "event unserialize")
+		return p, err
 	}
+	category, err := s.reposCat.CategoryGetById(ctx, e.CategoryId)
+	if err != nil {
+		return p, errors.Wrap(err, "event add category")
+	}
+
+	// today and dates of picker are in time zone of user
+	now := time.Now().In(uState.GetLocation(ctx))
+	today := e
+	today.D, today.M, today.Y = now.Day(), int(now.Month()), now.Year()
+
+	pr := i18n.FromContext(ctx)
+	return dialog.Prompt[Event]{
+		Text: pr.Sprintf("Choose date or write it like `%s` (*%.2f %s* > *%s*):",
+			now.Format("2006-01-02"), e.Price, uCurrency.Abbr, category.Title),
+		Rows: [][]dialog.Button[Event]{{
+			{Title: pr.Sprintf("Today"), Next: "save", Data: today},
+			{Title: pr.Sprintf("Choose date"), Next: "year", Data: e},
+		}},
+	}, nil
+}
 
-	var category model.Category
-	if event.CategoryId > -1 {
-		cs, err := s.reposCat.Categories(ctx)
-		if err != nil {
-			return errors.Wrap(err, "event add categories")
+// spendingAddDateInput reads date written by user: `2022-11-28`, `28.11.2022` or `28.11` of current year
+func (s *Service) spendingAddDateInput(ctx context.Context, e Event, text string) (string, Event, error) {
+	uState, _, err := s.userCurrency(ctx)
+	if err != nil {
+		return "", e, err
+	}
+	now := time.Now().In(uState.GetLocation(ctx))
+	text = strings.TrimSpace(text)
+	for _, layout := range []string{"2006-01-02", "02.01.2006", "2.1.2006", "02.01", "2.1"} {
+		t, errParse := time.ParseInLocation(layout, text, now.Location())
+		if errParse != nil {
+			continue
 		}
-		for _, c := range cs {
-			if c.Id == event.CategoryId {
-				category = c
-				break
-			}
+		if !strings.Contains(layout, "2006") {
+			t = time.Date(now.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location())
 		}
+		e.D, e.M, e.Y = t.Day(), int(t.Month()), t.Year()
+		return "save", e, nil
 	}
 
-	userCtx, err := user.FromContext(ctx)
+	return "", e, dialog.Errorf("Error date '*%s*', write it like `%s`", text, now.Format("2006-01-02"))
+}
+
+func (s *Service) spendingAddYear(ctx context.Context, e Event) (p dialog.Prompt[Event], err error) {
+	uState, uCurrency, err := s.userCurrency(ctx)
 	if err != nil {
-		_ = s.client.SendMessage(fmt.Sprintf(
-			"User not found: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
-		return errors.Wrap(err, "user not found")
+		return p, err
 	}
-	uState, err := userCtx.GetState(ctx)
+	category, err := s.reposCat.CategoryGetById(ctx, e.CategoryId)
 	if err != nil {
-		return errors.Wrap(err, "state not found")
+		return p, errors.Wrap(err, "event add category")
 	}
-	uCurrency, err := uState.GetCurrency(ctx)
+
+	now := time.Now().In(uState.GetLocation(ctx))
+	row := make([]dialog.Button[Event], 0, 3)
+	for _, year := range []int{now.Year() - 1, now.Year(), now.Year() + 1} {
+		e.Y = year
+		row = append(row, dialog.Button[Event]{Title: strconv.Itoa(year), Next: "month", Data: e})
+	}
+
+	return dialog.Prompt[Event]{
+		Text: i18n.FromContext(ctx).Sprintf("Choose years (*%.2f %s* > *%s*):", e.Price, uCurrency.Abbr,
+			category.Title),
+		Rows: [][]dialog.Button[Event]{row},
+	}, nil
+}
+
+func (s *Service) spendingAddMonth(ctx context.Context, e Event) (p dialog.Prompt[Event], err error) {
+	_, uCurrency, err := s.userCurrency(ctx)
 	if err != nil {
-		return errors.Wrap(err, "currency not found")
+		return p, err
+	}
+	category, err := s.reposCat.CategoryGetById(ctx, e.CategoryId)
+	if err != nil {
+		return p, errors.Wrap(err, "event add category")
 	}
-	userCurrAbbr := uCurrency.Abbr
 
-	now := time.Now().UTC()
-	if event.D > -1 {
-		// add event
-		userRate, ok := s.rates.GetRate(ctx, uCurrency)
-		if !ok {
-			_ = s.client.SendMessage(fmt.Sprintf(
-				"Rate not found: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
-			return errors.Wrap(err, "rate not found")
-		}
-		userRateDecimal := userRate.Rate
-		t := time.Date(event.Y, time.Month(event.M), event.D, 0, 0, 0, 0, now.Location())
-		_, err = s.reposSpend.AddEvent(ctx, event.CategoryId, t, decimal.ToDecimal(event.Price).Multiply(userRateDecimal))
-		if err != nil {
-			_ = s.client.SendMessage(fmt.Sprintf(
-				"Error add event: %s", err.Error()), update.CallbackQuery.Message.Chat.ID)
-			return errors.Wrap(err, "add event")
-		}
-		err = s.client.SendCallbackQuery(inlineKeyboardRows, fmt.Sprintf(
-			"Event with price *%v %s* on *%s* success added to *%s*\r\n"+
-				"Show /report7 /report31 /report365", event.Price, userCurrAbbr, t.Format("2 Jan 06"), category.Title),
-			update.CallbackQuery.Message.MessageID, update.CallbackQuery.Message.Chat.ID)
+	pr := i18n.FromContext(ctx)
+	rows := make([][]dialog.Button[Event], 2)
+	for i := 1; i <= 12; i++ {
+		e.M = i
+		rows[(i-1)/6] = append(rows[(i-1)/6], dialog.Button[Event]{
+			Title: pr.MonthShort(time.Month(i)),
+			Next:  "day",
+			Data:  e,
+		})
+	}
 
-		// notification by limit category
-		mess, err := s.checkLimitPrice(ctx, category)
-		if err != nil {
-			return errors.Wrap(err, "add event check limit price")
-		}
-		_ = s.client.SendMessage(mess, update.CallbackQuery.Message.Chat.ID)
-	} else if event.M > -1 {
-		// show days
-		firstMonth := time.Date(2006, time.Month(event.M), 1, 0, 0, 0, 0, time.Local)
-		msg := fmt.Sprintf("Choose days (*%.2f %s* > *%s* > *%d* > *%s*):",
-			event.Price, userCurrAbbr, category.Title, event.Y, firstMonth.Format("Jan"))
-		t := time.Date(event.Y, time.Month(event.M)+1, 0, 0, 0, 0, 0, time.Local)
-		countDays := t.Day()
-		for i := 1; i <= countDays; i++ {
-			event.D = i
-			eventSer = EventSerialize(event)
-			inlineKeyboardRow.Add(strconv.Itoa(i), AddPrefix+string(eventSer))
-		}
+	return dialog.Prompt[Event]{
+		Text: pr.Sprintf("Choose months (*%.2f %s* > *%s* > *%d*):",
+			e.Price, uCurrency.Abbr, category.Title, e.Y),
+		Rows: rows,
+	}, nil
+}
 
-		event.D = -1
-		event.M = -1
-		eventSer = EventSerialize(event)
-		inlineKeyboardRow2 := client.NewKeyboardRow()
-		inlineKeyboardRow2.Add("<< Back", AddPrefix+string(eventSer))
-		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow, inlineKeyboardRow2)
-		err = s.client.SendCallbackQuery(inlineKeyboardRows, msg,
-			update.CallbackQuery.Message.MessageID, update.CallbackQuery.Message.Chat.ID)
-	} else if event.Y > -1 {
-		// show months
-		firstMonth := time.Date(2006, 1, 1, 0, 0, 0, 0, time.Local)
-		msg := fmt.Sprintf("Choose months (*%.2f %s* > *%s* > *%d*):",
-			event.Price, userCurrAbbr, category.Title, event.Y)
-		for i := 1; i <= 12; i++ {
-			m := firstMonth.Format("Jan")
-			firstMonth = firstMonth.AddDate(0, 1, 0)
-			event.M = i
-			eventSer = EventSerialize(event)
-			inlineKeyboardRow.Add(m, AddPrefix+string(eventSer))
-			if i == 6 {
-				inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
-				inlineKeyboardRow = client.NewKeyboardRow()
-			}
-		}
+func (s *Service) spendingAddDay(ctx context.Context, e Event) (p dialog.Prompt[Event], err error) {
+	_, uCurrency, err := s.userCurrency(ctx)
+	if err != nil {
+		return p, err
+	}
+	category, err := s.reposCat.CategoryGetById(ctx, e.CategoryId)
+	if err != nil {
+		return p, errors.Wrap(err, "event add category")
+	}
 
-		event.M = -1
-		event.Y = -1
-		eventSer = EventSerialize(event)
-		inlineKeyboardRow2 := client.NewKeyboardRow()
-		inlineKeyboardRow2.Add("<< Back", AddPrefix+string(eventSer))
-		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow, inlineKeyboardRow2)
-		err = s.client.SendCallbackQuery(inlineKeyboardRows, msg,
-			update.CallbackQuery.Message.MessageID, update.CallbackQuery.Message.Chat.ID)
-	} else if event.SelectedToday {
-		// show years
-		msg := fmt.Sprintf("Choose years (*%.2f %s* > *%s*):", event.Price, userCurrAbbr, category.Title)
-		years := []int{now.Year() - 1, now.Year(), now.Year() + 1}
-		for _, year := range years {
-			event.Y = year
-			eventSer = EventSerialize(event)
-			inlineKeyboardRow.Add(strconv.Itoa(year), AddPrefix+string(eventSer))
-		}
+	pr := i18n.FromContext(ctx)
+	countDays := time.Date(e.Y, time.Month(e.M)+1, 0, 0, 0, 0, 0, time.Local).Day()
+	row := make([]dialog.Button[Event], 0, countDays)
+	for i := 1; i <= countDays; i++ {
+		e.D = i
+		row = append(row, dialog.Button[Event]{Title: strconv.Itoa(i), Next: "save", Data: e})
+	}
+
+	return dialog.Prompt[Event]{
+		Text: pr.Sprintf("Choose days (*%.2f %s* > *%s* > *%d* > *%s*):",
+			e.Price, uCurrency.Abbr, category.Title, e.Y, pr.MonthShort(time.Month(e.M))),
+		Rows: [][]dialog.Button[Event]{row},
+	}, nil
+}
 
-		event.Y = -1
-		event.SelectedToday = false
-		eventSer = EventSerialize(event)
-		inlineKeyboardRow2 := client.NewKeyboardRow()
-		inlineKeyboardRow2.Add("<< Back", AddPrefix+string(eventSer))
-		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow, inlineKeyboardRow2)
-		err = s.client.SendCallbackQuery(inlineKeyboardRows, msg,
-			update.CallbackQuery.Message.MessageID, update.CallbackQuery.Message.Chat.ID)
-	} else if event.CategoryId > -1 {
-		// choose date
-		msg := fmt.Sprintf("Choose date (*%.2f %s* > *%s*):", event.Price, userCurrAbbr, category.Title)
-		event.Today = true
-		event.SelectedToday = true
-		event.D = now.Day()
-		event.M = int(now.Month())
-		event.Y = now.Year()
-		eventSer = EventSerialize(event)
-		inlineKeyboardRow.Add("Today", AddPrefix+string(eventSer))
-		event.Today = false
-		event.D = -1
-		event.M = -1
-		event.Y = -1
-		eventSer = EventSerialize(event)
-		inlineKeyboardRow.Add("Choose date", AddPrefix+string(eventSer))
-		event.SelectedToday = false
-		event.CategoryId = -1
-		eventSer = EventSerialize(event)
-		inlineKeyboardRow2 := client.NewKeyboardRow()
-		inlineKeyboardRow2.Add("<< Back", AddPrefix+string(eventSer))
-		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow, inlineKeyboardRow2)
-		err = s.client.SendCallbackQuery(inlineKeyboardRows, msg,
-			update.CallbackQuery.Message.MessageID, update.CallbackQuery.Message.Chat.ID)
-	} else if event.Price > 0 {
-		// show categories
-		msg := fmt.Sprintf("Choose category (*%.2f %s*):", event.Price, userCurrAbbr)
-		categories, err := s.reposCat.Categories(ctx)
+func (s *Service) spendingAddSave(ctx context.Context, e Event) (p dialog.Prompt[Event], err error) {
+	if !s.rates.IsLoaded(ctx) {
+		return p, dialog.Errorf("Rates not loaded, please repeat later")
+	}
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return p, errors.Wrap(err, "user not found")
+	}
+	uState, uCurrency, err := s.userCurrency(ctx)
+	if err != nil {
+		return p, err
+	}
+	userCurrAbbr := uCurrency.Abbr
+	category, err := s.reposCat.CategoryGetById(ctx, e.CategoryId)
+	if err != nil {
+		return p, errors.Wrap(err, "event add category")
+	}
+	var accountTitle string
+	if e.AccountId > 0 {
+		account, err := s.reposAcc.GetById(ctx, uState.Id, e.AccountId)
 		if err != nil {
-			return errors.Wrap(err, "event add categories")
-		}
-		if len(categories) == 0 {
-			_ = s.client.SendMessage(
-				"Categories list is empty, please add /categories", update.CallbackQuery.Message.Chat.ID)
-			return errors.New("Categories list is empty")
+			return p, errors.Wrap(err, "event add account")
 		}
-		for _, c := range categories {
-			event.CategoryId = c.Id
-			eventSer = EventSerialize(event)
-			inlineKeyboardRow.Add(c.Title, AddPrefix+string(eventSer))
-		}
-		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
-		err = s.client.SendCallbackQuery(inlineKeyboardRows, msg,
-			update.CallbackQuery.Message.MessageID, update.CallbackQuery.Message.Chat.ID)
-		if err != nil {
-			return errors.Wrap(err, "send callback query")
+		accountTitle = account.Title
+	}
+
+	userRate, ok := s.rates.GetRate(ctx, uCurrency)
+	if !ok {
+		return p, dialog.Errorf("Rate not found: %s", userCurrAbbr)
+	}
+	t := time.Date(e.Y, time.Month(e.M), e.D, 0, 0, 0, 0, uState.GetLocation(ctx))
+	eventId, err := s.reposSpend.AddEvent(ctx, uState.Id, userCtx.Id, e.CategoryId, e.AccountId, t,
+		decimal.ToDecimal(e.Price).Multiply(userRate.Rate))
+	if err != nil {
+		return p, dialog.Errorf("Error add event: %s", err.Error())
+	}
+	s.webhooks.Notify(uState.Id, model.WebhookSpendingAdded, WebhookSpending{
+		EventId:       eventId,
+		CategoryId:    category.Id,
+		CategoryTitle: category.Title,
+		Price:         e.Price,
+		Currency:      userCurrAbbr,
+		Date:          t.Format("2006-01-02"),
+		UserId:        userCtx.Id,
+		AccountId:     e.AccountId,
+	})
+	pr := i18n.FromContext(ctx)
+	accountMess := ""
+	if accountTitle != "" {
+		accountMess = pr.Sprintf(" from *%s*", accountTitle)
+		if err = uState.SetLastAccount(ctx, e.AccountId); err != nil {
+			return p, errors.Wrap(err, "add event set last account")
 		}
 	}
+	// remaining budget and notification by limit category
+	remain, mess, err := s.checkLimitPrice(ctx, *category)
+	if err != nil {
+		return p, errors.Wrap(err, "add event check limit price")
+	}
+	if remain != "" {
+		remain += "\r\n"
+	}
+	p.Text = pr.Sprintf("Event with price *%v %s* on *%s* success added to *%s*%s\r\n%s"+
+		"Show /report7 /report31 /report365", e.Price, userCurrAbbr, pr.Date(t),
+		category.Title, accountMess, remain)
+	if mess != "" {
+		p.Notes = append(p.Notes, mess)
+	}
 
-	return
+	return p, nil
 }
 
 func (s *Service) GetRateUserFloat(ctx context.Context) (r decimal.Decimal, err error) {
@@ -390,118 +488,160 @@ func (s *Service) ConvertPrice(ctx context.Context, price decimal.Decimal) (f de
 	return userRateFloat64.Multiply(price), nil
 }
 
-func (s *Service) checkLimitPrice(ctx context.Context, category model.Category) (mess string, err error) {
+// limitRemain returns spending by category of limit in window f1 - f2 in default currency and remaining budget,
+// the sum is not cached, so it includes spending added just now
+func (s *Service) limitRemain(ctx context.Context, stateId int, categoryLimit *category_limit.CategoryLimit,
+	f1, f2 time.Time, rate decimal.Decimal, curr model.Currency) (sum decimal.Decimal, remain string, err error) {
+	sum, err = s.reposSpend.CategorySum(ctx, stateId, categoryLimit.Category.Id, f1, f2)
+	if err != nil {
+		return 0, "", errors.Wrap(err, "check limit price sum")
+	}
+
+	pr := i18n.FromContext(ctx)
+	limitUser := categoryLimit.Limit.Divide(rate)
+	if sum <= categoryLimit.Limit {
+		remain = pr.Sprintf("Remaining *%.2f %s* of *%.2f %s* per *%s*",
+			(categoryLimit.Limit - sum).Divide(rate), curr.Abbr,
+			limitUser, curr.Abbr, rangeTitle(pr, categoryLimit.Range))
+	} else {
+		remain = pr.Sprintf("Limit *%.2f %s* per *%s* exceeded by *%.2f %s*",
+			limitUser, curr.Abbr, rangeTitle(pr, categoryLimit.Range),
+			(sum - categoryLimit.Limit).Divide(rate), curr.Abbr)
+	}
+
+	return
+}
+
+// rollingNoticeAt is period of notices of rolling limits
+var rollingNoticeAt = time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// checkLimitPrice returns remaining budget by category limit and warning about the highest reached
+// threshold, every threshold is warned once per period of limit, thresholds of rolling limit are warned
+// again after spending drops below them
+func (s *Service) checkLimitPrice(ctx context.Context, category model.Category) (remain, mess string, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "CheckLimitPrice")
 	defer span.Finish()
 
 	userCtx, err := user.FromContext(ctx)
 	if err != nil {
-		return "", errors.Wrap(err, "user not found")
+		return "", "", errors.Wrap(err, "user not found")
 	}
 	uState, err := userCtx.GetState(ctx)
 	if err != nil {
-		return "", errors.Wrap(err, "state not found")
+		return "", "", errors.Wrap(err, "state not found")
 	}
 	uCurrency, err := uState.GetCurrency(ctx)
 	if err != nil {
-		return "", errors.Wrap(err, "currency not found")
+		return "", "", errors.Wrap(err, "currency not found")
 	}
 
 	limits, err := uState.GetLimits(ctx)
 	if err != nil {
-		return "", errors.Wrap(err, "check limit price get limits")
+		return "", "", errors.Wrap(err, "check limit price get limits")
 	}
-	categoryLimit := decimal.Decimal(0)
+	var categoryLimit *category_limit.CategoryLimit
 	for _, limit := range limits {
 		if limit.Category.Id == category.Id {
-			categoryLimit = limit.Limit
+			categoryLimit = limit
 			break
 		}
 	}
-	if categoryLimit > 0 {
-		now := time.Now()
-		f1 := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-		f2 := time.Date(f1.Year(), f1.Month()+1, 0, 23, 59, 59, 0, f1.Location())
+	if categoryLimit == nil || categoryLimit.Limit <= 0 {
+		return
+	}
 
-		m, err := s.reposSpend.Report(ctx, f1, f2, s.rates, uCurrency)
+	f1, f2 := categoryLimit.Range.Window(time.Now().In(uState.GetLocation(ctx)), uState.GetStart(ctx))
+	userRateFloat64, err := s.GetRateUserFloat(ctx)
+	if err != nil {
+		return "", "", errors.Wrap(err, "convert price")
+	}
+	sum, remain, err := s.limitRemain(ctx, uState.Id, categoryLimit, f1, f2, userRateFloat64, uCurrency)
+	if err != nil {
+		return "", "", err
+	}
+	limitUser := categoryLimit.Limit.Divide(userRateFloat64)
+	pr := i18n.FromContext(ctx)
+
+	percent := int(int64(sum) * 100 / int64(categoryLimit.Limit))
+	noticeAt := f1
+	if categoryLimit.Range.Period == period.PeriodRolling {
+		// rolling window starts every day, so its thresholds are notified once
+		// until spending drops below them
+		noticeAt = rollingNoticeAt
+		if err = uState.ReleaseThresholds(ctx, categoryLimit.Id, percent, noticeAt); err != nil {
+			return "", "", errors.Wrap(err, "check limit price release thresholds")
+		}
+	}
+	reached := 0
+	for _, threshold := range uState.GetThresholds(ctx) {
+		if threshold > percent {
+			break
+		}
+		ok, err := uState.ClaimThreshold(ctx, categoryLimit.Id, threshold, noticeAt)
 		if err != nil {
-			return "", errors.Wrap(err, "check limit price report 31")
+			return "", "", errors.Wrap(err, "check limit price claim threshold")
+		}
+		if ok {
+			reached = threshold
 		}
-		if sum, ok := m[category.Id]; ok {
-			userRateFloat64, err := s.GetRateUserFloat(ctx)
-			if err != nil {
-				return "", errors.Wrap(err, "convert price")
-			}
-			sum = sum.Multiply(userRateFloat64)
-			if sum > categoryLimit {
-				span.SetTag("limitPrice", fmt.Sprintf("%s > %s", sum, categoryLimit))
-				mess = fmt.Sprintf("Sum *%.2f %s* by category *%s* over than *%.2f %s*",
-					sum.Divide(userRateFloat64), uCurrency.Abbr,
-					category.Title, categoryLimit.Divide(userRateFloat64), uCurrency.Abbr)
-			}
+	}
+	if reached == 0 {
+		if sum <= categoryLimit.Limit {
+			mess, err = s.limitForecast(ctx, uState, uCurrency, categoryLimit, f1, userRateFloat64)
 		}
+		return
+	}
+
+	span.SetTag("limitPrice", fmt.Sprintf("%s of %s reached %d%%", sum, categoryLimit.Limit, reached))
+	if sum > categoryLimit.Limit {
+		s.webhooks.Notify(uState.Id, model.WebhookLimitExceeded, WebhookLimit{
+			LimitId:       categoryLimit.Id,
+			CategoryId:    category.Id,
+			CategoryTitle: category.Title,
+			Limit:         limitUser.Float64(),
+			Spent:         sum.Divide(userRateFloat64).Float64(),
+			Currency:      uCurrency.Abbr,
+			Period:        categoryLimit.Range.String(),
+		})
+		mess = pr.Sprintf("Sum *%.2f %s* by category *%s* over than *%.2f %s* per *%s*",
+			sum.Divide(userRateFloat64), uCurrency.Abbr,
+			category.Title, limitUser, uCurrency.Abbr, rangeTitle(pr, categoryLimit.Range))
+	} else {
+		mess = pr.Sprintf("Spent *%d%%* of limit by category *%s*: *%.2f* of *%.2f %s* per *%s*",
+			percent, category.Title, sum.Divide(userRateFloat64), limitUser, uCurrency.Abbr,
+			rangeTitle(pr, categoryLimit.Range))
 	}
 
 	return
 }
 
-func EventSerialize(event *Event) string {
-	return strings.Join([]string{
-		strconv.FormatFloat(event.Price, 'f', 2, 64),
-		strconv.Itoa(event.CategoryId),
-		strconv.FormatBool(event.SelectedToday),
-		strconv.FormatBool(event.Today),
-		strconv.Itoa(event.D),
-		strconv.Itoa(event.M),
-		strconv.Itoa(event.Y),
-	}, "_")
-}
-
-func eventUnserialize(s string) (*Event, error) {
-	event := NewEvent(0)
-	args := strings.Split(s, "_")
-
-	price, err := strconv.ParseFloat(args[0], 64)
-	if err != nil {
-		return nil, err
+// limitForecast warns once per period when spending by category of limit is projected over limit
+// by the end of period
+func (s *Service) limitForecast(ctx context.Context, st *state.State, curr model.Currency,
+	cl *category_limit.CategoryLimit, periodAt time.Time, rate decimal.Decimal) (mess string, err error) {
+	if cl.Range.Period == period.PeriodRolling {
+		return
 	}
-	event.Price = price
-
-	categoryId, err := strconv.Atoi(args[1])
+	projection, err := s.forecaster.Forecast(ctx, st.Id, curr, cl.Range, st.GetStart(ctx),
+		time.Now().In(st.GetLocation(ctx)))
 	if err != nil {
-		return nil, err
+		return "", errors.Wrap(err, "limit forecast")
 	}
-	event.CategoryId = categoryId
-
-	selectedToday, err := strconv.ParseBool(args[2])
-	if err != nil {
-		return nil, err
+	projected, limitUser := projection[cl.Category.Id], cl.Limit.Divide(rate)
+	if projected <= limitUser {
+		return
 	}
-	event.SelectedToday = selectedToday
-
-	today, err := strconv.ParseBool(args[3])
+	ok, err := st.ClaimThreshold(ctx, cl.Id, forecastThreshold, periodAt)
 	if err != nil {
-		return nil, err
+		return "", errors.Wrap(err, "limit forecast claim")
 	}
-	event.Today = today
-
-	d, err := strconv.Atoi(args[4])
-	if err != nil {
-		return nil, err
-	}
-	event.D = d
-
-	m, err := strconv.Atoi(args[5])
-	if err != nil {
-		return nil, err
+	if !ok {
+		return
 	}
-	event.M = m
 
-	y, err := strconv.Atoi(args[6])
-	if err != nil {
-		return nil, err
-	}
-	event.Y = y
+	pr := i18n.FromContext(ctx)
 
-	return event, nil
+	return pr.Sprintf("Spending by category *%s* is projected to *%.2f %s* by the end of *%s*, "+
+		"over limit *%.2f %s*", cl.Category.Title, projected, curr.Abbr, rangeTitle(pr, cl.Range),
+		limitUser, curr.Abbr), nil
 }
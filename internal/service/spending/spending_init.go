@@ -15,6 +15,7 @@ import (
 	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/configtest"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/logger"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/session"
 	sqlmock "github.com/zhashkevych/go-sqlxmock"
 	"strings"
 )
@@ -49,7 +50,11 @@ func NewServiceTest(ctx context.Context) (st *ServiceTest, service *Service, moc
 	repos, _ := repository.NewRepository(st.DB)
 	reposCurrencies, _ := currency.NewCurrencies(st.DB)
 	ratesClient := st.initRates(repos)
-	st.Service = NewService(repos.Spending, repos.Categories, reposCurrencies, tgClient, ratesClient, kafkaProducer)
+	st.Service = NewService(repos.Spending, repos.Categories, reposCurrencies, repos.AccountClient,
+		repos.RecurringClient, repos.DigestClient, repos.StateClient,
+		repos.BudgetClient, repos.ChatClient, repos.DebtClient, repos.GoalClient, repos.TokenClient,
+		repos.WebhookClient, session.NewMemory(SessionTTL), tgClient, ratesClient,
+		kafkaProducer)
 
 	return st, st.Service, st.Mock, nil
 }
@@ -0,0 +1,64 @@
+//go:build integration
+// +build integration
+
+package spending
+
+import (
+	"context"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/category_limit"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/rates"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// ledgerEvents keeps prices of added events by category, report is cached before the first event
+type ledgerEvents struct {
+	repository.Spending
+	prices map[int]decimal.Decimal
+}
+
+func (l *ledgerEvents) AddEvent(_ context.Context, _, _, categoryId, _ int, _ time.Time,
+	price decimal.Decimal) (int, error) {
+	l.prices[categoryId] += price
+	return len(l.prices), nil
+}
+
+func (l *ledgerEvents) Report(context.Context, int, time.Time, time.Time, rates.Client,
+	model.Currency) (map[int]decimal.Decimal, error) {
+	return map[int]decimal.Decimal{}, nil
+}
+
+func (l *ledgerEvents) CategorySum(_ context.Context, _, categoryId int, _, _ time.Time) (decimal.Decimal, error) {
+	return l.prices[categoryId], nil
+}
+
+func TestService_limitRemain(t *testing.T) {
+	ctx := context.Background()
+	events := &ledgerEvents{prices: make(map[int]decimal.Decimal)}
+	s := &Service{reposSpend: events}
+	limit := &category_limit.CategoryLimit{
+		CategoryLimit: model.CategoryLimit{Id: 1, Category: &model.Category{Id: 3, Title: "Food"}},
+		Limit:         decimal.ToDecimal(1000),
+		Range:         period.Range{Period: period.PeriodMonth},
+	}
+	rate, curr := decimal.ToDecimal(1), model.Currency{Abbr: "RUB"}
+	f1, f2 := limit.Range.Window(time.Now(), period.Start{})
+
+	_, err := events.AddEvent(ctx, 5, 1, 3, 0, time.Now(), decimal.ToDecimal(100))
+	assert.NoError(t, err)
+	_, remain, err := s.limitRemain(ctx, 5, limit, f1, f2, rate, curr)
+	assert.NoError(t, err)
+	assert.Equal(t, "Remaining *900.00 RUB* of *1000.00 RUB* per *month*", remain)
+
+	_, err = events.AddEvent(ctx, 5, 1, 3, 0, time.Now(), decimal.ToDecimal(150))
+	assert.NoError(t, err)
+	sum, remain, err := s.limitRemain(ctx, 5, limit, f1, f2, rate, curr)
+	assert.NoError(t, err)
+	assert.Equal(t, decimal.ToDecimal(250), sum)
+	assert.Equal(t, "Remaining *750.00 RUB* of *1000.00 RUB* per *month*", remain)
+}
@@ -0,0 +1,385 @@
+package spending
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/debt"
+	spendingRepo "github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/spending"
+	"github.com/sku4/ozon-route256-spending-bot/internal/service/dialog"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/split"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/user"
+	"strconv"
+	"strings"
+)
+
+const settlePrefix = "settle_"
+
+// splitArgs is parsed `/split`, members share equally unless shares or exact amounts are set
+type splitArgs struct {
+	members  []model.Member
+	shares   []int
+	amounts  []float64
+	currency string
+}
+
+// Split splits the latest spending of member among members of group ledger: `/split` equally among all members,
+// `/split Anna Bob` equally among chosen, `/split Anna:2 Bob:1` by shares, `/split Anna=500 Bob=700 USD` by amounts
+func (s *Service) Split(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	if !s.rates.IsLoaded(ctx) {
+		_ = s.client.SendMessage(pr.Sprintf("Rates not loaded, please repeat later"), update.Message.Chat.ID)
+		return errors.New("rates still not loaded")
+	}
+	chatId := update.Message.Chat.ID
+	if update.Message.Chat.IsPrivate() {
+		_ = s.client.SendMessage(pr.Sprintf(
+			"Spending is split among members of group chat, add the bot to group"), chatId)
+		return errors.New("split in private chat")
+	}
+
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+	uCurrency, err := uState.GetCurrency(ctx)
+	if err != nil {
+		return errors.Wrap(err, "currency not found")
+	}
+
+	members, err := s.reposChat.Members(ctx, uState.Id)
+	if err != nil {
+		return errors.Wrap(err, "split members")
+	}
+	args, err := parseSplitArgs(strings.Fields(update.Message.CommandArguments()), members)
+	if err != nil {
+		_ = s.client.SendMessage(pr.Sprintf("%s, example `/split Anna Bob`, `/split Anna:2 Bob:1` "+
+			"or `/split Anna=500 Bob=700 USD`", errorText(pr, err)), chatId)
+		return errors.Wrap(err, "split args")
+	}
+
+	event, err := s.reposSpend.LastEvent(ctx, uState.Id, userCtx.Id)
+	if errors.Is(err, spendingRepo.NotFoundError) {
+		_ = s.client.SendMessage(pr.Sprintf("Your spending not found, please add by /spendingadd"), chatId)
+		return errors.Wrap(err, "split event")
+	}
+	if err != nil {
+		return errors.Wrap(err, "split event")
+	}
+
+	total := decimal.Decimal(event.Price)
+	var parts []decimal.Decimal
+	switch {
+	case args.amounts != nil:
+		parts, err = s.exactParts(ctx, total, args, uCurrency)
+		if err != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Spending not split: %s", errorText(pr, err)), chatId)
+			return errors.Wrap(err, "split exact")
+		}
+	case args.shares != nil:
+		parts = split.Shares(total, args.shares)
+	default:
+		parts = split.Equal(total, len(args.members))
+	}
+
+	shares := make(map[int]decimal.Decimal, len(parts))
+	for i, part := range parts {
+		shares[args.members[i].UserId] += part
+	}
+	err = s.reposDebt.Split(ctx, uState.Id, event.Id, userCtx.Id, shares)
+	if errors.Is(err, debt.AlreadySplitError) {
+		_ = s.client.SendMessage(pr.Sprintf("Your last spending is already split, show /balance"), chatId)
+		return errors.Wrap(err, "split")
+	}
+	if err != nil {
+		_ = s.client.SendMessage(pr.Sprintf("Spending not split: %s", err.Error()), chatId)
+		return errors.Wrap(err, "split")
+	}
+
+	rate, err := s.GetRateUserFloat(ctx)
+	if err != nil {
+		return errors.Wrap(err, "split rate")
+	}
+	msg := pr.Sprintf("Spending *%.2f %s* (_%s_, %s) split:", total.Divide(rate), uCurrency.Abbr,
+		event.Category.Title, pr.Date(event.Date)) + "\n"
+	for i, part := range parts {
+		msg += pr.Sprintf("_%s_ - %.2f %s\n", args.members[i].Name, part.Divide(rate), uCurrency.Abbr)
+	}
+	msg += pr.Sprintf("Show /balance or /settle")
+
+	return s.client.SendMessage(msg, chatId)
+}
+
+// exactParts converts exact amounts of members to default currency, amounts must sum to total
+// with tolerance of a cent per member, errors are shown to user
+func (s *Service) exactParts(ctx context.Context, total decimal.Decimal, args splitArgs,
+	uCurrency model.Currency) ([]decimal.Decimal, error) {
+	curr := uCurrency
+	if args.currency != "" {
+		c, err := s.reposCurr.GetByAbbr(ctx, strings.ToUpper(args.currency))
+		if err != nil {
+			return nil, dialog.Errorf("Currency *%s* not found", args.currency)
+		}
+		curr = c
+	}
+	rate, ok := s.rates.GetRate(ctx, curr)
+	if !ok {
+		return nil, dialog.Errorf("Rate *%s* not found", curr.Abbr)
+	}
+
+	var sum float64
+	amounts := make([]decimal.Decimal, len(args.amounts))
+	for i, amount := range args.amounts {
+		amounts[i] = decimal.ToDecimal(amount).Multiply(rate.Rate)
+		sum += amount
+	}
+
+	parts, err := split.Exact(total, amounts, decimal.ToDecimal(0.01*float64(len(amounts))).Multiply(rate.Rate))
+	if errors.Is(err, split.SumError) {
+		return nil, dialog.Errorf("Amounts sum to %.2f %s, spending is %.2f %s", sum, curr.Abbr,
+			total.Divide(rate.Rate), curr.Abbr)
+	}
+	if err != nil {
+		return nil, dialog.Errorf("Error amounts, set amounts from 0")
+	}
+
+	return parts, nil
+}
+
+// parseSplitArgs parses members of split by name or first name, all members share equally without arguments,
+// errors are shown to user
+func parseSplitArgs(fields []string, members []model.Member) (args splitArgs, err error) {
+	if len(members) == 0 {
+		return args, dialog.Errorf("Group members not found")
+	}
+	if len(fields) == 0 {
+		args.members = members
+		return
+	}
+
+	exact := strings.Contains(fields[0], "=")
+	byShares := strings.Contains(fields[0], ":")
+	if exact {
+		if last := fields[len(fields)-1]; !strings.Contains(last, "=") {
+			args.currency = last
+			fields = fields[:len(fields)-1]
+		}
+		args.amounts = []float64{}
+	}
+	if byShares {
+		args.shares = []int{}
+	}
+
+	seen := make(map[int]bool)
+	for _, field := range fields {
+		name, value, found := strings.Cut(field, "=")
+		if !exact {
+			name, value, found = strings.Cut(field, ":")
+		}
+		if found != (exact || byShares) {
+			return args, dialog.Errorf("Error argument '*%s*'", field)
+		}
+		member, ok := findMember(members, name)
+		if !ok {
+			return args, dialog.Errorf("Member '*%s*' not found", name)
+		}
+		if seen[member.UserId] {
+			return args, dialog.Errorf("Member '*%s*' is repeated", name)
+		}
+		seen[member.UserId] = true
+		args.members = append(args.members, member)
+
+		switch {
+		case exact:
+			amount, errConv := strconv.ParseFloat(value, 64)
+			if errConv != nil || amount < 0 {
+				return args, dialog.Errorf("Error amount '*%s*'", value)
+			}
+			args.amounts = append(args.amounts, amount)
+		case byShares:
+			share, errConv := strconv.Atoi(value)
+			if errConv != nil || share < 1 {
+				return args, dialog.Errorf("Error share '*%s*', set share from 1", value)
+			}
+			args.shares = append(args.shares, share)
+		}
+	}
+
+	return
+}
+
+// findMember finds member by name or first word of name ignoring case
+func findMember(members []model.Member, name string) (model.Member, bool) {
+	for _, member := range members {
+		if strings.EqualFold(member.Name, name) {
+			return member, true
+		}
+	}
+	for _, member := range members {
+		if first, _, _ := strings.Cut(member.Name, " "); strings.EqualFold(first, name) {
+			return member, true
+		}
+	}
+
+	return model.Member{}, false
+}
+
+// Settle shows minimal transfers which settle up debts of group ledger with buttons to record them
+func (s *Service) Settle(ctx context.Context, update tgbotapi.Update) (err error) {
+	chatId := update.Message.Chat.ID
+	msg, inlineKeyboardRows, err := s.settlePlan(ctx)
+	if err != nil {
+		return err
+	}
+	if len(inlineKeyboardRows) == 0 {
+		return s.client.SendMessage(msg, chatId)
+	}
+
+	return s.client.SendInlineKeyboard(inlineKeyboardRows, msg, chatId)
+}
+
+// SettleQuery records transfer of settle plan and shows the rest of plan
+func (s *Service) SettleQuery(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	chatId := update.CallbackQuery.Message.Chat.ID
+	messageId := update.CallbackQuery.Message.MessageID
+
+	args := strings.Split(update.CallbackQuery.Data[len(settlePrefix):], "_")
+	if len(args) != 3 {
+		return errors.New("settle callback data")
+	}
+	fromId, err := strconv.Atoi(args[0])
+	if err != nil {
+		return errors.Wrap(err, "settle from member")
+	}
+	toId, err := strconv.Atoi(args[1])
+	if err != nil {
+		return errors.Wrap(err, "settle to member")
+	}
+	amount, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "settle amount")
+	}
+
+	uState, err := stateFromContext(ctx)
+	if err != nil {
+		return
+	}
+	debts, err := s.reposDebt.Debts(ctx, uState.Id)
+	if err != nil {
+		return errors.Wrap(err, "settle debts")
+	}
+	// button of outdated plan is not recorded twice
+	transfer := split.Transfer{From: fromId, To: toId, Amount: decimal.Decimal(amount)}
+	planned := false
+	for _, t := range split.Settle(netBalances(debts)) {
+		if t == transfer {
+			planned = true
+		}
+	}
+	msg := pr.Sprintf("Transfer is outdated, settle plan is updated") + "\r\n"
+	if planned {
+		if err = s.reposDebt.Settle(ctx, uState.Id, fromId, toId, transfer.Amount); err != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Settlement not added: %s", err.Error()), chatId)
+			return errors.Wrap(err, "settle")
+		}
+		msg = pr.Sprintf("Settlement success added") + "\r\n"
+	}
+
+	plan, inlineKeyboardRows, err := s.settlePlan(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.client.SendCallbackQuery(inlineKeyboardRows, msg+plan, messageId, chatId)
+}
+
+// settlePlan returns minimal transfers of group ledger in currency of user and their buttons
+func (s *Service) settlePlan(ctx context.Context) (msg string, inlineKeyboardRows []*client.KeyboardRow, err error) {
+	pr := i18n.FromContext(ctx)
+	uState, err := stateFromContext(ctx)
+	if err != nil {
+		return
+	}
+	uCurrency, err := uState.GetCurrency(ctx)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "currency not found")
+	}
+	rate, err := s.GetRateUserFloat(ctx)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "settle rate")
+	}
+
+	debts, err := s.reposDebt.Debts(ctx, uState.Id)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "settle debts")
+	}
+	names := memberNames(debts)
+	transfers := split.Settle(netBalances(debts))
+	if len(transfers) == 0 {
+		return pr.Sprintf("All debts are settled up"), nil, nil
+	}
+
+	msg = pr.Sprintf("Transfers to settle up:") + "\n"
+	for _, t := range transfers {
+		msg += pr.Sprintf("_%s_ pays _%s_ - %.2f %s\n", names[t.From], names[t.To], t.Amount.Divide(rate),
+			uCurrency.Abbr)
+		inlineKeyboardRow := client.NewKeyboardRow()
+		inlineKeyboardRow.Add(pr.Sprintf("%s paid %s %.2f", names[t.From], names[t.To], t.Amount.Divide(rate)),
+			settlePrefix+strings.Join([]string{strconv.Itoa(t.From), strconv.Itoa(t.To),
+				strconv.FormatInt(t.Amount.Original(), 10)}, "_"))
+		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
+	}
+	msg += pr.Sprintf("Press transfer when it is paid")
+
+	return
+}
+
+// debtsMessage returns who owes whom in group ledger by minimal transfers in currency of user
+func (s *Service) debtsMessage(ctx context.Context, stateId int, rate decimal.Decimal,
+	uCurrency model.Currency) (msg string, err error) {
+	pr := i18n.FromContext(ctx)
+	debts, err := s.reposDebt.Debts(ctx, stateId)
+	if err != nil {
+		return "", errors.Wrap(err, "debts")
+	}
+	names := memberNames(debts)
+	for _, t := range split.Settle(netBalances(debts)) {
+		msg += pr.Sprintf("_%s_ owes _%s_ - %.2f %s\n", names[t.From], names[t.To], t.Amount.Divide(rate),
+			uCurrency.Abbr)
+	}
+	if msg != "" {
+		msg = pr.Sprintf("Who owes whom:") + "\n" + msg + pr.Sprintf("Settle up by /settle")
+	}
+
+	return
+}
+
+// netBalances returns net balance of every member, positive balance is owed to member
+func netBalances(debts []model.Debt) map[int]decimal.Decimal {
+	balances := make(map[int]decimal.Decimal)
+	for _, d := range debts {
+		balances[d.Debtor.UserId] -= decimal.Decimal(d.Amount)
+		balances[d.Creditor.UserId] += decimal.Decimal(d.Amount)
+	}
+
+	return balances
+}
+
+func memberNames(debts []model.Debt) map[int]string {
+	names := make(map[int]string)
+	for _, d := range debts {
+		names[d.Debtor.UserId] = d.Debtor.Name
+		names[d.Creditor.UserId] = d.Creditor.Name
+	}
+
+	return names
+}
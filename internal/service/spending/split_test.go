@@ -0,0 +1,27 @@
+//go:build integration
+// +build integration
+
+package spending
+
+import (
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseSplitArgs(t *testing.T) {
+	members := []model.Member{{UserId: 1, Name: "Anna Smith"}, {UserId: 2, Name: "Bob"}}
+	ru := i18n.NewPrinter(i18n.Ru)
+
+	args, err := parseSplitArgs([]string{"anna:2", "Bob:1"}, members)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 1}, args.shares)
+
+	_, err = parseSplitArgs([]string{"Zoe"}, members)
+	assert.Equal(t, "Участник '*Zoe*' не найден", errorText(ru, err))
+	_, err = parseSplitArgs([]string{"Anna=abc"}, members)
+	assert.Equal(t, "Ошибка суммы '*abc*'", errorText(ru, err))
+	_, err = parseSplitArgs(nil, nil)
+	assert.Equal(t, "Group members not found", errorText(i18n.NewPrinter(i18n.En), err))
+}
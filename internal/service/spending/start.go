@@ -0,0 +1,126 @@
+package spending
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/state"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/period"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/schedule"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WeekStart shows or sets weekday weeks begin on by `/weekstart sun`
+func (s *Service) WeekStart(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	uState, err := stateFromContext(ctx)
+	if err != nil {
+		return
+	}
+
+	start := uState.GetStart(ctx)
+	if arg := update.Message.CommandArguments(); arg != "" {
+		weekday, ok := schedule.ParseWeekday(arg)
+		if !ok {
+			_ = s.client.SendMessage(pr.Sprintf(
+				"Unknown weekday '*%s*', example `/weekstart sun`", arg), update.Message.Chat.ID)
+			return errors.New("week start weekday")
+		}
+		start.Weekday = weekday
+		if err = s.setStart(ctx, uState, start); err != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Week start not set: %s", err.Error()), update.Message.Chat.ID)
+			return errors.Wrap(err, "set week start")
+		}
+	}
+
+	f1, f2 := start.Week(time.Now().In(uState.GetLocation(ctx)))
+
+	return s.client.SendMessage(pr.Sprintf("Weeks begin on *%s*, current week *%s - %s*\r\n"+
+		"Change by `/weekstart sun`", pr.Weekday(start.Weekday), pr.Date(f1), pr.Date(f2)),
+		update.Message.Chat.ID)
+}
+
+// MonthStart shows or sets day months begin on by `/monthstart 10`, for example payday
+func (s *Service) MonthStart(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	uState, err := stateFromContext(ctx)
+	if err != nil {
+		return
+	}
+
+	start := uState.GetStart(ctx)
+	if arg := strings.TrimSpace(update.Message.CommandArguments()); arg != "" {
+		day, errConv := strconv.Atoi(arg)
+		if errConv != nil || day < 1 || day > period.MaxMonthDay {
+			_ = s.client.SendMessage(pr.Sprintf(
+				"Error day '*%s*', set day from 1 to %d", arg, period.MaxMonthDay), update.Message.Chat.ID)
+			return errors.New("month start day out of range")
+		}
+		start.MonthDay = day
+		if err = s.setStart(ctx, uState, start); err != nil {
+			_ = s.client.SendMessage(pr.Sprintf("Month start not set: %s", err.Error()), update.Message.Chat.ID)
+			return errors.Wrap(err, "set month start")
+		}
+	}
+
+	f1, f2 := start.Month(time.Now().In(uState.GetLocation(ctx)))
+
+	return s.client.SendMessage(pr.Sprintf("Months begin on day *%d*, current month *%s - %s*\r\n"+
+		"Change by `/monthstart 10`", start.MonthDay, pr.Date(f1), pr.Date(f2)),
+		update.Message.Chat.ID)
+}
+
+// setStart saves period start of state and moves digests of state to the last day of new periods
+func (s *Service) setStart(ctx context.Context, st *state.State, start period.Start) error {
+	if err := st.SetStart(ctx, start); err != nil {
+		return err
+	}
+
+	return s.rescheduleDigests(ctx, st)
+}
+
+// rescheduleDigests plans next digests of state by its current period start and time zone
+func (s *Service) rescheduleDigests(ctx context.Context, st *state.State) error {
+	digests, err := s.reposDigest.Digests(ctx, st.Id)
+	if err != nil {
+		return errors.Wrap(err, "digests")
+	}
+	start, now := st.GetStart(ctx), time.Now().In(st.GetLocation(ctx))
+	for _, dg := range digests {
+		dp, ok := digestPeriods[dg.Period]
+		if !ok {
+			continue
+		}
+		_, err = s.reposDigest.AddDigest(ctx, model.Digest{
+			StateId:  dg.StateId,
+			ChatId:   dg.ChatId,
+			Period:   dg.Period,
+			Clock:    dg.Clock,
+			NextAt:   dp.schedule(start).NextAt(now, time.Duration(dg.Clock)*time.Minute),
+			Language: dg.Language,
+		})
+		if err != nil {
+			return errors.Wrap(err, "reschedule digest")
+		}
+	}
+
+	return nil
+}
+
+func stateFromContext(ctx context.Context) (*state.State, error) {
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "state not found")
+	}
+
+	return uState, nil
+}
@@ -0,0 +1,48 @@
+package spending
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/timezone"
+	"strings"
+	"time"
+)
+
+// Timezone shows or sets time zone of user by `/timezone Europe/Moscow` or by shared location,
+// location gives zone of whole hours offset by longitude
+func (s *Service) Timezone(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	uState, err := stateFromContext(ctx)
+	if err != nil {
+		return
+	}
+
+	name := ""
+	if update.Message.Location != nil {
+		name = timezone.FromLongitude(update.Message.Location.Longitude)
+	} else {
+		name = strings.TrimSpace(update.Message.CommandArguments())
+	}
+	if name != "" {
+		if err = uState.SetTimezone(ctx, name); err != nil {
+			_ = s.client.SendMessage(pr.Sprintf(
+				"Unknown time zone '*%s*', example `/timezone Europe/Moscow`", name), update.Message.Chat.ID)
+			return errors.Wrap(err, "set timezone")
+		}
+		if err = s.rescheduleDigests(ctx, uState); err != nil {
+			return errors.Wrap(err, "timezone reschedule digests")
+		}
+	}
+
+	loc := uState.GetLocation(ctx)
+	title := loc.String()
+	if uState.Timezone == "" {
+		title += pr.Sprintf(" (server)")
+	}
+
+	return s.client.SendMessage(pr.Sprintf("Time zone *%s*, now *%s*\r\n"+
+		"Change by `/timezone Europe/Moscow` or share location", title, pr.DateTime(time.Now().In(loc))),
+		update.Message.Chat.ID)
+}
@@ -0,0 +1,145 @@
+package spending
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/auth"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/user"
+	"strconv"
+	"strings"
+)
+
+const (
+	tokenPrefix  = "token_"
+	maxTokenName = 100
+)
+
+// Token shows api tokens of user or issues new one: `/token Sheets` is read only token,
+// `/token Sheets write` also changes data. Token is shown once, only its hash is stored.
+// Router runs it in private chats only
+func (s *Service) Token(ctx context.Context, update tgbotapi.Update) (err error) {
+	chatId := update.Message.Chat.ID
+
+	fields := strings.Fields(update.Message.CommandArguments())
+	if len(fields) == 0 {
+		msg, inlineKeyboardRows, errList := s.tokenList(ctx)
+		if errList != nil {
+			return errList
+		}
+		if len(inlineKeyboardRows) == 0 {
+			return s.client.SendMessage(msg, chatId)
+		}
+		return s.client.SendInlineKeyboard(inlineKeyboardRows, msg, chatId)
+	}
+
+	return s.tokenAdd(ctx, fields, chatId)
+}
+
+// TokenQuery revokes token by button of tokens list
+func (s *Service) TokenQuery(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	chatId := update.CallbackQuery.Message.Chat.ID
+	messageId := update.CallbackQuery.Message.MessageID
+
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+
+	data := update.CallbackQuery.Data[len(tokenPrefix):]
+	if strings.Index(data, "del_") != 0 {
+		return errors.New("token callback data")
+	}
+	tokenId, err := strconv.Atoi(data[len("del_"):])
+	if err != nil {
+		return errors.Wrap(err, "token id convert")
+	}
+	if err = s.reposToken.RevokeToken(ctx, userCtx.Id, tokenId); err != nil {
+		_ = s.client.SendMessage(pr.Sprintf("Token not revoked: %s", err.Error()), chatId)
+		return errors.Wrap(err, "revoke token")
+	}
+
+	msg, inlineKeyboardRows, err := s.tokenList(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.client.SendCallbackQuery(inlineKeyboardRows, pr.Sprintf("Token success revoked")+"\r\n"+msg,
+		messageId, chatId)
+}
+
+func (s *Service) tokenList(ctx context.Context) (msg string, inlineKeyboardRows []*client.KeyboardRow, err error) {
+	pr := i18n.FromContext(ctx)
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "user not found")
+	}
+	tokens, err := s.reposToken.Tokens(ctx, userCtx.Id)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "tokens")
+	}
+	if len(tokens) == 0 {
+		return pr.Sprintf("Tokens list is empty, write `/token Sheets` to issue read only token " +
+			"or `/token Sheets write` to issue token which also changes data"), nil, nil
+	}
+
+	msg = pr.Sprintf("Api tokens:") + "\n"
+	for _, t := range tokens {
+		used := pr.Sprintf("never used")
+		if !t.UsedAt.IsZero() {
+			used = pr.Sprintf("used %s", pr.DateTime(t.UsedAt))
+		}
+		msg += pr.Sprintf("*%s* - %s, created %s, %s\n", t.Name, t.Scopes.String(),
+			pr.Date(t.CreatedAt), used)
+
+		inlineKeyboardRow := client.NewKeyboardRow()
+		inlineKeyboardRow.Add(pr.Sprintf("Revoke %s", t.Name), tokenPrefix+"del_"+strconv.Itoa(t.Id))
+		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
+	}
+
+	return
+}
+
+// tokenAdd parses `Sheets write`, scopes are optional and read only by default
+func (s *Service) tokenAdd(ctx context.Context, fields []string, chatId int64) (err error) {
+	pr := i18n.FromContext(ctx)
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+
+	scopes := auth.Scopes{auth.ScopeRead}
+	if len(fields) > 1 {
+		if sc, errScopes := auth.ParseScopes(fields[len(fields)-1]); errScopes == nil {
+			scopes = sc
+			fields = fields[:len(fields)-1]
+		}
+	}
+	name := strings.Join(fields, " ")
+	if len([]rune(name)) > maxTokenName {
+		_ = s.client.SendMessage(pr.Sprintf("Token name must be up to %d characters", maxTokenName), chatId)
+		return errors.New("token name")
+	}
+
+	raw, hash, err := auth.Generate()
+	if err != nil {
+		return err
+	}
+	_, err = s.reposToken.AddToken(ctx, model.Token{
+		UserId: userCtx.Id,
+		Name:   name,
+		Hash:   hash,
+		Scopes: scopes,
+	})
+	if err != nil {
+		_ = s.client.SendMessage(pr.Sprintf("Error add token *%s*: %s", name, err.Error()), chatId)
+		return errors.Wrap(err, "add token")
+	}
+
+	return s.client.SendMessage(pr.Sprintf("Token *%s* - %s success issued, copy it now, it is not shown again:\n"+
+		"`%s`\nSend it in header `Authorization: Bearer <token>`\r\nShow /token", name, scopes.String(), raw), chatId)
+}
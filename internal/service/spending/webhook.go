@@ -0,0 +1,471 @@
+package spending
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/webhook"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	"github.com/sku4/ozon-route256-spending-bot/model/telegram/bot/client"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/i18n"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/logger"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/pagination"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/user"
+	webhookSender "github.com/sku4/ozon-route256-spending-bot/pkg/webhook"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	webhookPrefix = "webhook_"
+
+	webhookAttempts        = 5
+	webhookBackoff         = time.Second
+	webhookRequestTimeout  = 10 * time.Second
+	webhookDeliveryTimeout = 2 * time.Minute
+	maxWebhookUrl          = 2048
+	maxDeliveryError       = 255
+)
+
+var (
+	InvalidWebhookError = errors.New("invalid webhook")
+
+	// lookupIP resolves host of webhook url
+	lookupIP = net.DefaultResolver.LookupIPAddr
+)
+
+// WebhookPayload is signed json body posted to webhooks, Id is the same for retries of delivery
+type WebhookPayload struct {
+	Id        string             `json:"id"`
+	Event     model.WebhookEvent `json:"event"`
+	StateId   int                `json:"stateId"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Data      interface{}        `json:"data"`
+}
+
+// WebhookSpending is data of spending.added, price is in currency of state
+type WebhookSpending struct {
+	EventId       int     `json:"eventId"`
+	CategoryId    int     `json:"categoryId"`
+	CategoryTitle string  `json:"categoryTitle"`
+	Price         float64 `json:"price"`
+	Currency      string  `json:"currency"`
+	Date          string  `json:"date"`
+	UserId        int     `json:"userId"`
+	AccountId     int     `json:"accountId"`
+}
+
+// WebhookLimit is data of limit.exceeded, amounts are in currency of state
+type WebhookLimit struct {
+	LimitId       int     `json:"limitId"`
+	CategoryId    int     `json:"categoryId"`
+	CategoryTitle string  `json:"categoryTitle"`
+	Limit         float64 `json:"limit"`
+	Spent         float64 `json:"spent"`
+	Currency      string  `json:"currency"`
+	Period        string  `json:"period"`
+}
+
+// WebhookReport is data of report.generated, message is text of report sent to telegram
+type WebhookReport struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
+
+// WebhookNotifier delivers events of states to their webhooks in background
+type WebhookNotifier struct {
+	ctx          context.Context
+	cancel       context.CancelFunc
+	reposWebhook webhook.Client
+	sender       *webhookSender.Sender
+	wg           sync.WaitGroup
+}
+
+func NewWebhookNotifier(reposWebhook webhook.Client, sender *webhookSender.Sender) *WebhookNotifier {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &WebhookNotifier{
+		ctx:          ctx,
+		cancel:       cancel,
+		reposWebhook: reposWebhook,
+		sender:       sender,
+	}
+}
+
+// Notify delivers event with data to active webhooks of state subscribed to event without blocking caller,
+// attempts are logged and webhook is disabled after repeated failed deliveries
+func (n *WebhookNotifier) Notify(stateId int, event model.WebhookEvent, data interface{}) {
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+
+		ctx, cancel := context.WithTimeout(n.ctx, webhookDeliveryTimeout)
+		defer cancel()
+
+		webhooks, err := n.reposWebhook.Webhooks(ctx, stateId)
+		if err != nil {
+			logger.Info(fmt.Sprintf("webhooks of state %d: %s", stateId, err.Error()))
+			return
+		}
+		var wg sync.WaitGroup
+		for _, w := range webhooks {
+			if !w.DisabledAt.IsZero() || !w.Has(event) {
+				continue
+			}
+			wg.Add(1)
+			go func(w model.Webhook) {
+				defer wg.Done()
+				n.deliver(ctx, w, event, data)
+			}(w)
+		}
+		wg.Wait()
+	}()
+}
+
+// Wait blocks until started deliveries are finished
+func (n *WebhookNotifier) Wait() {
+	n.wg.Wait()
+}
+
+// Shutdown waits for started deliveries until ctx is done, then cancels the rest of them
+func (n *WebhookNotifier) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		n.cancel()
+		<-done
+		return errors.Wrap(ctx.Err(), "webhook deliveries")
+	}
+}
+
+func (n *WebhookNotifier) deliver(ctx context.Context, w model.Webhook, event model.WebhookEvent,
+	data interface{}) {
+	deliveryId := webhookSender.NewDeliveryId()
+	body, err := json.Marshal(WebhookPayload{
+		Id:        deliveryId,
+		Event:     event,
+		StateId:   w.StateId,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		logger.Info(fmt.Sprintf("webhook payload: %s", err.Error()))
+		return
+	}
+
+	err = n.sender.Send(ctx, w.Url, w.Secret, string(event), deliveryId, body, func(a webhookSender.Attempt) {
+		d := model.WebhookDelivery{
+			WebhookId:  w.Id,
+			DeliveryId: deliveryId,
+			Event:      event,
+			Attempt:    a.Number,
+			StatusCode: a.StatusCode,
+			DurationMs: int(a.Duration.Milliseconds()),
+		}
+		if a.Err != nil {
+			d.Error = a.Err.Error()
+			if len(d.Error) > maxDeliveryError {
+				d.Error = d.Error[:maxDeliveryError]
+			}
+		}
+		if errLog := n.reposWebhook.AddDelivery(ctx, d); errLog != nil {
+			logger.Info(fmt.Sprintf("webhook %d delivery log: %s", w.Id, errLog.Error()))
+		}
+	})
+
+	disabled, errDelivered := n.reposWebhook.Delivered(ctx, w.Id, err == nil)
+	if errDelivered != nil && !errors.Is(errDelivered, webhook.NotFoundError) {
+		logger.Info(fmt.Sprintf("webhook %d delivered: %s", w.Id, errDelivered.Error()))
+	}
+	if err != nil && disabled {
+		logger.Info(fmt.Sprintf("webhook %d of state %d disabled after %d failed deliveries", w.Id, w.StateId,
+			webhook.MaxFailures))
+	}
+}
+
+// newWebhookSender connects to public addresses only, host of webhook may resolve to private address
+// after registration
+func newWebhookSender() *webhookSender.Sender {
+	dialer := &net.Dialer{Timeout: webhookRequestTimeout, Control: publicDial}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return webhookSender.NewSender(&http.Client{Timeout: webhookRequestTimeout, Transport: transport},
+		webhookAttempts, webhookBackoff)
+}
+
+// publicDial refuses connection to address which is not public
+func publicDial(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.Wrap(err, "webhook address")
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+		return errors.Errorf("webhook address %s is not public", host)
+	}
+
+	return nil
+}
+
+// publicIP rejects loopback, private, link local, multicast and unspecified addresses
+func publicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+// Webhook shows webhooks of state or registers new one: `/webhook https://example.com/hook limit.exceeded`,
+// all events by default. Registering url again changes its events and enables disabled webhook
+func (s *Service) Webhook(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	chatId := update.Message.Chat.ID
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+
+	fields := strings.Fields(update.Message.CommandArguments())
+	if len(fields) == 0 {
+		msg, inlineKeyboardRows, errList := s.webhookList(ctx, uState.Id)
+		if errList != nil {
+			return errList
+		}
+		if len(inlineKeyboardRows) == 0 {
+			return s.client.SendMessage(msg, chatId)
+		}
+		return s.client.SendInlineKeyboard(inlineKeyboardRows, msg, chatId)
+	}
+
+	w, err := s.addWebhook(ctx, uState.Id, userCtx.Id, fields[0], fields[1:])
+	if errors.Is(err, InvalidWebhookError) {
+		return s.client.SendMessage(pr.Sprintf("%s\nFor example `/webhook https://example.com/hook "+
+			"spending.added limit.exceeded`, events are %s", strings.TrimPrefix(err.Error(), "invalid webhook: "),
+			webhookEventsList()), chatId)
+	}
+	if err != nil {
+		_ = s.client.SendMessage(pr.Sprintf("Error add webhook: %s", err.Error()), chatId)
+		return err
+	}
+
+	return s.client.SendMessage(pr.Sprintf("Webhook %s success registered for %s\n"+
+		"Payloads are signed by HMAC-SHA256 in header `%s`, secret:\n`%s`\r\nShow /webhook",
+		w.Url, webhookEventsString(w.Events), webhookSender.SignatureHeader, w.Secret), chatId)
+}
+
+// WebhookQuery deletes webhook by button of webhooks list
+func (s *Service) WebhookQuery(ctx context.Context, update tgbotapi.Update) (err error) {
+	pr := i18n.FromContext(ctx)
+	chatId := update.CallbackQuery.Message.Chat.ID
+	messageId := update.CallbackQuery.Message.MessageID
+
+	userCtx, err := user.FromContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	uState, err := userCtx.GetState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "state not found")
+	}
+
+	data := update.CallbackQuery.Data[len(webhookPrefix):]
+	if strings.Index(data, "del_") != 0 {
+		return errors.New("webhook callback data")
+	}
+	webhookId, err := strconv.Atoi(data[len("del_"):])
+	if err != nil {
+		return errors.Wrap(err, "webhook id convert")
+	}
+	if err = s.reposWebhook.DeleteWebhook(ctx, uState.Id, webhookId); err != nil {
+		_ = s.client.SendMessage(pr.Sprintf("Webhook not deleted: %s", err.Error()), chatId)
+		return errors.Wrap(err, "delete webhook")
+	}
+
+	msg, inlineKeyboardRows, err := s.webhookList(ctx, uState.Id)
+	if err != nil {
+		return err
+	}
+
+	return s.client.SendCallbackQuery(inlineKeyboardRows, pr.Sprintf("Webhook success deleted")+"\r\n"+msg,
+		messageId, chatId)
+}
+
+func (s *Service) webhookList(ctx context.Context, stateId int) (msg string,
+	inlineKeyboardRows []*client.KeyboardRow, err error) {
+	pr := i18n.FromContext(ctx)
+	webhooks, err := s.reposWebhook.Webhooks(ctx, stateId)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "webhooks")
+	}
+	if len(webhooks) == 0 {
+		return pr.Sprintf("Webhooks list is empty, write `/webhook https://example.com/hook` to post "+
+			"events %s to url", webhookEventsList()), nil, nil
+	}
+
+	msg = pr.Sprintf("Webhooks:") + "\n"
+	for _, w := range webhooks {
+		status := pr.Sprintf("active")
+		if !w.DisabledAt.IsZero() {
+			status = pr.Sprintf("*disabled* %s, register url again to enable", pr.DateTime(w.DisabledAt))
+		} else if w.Failures > 0 {
+			status = pr.Plural(w.Failures, "%d failed delivery in a row", "%d failed deliveries in a row", w.Failures)
+		}
+		msg += fmt.Sprintf("%s - %s, %s\n", w.Url, webhookEventsString(w.Events), status)
+
+		inlineKeyboardRow := client.NewKeyboardRow()
+		inlineKeyboardRow.Add(pr.Sprintf("Delete %s", webhookHost(w.Url)), webhookPrefix+"del_"+strconv.Itoa(w.Id))
+		inlineKeyboardRows = append(inlineKeyboardRows, inlineKeyboardRow)
+	}
+
+	return
+}
+
+// StateWebhooks returns webhooks of state
+func (s *Service) StateWebhooks(ctx context.Context, stateId int) ([]model.Webhook, error) {
+	return s.reposWebhook.Webhooks(ctx, stateId)
+}
+
+// StateAddWebhook registers webhook of state by caller of api
+func (s *Service) StateAddWebhook(ctx context.Context, stateId int, rawUrl string,
+	events []string) (model.Webhook, error) {
+	userId := 0
+	if userCtx, err := user.FromContext(ctx); err == nil {
+		userId = userCtx.Id
+	}
+
+	return s.addWebhook(ctx, stateId, userId, rawUrl, events)
+}
+
+// ShutdownWebhooks waits for deliveries of webhooks until ctx is done
+func (s *Service) ShutdownWebhooks(ctx context.Context) error {
+	return s.webhooks.Shutdown(ctx)
+}
+
+func (s *Service) StateDeleteWebhook(ctx context.Context, stateId, id int) error {
+	return s.reposWebhook.DeleteWebhook(ctx, stateId, id)
+}
+
+// StateWebhookDeliveries returns page of attempts of deliveries to webhook of state, newest first
+func (s *Service) StateWebhookDeliveries(ctx context.Context, stateId, id int,
+	page pagination.Page) ([]model.WebhookDelivery, int, error) {
+	if _, err := s.reposWebhook.GetWebhook(ctx, stateId, id); err != nil {
+		return nil, 0, err
+	}
+
+	return s.reposWebhook.Deliveries(ctx, id, page.Offset, page.Limit)
+}
+
+func (s *Service) addWebhook(ctx context.Context, stateId, userId int, rawUrl string,
+	rawEvents []string) (model.Webhook, error) {
+	if err := validateWebhookUrl(ctx, rawUrl); err != nil {
+		return model.Webhook{}, err
+	}
+	events, err := parseWebhookEvents(rawEvents)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+	secret, err := webhookSender.NewSecret()
+	if err != nil {
+		return model.Webhook{}, err
+	}
+
+	return s.reposWebhook.AddWebhook(ctx, model.Webhook{
+		StateId: stateId,
+		UserId:  userId,
+		Url:     rawUrl,
+		Secret:  secret,
+		Events:  events,
+	})
+}
+
+// validateWebhookUrl accepts absolute https urls of public hosts only, webhooks must not reach
+// services in private network of bot
+func validateWebhookUrl(ctx context.Context, rawUrl string) error {
+	if len(rawUrl) > maxWebhookUrl {
+		return errors.Wrapf(InvalidWebhookError, "url must be up to %d characters", maxWebhookUrl)
+	}
+	u, err := url.Parse(rawUrl)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return errors.Wrap(InvalidWebhookError, "url must be absolute https url")
+	}
+	addrs, err := lookupIP(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return errors.Wrap(InvalidWebhookError, "host of url is not resolved")
+	}
+	for _, a := range addrs {
+		if !publicIP(a.IP) {
+			return errors.Wrap(InvalidWebhookError, "host of url must have public address")
+		}
+	}
+
+	return nil
+}
+
+// parseWebhookEvents parses events separated by spaces or commas, no events is all events
+func parseWebhookEvents(rawEvents []string) (events []model.WebhookEvent, err error) {
+	seen := make(map[model.WebhookEvent]bool)
+	for _, raw := range rawEvents {
+		for _, e := range strings.Split(raw, ",") {
+			if e == "" {
+				continue
+			}
+			event := model.WebhookEvent(strings.ToLower(e))
+			known := false
+			for _, we := range model.WebhookEvents {
+				known = known || we == event
+			}
+			if !known {
+				return nil, errors.Wrapf(InvalidWebhookError, "unknown event %s", e)
+			}
+			if !seen[event] {
+				seen[event] = true
+				events = append(events, event)
+			}
+		}
+	}
+	if len(events) == 0 {
+		events = model.WebhookEvents
+	}
+
+	return
+}
+
+func webhookEventsString(events []model.WebhookEvent) string {
+	names := make([]string, 0, len(events))
+	for _, e := range events {
+		names = append(names, "`"+string(e)+"`")
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func webhookEventsList() string {
+	return webhookEventsString(model.WebhookEvents)
+}
+
+func webhookHost(rawUrl string) string {
+	if u, err := url.Parse(rawUrl); err == nil {
+		return u.Host
+	}
+
+	return rawUrl
+}
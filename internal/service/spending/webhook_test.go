@@ -0,0 +1,171 @@
+//go:build integration
+// +build integration
+
+package spending
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/repository/postgres/webhook"
+	"github.com/sku4/ozon-route256-spending-bot/model"
+	webhookSender "github.com/sku4/ozon-route256-spending-bot/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func(db *sqlx.DB) {
+		_ = db.Close()
+	}(db)
+
+	var (
+		mu       sync.Mutex
+		payloads []WebhookPayload
+		code     int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		if !webhookSender.Verify("whsec_1", body, r.Header.Get(webhookSender.SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var p WebhookPayload
+		_ = json.Unmarshal(body, &p)
+		payloads = append(payloads, p)
+		w.WriteHeader(code)
+	}))
+	defer srv.Close()
+
+	n := NewWebhookNotifier(webhook.NewWebhook(db), webhookSender.NewSender(srv.Client(), 2, time.Millisecond))
+
+	created := time.Date(2022, 11, 27, 10, 0, 0, 0, time.UTC)
+	webhookRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "state_id", "user_id", "url", "secret", "events", "failures",
+			"created_at", "disabled_at"}).
+			AddRow(1, 3, 5, srv.URL, "whsec_1", "spending.added,limit.exceeded", 0, created, nil).
+			AddRow(2, 3, 5, srv.URL+"/report", "whsec_2", "report.generated", 0, created, nil).
+			AddRow(3, 3, 5, srv.URL+"/off", "whsec_3", "spending.added", 5, created, created)
+	}
+	tests := []struct {
+		name         string
+		code         int
+		mock         func()
+		wantPayloads int
+	}{
+		{
+			name: "Delivered",
+			code: http.StatusOK,
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM webhook WHERE state_id").WithArgs(3).WillReturnRows(webhookRows())
+				mock.ExpectExec("INSERT INTO webhook_delivery").
+					WithArgs(1, sqlmock.AnyArg(), model.WebhookSpendingAdded, 1, http.StatusOK, "", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("UPDATE webhook SET failures = 0").WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"disabled"}).AddRow(false))
+			},
+			wantPayloads: 1,
+		},
+		{
+			name: "Failed and disabled",
+			code: http.StatusServiceUnavailable,
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM webhook WHERE state_id").WithArgs(3).WillReturnRows(webhookRows())
+				for attempt := 1; attempt <= 2; attempt++ {
+					mock.ExpectExec("INSERT INTO webhook_delivery").
+						WithArgs(1, sqlmock.AnyArg(), model.WebhookSpendingAdded, attempt,
+							http.StatusServiceUnavailable, "", sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+				mock.ExpectQuery("UPDATE webhook SET failures = failures \\+ 1").WithArgs(1, webhook.MaxFailures).
+					WillReturnRows(sqlmock.NewRows([]string{"disabled"}).AddRow(true))
+			},
+			wantPayloads: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payloads, code = nil, tt.code
+			tt.mock()
+
+			n.Notify(3, model.WebhookSpendingAdded, WebhookSpending{EventId: 10, Price: 100, Currency: "USD"})
+			n.Wait()
+
+			assert.Len(t, payloads, tt.wantPayloads)
+			for _, p := range payloads {
+				assert.Equal(t, model.WebhookSpendingAdded, p.Event)
+				assert.Equal(t, 3, p.StateId)
+				assert.Equal(t, payloads[0].Id, p.Id)
+				assert.Equal(t, map[string]interface{}{"eventId": float64(10), "categoryId": float64(0),
+					"categoryTitle": "", "price": float64(100), "currency": "USD", "date": "", "userId": float64(0),
+					"accountId": float64(0)}, p.Data)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestParseWebhookEvents(t *testing.T) {
+	events, err := parseWebhookEvents(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, model.WebhookEvents, events)
+
+	events, err = parseWebhookEvents([]string{"limit.exceeded,spending.added", "Limit.Exceeded"})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.WebhookEvent{model.WebhookLimitExceeded, model.WebhookSpendingAdded}, events)
+
+	_, err = parseWebhookEvents([]string{"event.deleted"})
+	assert.ErrorIs(t, err, InvalidWebhookError)
+
+}
+
+func TestValidateWebhookUrl(t *testing.T) {
+	defer func(f func(context.Context, string) ([]net.IPAddr, error)) { lookupIP = f }(lookupIP)
+	hosts := map[string]string{
+		"example.com":         "93.184.216.34",
+		"internal.example":    "10.0.0.5",
+		"metadata.example":    "169.254.169.254",
+		"localhost":           "127.0.0.1",
+		"127.0.0.1":           "127.0.0.1",
+		"::1":                 "::1",
+		"ipv6.example.com":    "2606:2800:220:1::1",
+		"unspecified.example": "0.0.0.0",
+	}
+	lookupIP = func(_ context.Context, host string) ([]net.IPAddr, error) {
+		ip, ok := hosts[host]
+		if !ok {
+			return nil, errors.New("no such host")
+		}
+		return []net.IPAddr{{IP: net.ParseIP(ip)}}, nil
+	}
+	ctx := context.Background()
+
+	assert.NoError(t, validateWebhookUrl(ctx, "https://example.com/hook?a=1"))
+	assert.NoError(t, validateWebhookUrl(ctx, "https://ipv6.example.com/hook"))
+	assert.ErrorIs(t, validateWebhookUrl(ctx, "http://example.com/hook"), InvalidWebhookError)
+	assert.ErrorIs(t, validateWebhookUrl(ctx, "example.com"), InvalidWebhookError)
+	for _, rawUrl := range []string{"https://internal.example/hook", "https://metadata.example/latest",
+		"https://localhost:8443/hook", "https://127.0.0.1/hook", "https://[::1]/hook",
+		"https://unspecified.example/hook", "https://unknown.example/hook"} {
+		assert.ErrorIs(t, validateWebhookUrl(ctx, rawUrl), InvalidWebhookError, rawUrl)
+	}
+
+	assert.NoError(t, publicDial("tcp", "93.184.216.34:443", nil))
+	assert.Error(t, publicDial("tcp", "192.168.1.1:443", nil))
+	assert.Error(t, publicDial("tcp6", "[fe80::1]:443", nil))
+}
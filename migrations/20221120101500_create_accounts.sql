@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+create table account
+(
+    id              int generated always as identity,
+    state_id        int references state (id) on delete cascade,
+    currency_id     int references currency (id) on delete set null,
+    title           varchar(255) not null,
+    opening_balance bigint       not null default 0,
+    created_at      timestamp    not null default now(),
+    primary key (id)
+);
+
+create table account_transfer
+(
+    id              int generated always as identity,
+    from_account_id int references account (id) on delete cascade,
+    to_account_id   int references account (id) on delete cascade,
+    transfer_at     date      not null,
+    amount          bigint    not null,
+    created_at      timestamp not null default now(),
+    primary key (id)
+);
+
+alter table event
+    add column account_id int references account (id) on delete set null;
+
+alter table state
+    add column last_account_id int references account (id) on delete set null;
+
+create unique index account_unique_idx on account (state_id, title);
+create index event_account_idx on event (account_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table state
+    drop column last_account_id;
+alter table event
+    drop column account_id;
+drop table account_transfer;
+drop table account;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+create table recurring
+(
+    id          int generated always as identity,
+    state_id    int references state (id) on delete cascade,
+    chat_id     bigint      not null,
+    category_id int references category (id) on delete cascade,
+    account_id  int references account (id) on delete set null,
+    currency_id int references currency (id) on delete set null,
+    price       bigint      not null,
+    period      varchar(10) not null,
+    day         int         not null,
+    month       int         not null default 0,
+    start_at    date        not null,
+    created_at  timestamp   not null default now(),
+    primary key (id)
+);
+
+-- one row per materialised occurrence, unique index makes materialisation idempotent
+create table recurring_event
+(
+    id            int generated always as identity,
+    recurring_id  int references recurring (id) on delete cascade,
+    event_id      int references event (id) on delete set null,
+    occurrence_at date      not null,
+    created_at    timestamp not null default now(),
+    primary key (id)
+);
+
+create unique index recurring_event_unique_idx on recurring_event (recurring_id, occurrence_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table recurring_event;
+drop table recurring;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+create table digest
+(
+    id         int generated always as identity,
+    state_id   int references state (id) on delete cascade,
+    chat_id    bigint      not null,
+    period     varchar(10) not null,
+    clock      int         not null,
+    next_at    timestamptz not null,
+    created_at timestamp   not null default now(),
+    primary key (id)
+);
+
+create unique index digest_unique_idx on digest (chat_id, period);
+create index digest_next_at_idx on digest (next_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table digest;
+-- +goose StatementEnd
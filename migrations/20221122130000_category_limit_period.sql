@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table category_limit add column period varchar(10) not null default 'month';
+alter table category_limit add column period_days int not null default 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table category_limit drop column period_days;
+alter table category_limit drop column period;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table state add column limit_thresholds varchar(50) not null default '50,80,100';
+
+-- sent limit warnings, one per threshold in period of limit
+create table category_limit_notice
+(
+    id                int generated always as identity,
+    category_limit_id int references category_limit (id) on delete cascade,
+    threshold         int       not null,
+    period_at         date      not null,
+    created_at        timestamp not null default now(),
+    primary key (id)
+);
+
+create unique index category_limit_notice_unique_idx on category_limit_notice (category_limit_id, threshold, period_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table category_limit_notice;
+alter table state drop column limit_thresholds;
+-- +goose StatementEnd
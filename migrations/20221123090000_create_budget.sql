@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+create table budget
+(
+    id         int generated always as identity,
+    state_id   int references state (id) on delete cascade,
+    month_at   date      not null,
+    total      bigint    not null default 0,
+    carried    bigint    not null default 0,
+    rollover   boolean   not null default false,
+    created_at timestamp not null default now(),
+    primary key (id)
+);
+
+create unique index budget_unique_idx on budget (state_id, month_at);
+
+create table budget_plan
+(
+    id          int generated always as identity,
+    budget_id   int references budget (id) on delete cascade,
+    category_id int references category (id) on delete cascade,
+    planned     bigint not null,
+    primary key (id)
+);
+
+create unique index budget_plan_unique_idx on budget_plan (budget_id, category_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table budget_plan;
+drop table budget;
+-- +goose StatementEnd
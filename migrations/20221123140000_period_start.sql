@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- weekday weeks start on, 0 is sunday, and day of month months start on
+alter table state add column week_start smallint not null default 1;
+alter table state add column month_start smallint not null default 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table state drop column month_start;
+alter table state drop column week_start;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- IANA time zone of user dates and periods, empty is time zone of server
+alter table state add column timezone varchar(64) not null default '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table state drop column timezone;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table "user" add column name varchar(255) not null default '';
+
+-- shared ledger of group chat
+create table chat
+(
+    id         int generated always as identity,
+    chat_id    bigint    not null,
+    state_id   int references state (id) on delete cascade,
+    created_at timestamp not null default now(),
+    primary key (id)
+);
+
+create unique index chat_chat_id_unique_idx on chat (chat_id);
+
+-- ledger and member of event, events added before go to ledger of their account or recurring,
+-- the rest were added by the only user of bot before ledgers
+alter table event add column state_id int references state (id) on delete cascade;
+alter table event add column user_id int references "user" (id) on delete set null;
+
+update event e set state_id = a.state_id from account a where a.id = e.account_id;
+update event e set state_id = r.state_id
+    from recurring_event re join recurring r on r.id = re.recurring_id
+    where re.event_id = e.id and e.state_id is null;
+update event set state_id = (select u.state_id from "user" u where u.state_id is not null order by u.id limit 1)
+    where state_id is null;
+
+create index event_state_id_event_at_idx on event (state_id, event_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index event_state_id_event_at_idx;
+alter table event drop column user_id;
+alter table event drop column state_id;
+drop table chat;
+alter table "user" drop column name;
+-- +goose StatementEnd
@@ -0,0 +1,60 @@
+-- +goose Up
+-- +goose StatementBegin
+-- members of shared ledger of group chat
+create table ledger_member
+(
+    id         int generated always as identity,
+    state_id   int references state (id) on delete cascade,
+    user_id    int references "user" (id) on delete cascade,
+    created_at timestamp not null default now(),
+    primary key (id)
+);
+
+create unique index ledger_member_unique_idx on ledger_member (state_id, user_id);
+
+-- event split among members, event is split once
+create table split
+(
+    id         int generated always as identity,
+    state_id   int references state (id) on delete cascade,
+    event_id   int references event (id) on delete cascade,
+    payer_id   int references "user" (id) on delete cascade,
+    created_at timestamp not null default now(),
+    primary key (id)
+);
+
+create unique index split_event_unique_idx on split (event_id);
+
+-- amount debtor owes creditor in default currency, pair in reverse order is netted on read
+create table debt
+(
+    id          int generated always as identity,
+    state_id    int references state (id) on delete cascade,
+    debtor_id   int references "user" (id) on delete cascade,
+    creditor_id int references "user" (id) on delete cascade,
+    amount      bigint    not null default 0,
+    created_at  timestamp not null default now(),
+    primary key (id)
+);
+
+create unique index debt_pair_unique_idx on debt (state_id, debtor_id, creditor_id);
+
+create table settlement
+(
+    id           int generated always as identity,
+    state_id     int references state (id) on delete cascade,
+    from_user_id int references "user" (id) on delete cascade,
+    to_user_id   int references "user" (id) on delete cascade,
+    amount       bigint    not null,
+    created_at   timestamp not null default now(),
+    primary key (id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table settlement;
+drop table debt;
+drop table split;
+drop table ledger_member;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+create table goal
+(
+    id          int generated always as identity,
+    state_id    int references state (id) on delete cascade,
+    title       varchar(255) not null,
+    target      bigint       not null,
+    currency_id int references currency (id) on delete set null,
+    deadline    date         not null,
+    savings     boolean      not null default false,
+    created_at  timestamp    not null default now(),
+    primary key (id)
+);
+
+create index goal_state_idx on goal (state_id);
+
+create table goal_contribution
+(
+    id             int generated always as identity,
+    goal_id        int references goal (id) on delete cascade,
+    amount         bigint    not null,
+    contributed_at date      not null,
+    created_at     timestamp not null default now(),
+    primary key (id)
+);
+
+create index goal_contribution_goal_idx on goal_contribution (goal_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table goal_contribution;
+drop table goal;
+-- +goose StatementEnd
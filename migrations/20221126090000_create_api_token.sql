@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- personal access tokens of api, only sha256 of token is stored
+create table api_token
+(
+    id         int generated always as identity,
+    user_id    int references "user" (id) on delete cascade,
+    name       varchar(255) not null,
+    token_hash varchar(64)  not null,
+    scopes     varchar(255) not null default 'read',
+    created_at timestamp    not null default now(),
+    used_at    timestamp,
+    revoked_at timestamp,
+    primary key (id)
+);
+
+create unique index api_token_hash_idx on api_token (token_hash);
+create index api_token_user_idx on api_token (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table api_token;
+-- +goose StatementEnd
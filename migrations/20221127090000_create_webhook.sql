@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+-- callback urls of state notified about events, secret signs payloads
+create table webhook
+(
+    id          int generated always as identity,
+    state_id    int references state (id) on delete cascade,
+    user_id     int references "user" (id) on delete cascade,
+    url         varchar(2048) not null,
+    secret      varchar(64)   not null,
+    events      varchar(255)  not null,
+    failures    int           not null default 0,
+    created_at  timestamp     not null default now(),
+    disabled_at timestamp,
+    primary key (id)
+);
+
+create unique index webhook_state_url_idx on webhook (state_id, url);
+
+-- attempts of deliveries to webhooks
+create table webhook_delivery
+(
+    id          int generated always as identity,
+    webhook_id  int references webhook (id) on delete cascade,
+    delivery_id varchar(36)  not null,
+    event       varchar(64)  not null,
+    attempt     int          not null,
+    status_code int          not null default 0,
+    error       varchar(255) not null default '',
+    duration_ms int          not null default 0,
+    created_at  timestamp    not null default now(),
+    primary key (id)
+);
+
+create index webhook_delivery_webhook_idx on webhook_delivery (webhook_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table webhook_delivery;
+drop table webhook;
+-- +goose StatementEnd
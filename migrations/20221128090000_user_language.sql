@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- language chosen by /language, empty is language of telegram client
+alter table "user" add column language varchar(8) not null default '';
+-- scheduled messages speak language of member who set them up
+alter table digest add column language varchar(8) not null default '';
+alter table recurring add column language varchar(8) not null default '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table recurring drop column language;
+alter table digest drop column language;
+alter table "user" drop column language;
+-- +goose StatementEnd
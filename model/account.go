@@ -0,0 +1,23 @@
+package model
+
+type Account struct {
+	Id             int
+	StateId        int
+	Title          string
+	Currency       Currency
+	OpeningBalance int64
+}
+
+type AccountDB struct {
+	Id             int    `db:"id"`
+	StateId        int    `db:"state_id"`
+	CurrencyId     int    `db:"currency_id"`
+	CurrencyAbbr   string `db:"currency_abbr"`
+	Title          string `db:"title"`
+	OpeningBalance int64  `db:"opening_balance"`
+}
+
+type AccountBalanceDB struct {
+	Id      int   `db:"id"`
+	Balance int64 `db:"balance"`
+}
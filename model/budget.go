@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+type Budget struct {
+	Id       int
+	StateId  int
+	Month    time.Time
+	Total    int64
+	Carried  int64
+	Rollover bool
+	Plans    []BudgetPlan
+}
+
+type BudgetPlan struct {
+	Category Category
+	Planned  int64
+}
+
+type BudgetDB struct {
+	Id       int       `db:"id"`
+	StateId  int       `db:"state_id"`
+	Month    time.Time `db:"month_at"`
+	Total    int64     `db:"total"`
+	Carried  int64     `db:"carried"`
+	Rollover bool      `db:"rollover"`
+}
+
+type BudgetPlanDB struct {
+	CategoryId    int    `db:"category_id"`
+	CategoryTitle string `db:"category_title"`
+	Planned       int64  `db:"planned"`
+}
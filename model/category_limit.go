@@ -4,6 +4,8 @@ type CategoryLimit struct {
 	Id       int
 	Category *Category
 	Limit    int64
+	Period   string
+	Days     int
 }
 
 type CategoryLimitDB struct {
@@ -12,4 +14,6 @@ type CategoryLimitDB struct {
 	CategoryId    int    `db:"category_id"`
 	CategoryTitle string `db:"category_title"`
 	Limit         int64  `db:"category_limit"`
+	Period        string `db:"period"`
+	Days          int    `db:"period_days"`
 }
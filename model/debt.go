@@ -0,0 +1,27 @@
+package model
+
+// Member is member of shared ledger
+type Member struct {
+	UserId int
+	Name   string
+}
+
+type MemberDB struct {
+	UserId int    `db:"user_id"`
+	Name   string `db:"name"`
+}
+
+// Debt is amount in default currency debtor owes creditor
+type Debt struct {
+	Debtor   Member
+	Creditor Member
+	Amount   int64
+}
+
+type DebtDB struct {
+	DebtorId     int    `db:"debtor_id"`
+	DebtorName   string `db:"debtor_name"`
+	CreditorId   int    `db:"creditor_id"`
+	CreditorName string `db:"creditor_name"`
+	Amount       int64  `db:"amount"`
+}
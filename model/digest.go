@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+type Digest struct {
+	Id       int
+	StateId  int
+	ChatId   int64
+	Period   string
+	Clock    int
+	NextAt   time.Time
+	Currency Currency
+	// WeekStart, MonthStart and Timezone are period start and time zone of digest state
+	WeekStart  int
+	MonthStart int
+	Timezone   string
+	// Language is language of member who subscribed chat
+	Language string
+}
+
+type DigestDB struct {
+	Id           int       `db:"id"`
+	StateId      int       `db:"state_id"`
+	ChatId       int64     `db:"chat_id"`
+	Period       string    `db:"period"`
+	Clock        int       `db:"clock"`
+	NextAt       time.Time `db:"next_at"`
+	CurrencyId   int       `db:"currency_id"`
+	CurrencyAbbr string    `db:"currency_abbr"`
+	WeekStart    int       `db:"week_start"`
+	MonthStart   int       `db:"month_start"`
+	Timezone     string    `db:"timezone"`
+	Language     string    `db:"language"`
+}
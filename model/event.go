@@ -1,18 +1,69 @@
 package model
 
-import "time"
+import (
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"time"
+)
 
 type Event struct {
-	Id       int
-	Category Category
-	Date     time.Time
-	Price    int64
+	Id        int
+	Category  Category
+	Date      time.Time
+	Price     int64
+	UserId    int
+	AccountId int
 }
 
 type EventDB struct {
-	Id         int       `db:"id"`
-	CategoryId int       `db:"category_id"`
-	Date       time.Time `db:"event_at"`
-	Price      int64     `db:"price"`
-	CreatedAt  time.Time `db:"created_at"`
+	Id            int       `db:"id"`
+	CategoryId    int       `db:"category_id"`
+	CategoryTitle string    `db:"category_title"`
+	Date          time.Time `db:"event_at"`
+	Price         int64     `db:"price"`
+	UserId        int       `db:"user_id"`
+	AccountId     int       `db:"account_id"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// EventFilter selects events of state, zero fields are not filtered
+type EventFilter struct {
+	StateId    int
+	From, To   time.Time
+	CategoryId int
+	UserId     int
+}
+
+type EventChangeType string
+
+const (
+	EventCreated EventChangeType = "created"
+	EventUpdated EventChangeType = "updated"
+	EventDeleted EventChangeType = "deleted"
+)
+
+// EventChange is published to watchers of state, deleted event has Id only
+type EventChange struct {
+	Type    EventChangeType
+	StateId int
+	Event   Event
+}
+
+// MemberSpend is spending of ledger member, Sum is in currency of report
+type MemberSpend struct {
+	UserId int
+	Name   string
+	Sum    decimal.Decimal
+}
+
+// CategorySpendDB is spending by category, Recurring marks spending of events added by recurring spending
+type CategorySpendDB struct {
+	CategoryId int   `db:"category_id"`
+	Recurring  bool  `db:"recurring"`
+	Price      int64 `db:"price"`
+}
+
+type MemberSpendDB struct {
+	UserId int    `db:"user_id"`
+	Name   string `db:"name"`
+	Price  int64  `db:"price"`
 }
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Goal is savings target of state in default currency, Contributed is sum of manual contributions.
+// Savings goal is also filled by unused budget of closed months
+type Goal struct {
+	Id          int
+	StateId     int
+	Title       string
+	Target      int64
+	Currency    Currency
+	Deadline    time.Time
+	Savings     bool
+	Contributed int64
+	CreatedAt   time.Time
+}
+
+type GoalDB struct {
+	Id           int       `db:"id"`
+	StateId      int       `db:"state_id"`
+	Title        string    `db:"title"`
+	Target       int64     `db:"target"`
+	CurrencyId   int       `db:"currency_id"`
+	CurrencyAbbr string    `db:"currency_abbr"`
+	Deadline     time.Time `db:"deadline"`
+	Savings      bool      `db:"savings"`
+	Contributed  int64     `db:"contributed"`
+	CreatedAt    time.Time `db:"created_at"`
+}
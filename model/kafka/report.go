@@ -9,4 +9,10 @@ type Report struct {
 	F1, F2   time.Time
 	ChatId   int64
 	UserCurr model.Currency
+	// StateId is ledger of report, Forecast requests projection of spending to the end of period
+	// with limits of state
+	StateId  int
+	Forecast bool
+	// Language is language of user report is sent to
+	Language string
 }
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+type Recurring struct {
+	Id             int
+	StateId        int
+	ChatId         int64
+	Category       Category
+	AccountId      int
+	Currency       Currency
+	Price          int64
+	Period         string
+	Day            int
+	Month          int
+	StartAt        time.Time
+	LastOccurrence time.Time
+	// Timezone is time zone of state, occurrences are dates in it
+	Timezone string
+	// Language is language of member who added recurring
+	Language string
+}
+
+type RecurringDB struct {
+	Id             int       `db:"id"`
+	StateId        int       `db:"state_id"`
+	ChatId         int64     `db:"chat_id"`
+	CategoryId     int       `db:"category_id"`
+	CategoryTitle  string    `db:"category_title"`
+	AccountId      int       `db:"account_id"`
+	CurrencyId     int       `db:"currency_id"`
+	CurrencyAbbr   string    `db:"currency_abbr"`
+	Price          int64     `db:"price"`
+	Period         string    `db:"period"`
+	Day            int       `db:"day"`
+	Month          int       `db:"month"`
+	StartAt        time.Time `db:"start_at"`
+	LastOccurrence time.Time `db:"last_occurrence_at"`
+	Timezone       string    `db:"timezone"`
+	Language       string    `db:"language"`
+}
@@ -6,8 +6,12 @@ import (
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	"github.com/pkg/errors"
 	hgrpc "github.com/sku4/ozon-route256-spending-bot/internal/handler/grpc"
+	hreport "github.com/sku4/ozon-route256-spending-bot/internal/handler/grpc/report"
+	hgrpcV1 "github.com/sku4/ozon-route256-spending-bot/internal/handler/grpc/v1"
 	"github.com/sku4/ozon-route256-spending-bot/model/server/interceptors"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/api"
+	apiReport "github.com/sku4/ozon-route256-spending-bot/pkg/api/report"
+	apiV1 "github.com/sku4/ozon-route256-spending-bot/pkg/api/v1"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/logger"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
@@ -17,26 +21,48 @@ import (
 type Grpc struct {
 	ctx         context.Context
 	grpcService *grpc.Server
-	handler     *hgrpc.Handler
+	register    func(*grpc.Server)
 }
 
-// NewGrpc created new grpc server
-func NewGrpc(ctx context.Context, handler *hgrpc.Handler) *Grpc {
+// NewGrpc created new grpc server of bot with internal api and versioned api
+func NewGrpc(ctx context.Context, handler *hgrpc.Handler, handlerV1 *hgrpcV1.Handler) *Grpc {
+	return newGrpc(ctx, handler.Metrics, handler.Auth, func(s *grpc.Server) {
+		api.RegisterSpendingServer(s, handler)
+		apiV1.RegisterSpendingServer(s, handlerV1)
+	})
+}
+
+// NewReportGrpc created new grpc server of report service
+func NewReportGrpc(ctx context.Context, handler *hreport.Handler) *Grpc {
+	return newGrpc(ctx, handler.Metrics, handler.Auth, func(s *grpc.Server) {
+		apiReport.RegisterReportServiceServer(s, handler)
+	})
+}
+
+func newGrpc(ctx context.Context, metricsFunc interceptors.MetricsFunc, authFunc interceptors.AuthFunc,
+	register func(*grpc.Server)) *Grpc {
 	return &Grpc{
 		ctx: ctx,
-		// grpc middleware metrics
+		// grpc middleware metrics, authentication and validation by rules of proto
 		grpcService: grpc.NewServer(
 			grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(
-				interceptors.UnaryServerInterceptor(handler.Metrics),
+				interceptors.UnaryServerInterceptor(metricsFunc),
+				interceptors.AuthUnaryServerInterceptor(authFunc),
+				interceptors.ValidateUnaryServerInterceptor(),
+			)),
+			grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(
+				interceptors.StreamServerInterceptor(metricsFunc),
+				interceptors.AuthStreamServerInterceptor(authFunc),
+				interceptors.ValidateStreamServerInterceptor(),
 			)),
 		),
-		handler: handler,
+		register: register,
 	}
 }
 
 // Run grpc on port with handler
 func (g *Grpc) Run(grpcUrl string) (err error) {
-	api.RegisterSpendingServer(g.grpcService, g.handler)
+	g.register(g.grpcService)
 	lis, err := net.Listen("tcp", grpcUrl)
 	if err != nil {
 		return errors.Wrap(err, "failed to listen")
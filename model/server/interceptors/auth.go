@@ -0,0 +1,37 @@
+package interceptors
+
+import (
+	"context"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+)
+
+type AuthFunc func(ctx context.Context, fullMethodName string, req interface{}) (context.Context, error)
+
+// AuthUnaryServerInterceptor returns a new unary server interceptors that authenticates caller
+// and passes context with user to handler
+func AuthUnaryServerInterceptor(authFunc AuthFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		newCtx, err := authFunc(ctx, info.FullMethod, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(newCtx, req)
+	}
+}
+
+// AuthStreamServerInterceptor returns a new stream server interceptors that authenticates caller,
+// request of stream is not received yet, so handler checks access to requested data itself
+func AuthStreamServerInterceptor(authFunc AuthFunc) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		newCtx, err := authFunc(stream.Context(), info.FullMethod, nil)
+		if err != nil {
+			return err
+		}
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = newCtx
+
+		return handler(srv, wrapped)
+	}
+}
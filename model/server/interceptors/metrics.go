@@ -2,6 +2,7 @@ package interceptors
 
 import (
 	"context"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	"google.golang.org/grpc"
 )
 
@@ -20,3 +21,17 @@ func UnaryServerInterceptor(metricsFunc MetricsFunc) grpc.UnaryServerInterceptor
 		return handler(newCtx, req)
 	}
 }
+
+// StreamServerInterceptor returns a new stream server interceptors that performs per-stream metrics
+func StreamServerInterceptor(metricsFunc MetricsFunc) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		newCtx, err := metricsFunc(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = newCtx
+
+		return handler(srv, wrapped)
+	}
+}
@@ -0,0 +1,121 @@
+package interceptors
+
+import (
+	"context"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+// validatorAll is implemented by messages generated by protoc-gen-validate
+type validatorAll interface {
+	ValidateAll() error
+}
+
+// fieldError is validation error of field generated by protoc-gen-validate
+type fieldError interface {
+	Field() string
+	Reason() string
+	Cause() error
+}
+
+// multiError keeps all violations of message
+type multiError interface {
+	AllErrors() []error
+}
+
+// ValidateUnaryServerInterceptor returns a new unary server interceptors that rejects requests
+// breaking rules of proto with InvalidArgument and violations of fields in details
+func ValidateUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := validate(req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// ValidateStreamServerInterceptor returns a new stream server interceptors that validates every received message
+func ValidateStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validateStream{ServerStream: stream})
+	}
+}
+
+type validateStream struct {
+	grpc.ServerStream
+}
+
+func (s *validateStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return validate(m)
+}
+
+func validate(req interface{}) error {
+	v, ok := req.(validatorAll)
+	if !ok {
+		return nil
+	}
+	err := v.ValidateAll()
+	if err == nil {
+		return nil
+	}
+
+	errs := []error{err}
+	if multi, ok := err.(multiError); ok {
+		errs = multi.AllErrors()
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, e := range errs {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, fieldViolations("", e)...)
+	}
+
+	st := status.New(codes.InvalidArgument, err.Error())
+	if stDetails, errDetails := st.WithDetails(badRequest); errDetails == nil {
+		st = stDetails
+	}
+
+	return st.Err()
+}
+
+// fieldViolations flattens errors of embedded messages to paths like `date.seconds`
+func fieldViolations(prefix string, err error) []*errdetails.BadRequest_FieldViolation {
+	fe, ok := err.(fieldError)
+	if !ok {
+		return []*errdetails.BadRequest_FieldViolation{{Field: prefix, Description: err.Error()}}
+	}
+
+	field := strings.TrimPrefix(prefix+"."+jsonName(fe.Field()), ".")
+	if cause := fe.Cause(); cause != nil {
+		causes := []error{cause}
+		if multi, ok := cause.(multiError); ok {
+			causes = multi.AllErrors()
+		}
+		var violations []*errdetails.BadRequest_FieldViolation
+		for _, c := range causes {
+			if _, ok = c.(fieldError); ok {
+				violations = append(violations, fieldViolations(field, c)...)
+			}
+		}
+		if len(violations) > 0 {
+			return violations
+		}
+	}
+
+	return []*errdetails.BadRequest_FieldViolation{{Field: field, Description: fe.Reason()}}
+}
+
+// jsonName returns json name of field like `stateId` by go name `StateId`
+func jsonName(field string) string {
+	if field == "" {
+		return field
+	}
+
+	return strings.ToLower(field[:1]) + field[1:]
+}
@@ -0,0 +1,45 @@
+package interceptors
+
+import (
+	"context"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/api/report"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"testing"
+)
+
+func TestValidateUnaryServerInterceptor(t *testing.T) {
+	interceptor := ValidateUnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/api.Spending/SendReport"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	got, err := interceptor(context.Background(), &report.Report{
+		F1: timestamppb.Now(),
+		F2: timestamppb.Now(),
+	}, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", got)
+
+	_, err = interceptor(context.Background(), &report.Report{
+		F2:           timestamppb.Now(),
+		UserCurrency: &report.Currency{Abbr: "EURO"},
+	}, info, handler)
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	if assert.Len(t, st.Details(), 1) {
+		badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+		assert.True(t, ok)
+		var fields []string
+		for _, v := range badRequest.FieldViolations {
+			fields = append(fields, v.Field)
+		}
+		assert.Equal(t, []string{"f1", "userCurrency.abbr"}, fields)
+	}
+}
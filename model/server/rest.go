@@ -6,7 +6,6 @@ import (
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"github.com/sku4/ozon-route256-spending-bot/pkg/api"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/logger"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -17,26 +16,40 @@ import (
 
 const swaggerDir = "./swagger"
 
+// RegisterHandlerFunc registers handlers of grpc-gateway, like generated Register*HandlerFromEndpoint
+type RegisterHandlerFunc func(ctx context.Context, mux *runtime.ServeMux, endpoint string,
+	opts []grpc.DialOption) error
+
 type Rest struct {
 	ctx        context.Context
 	restServer *http.Server
+	handlers   []RegisterHandlerFunc
+	routes     map[string]http.Handler
 }
 
-// NewRest created new rest server
-func NewRest(ctx context.Context) *Rest {
+// NewRest created new rest server with grpc-gateway handlers
+func NewRest(ctx context.Context, handlers ...RegisterHandlerFunc) *Rest {
 	return &Rest{
 		ctx:        ctx,
 		restServer: &http.Server{},
+		handlers:   handlers,
+		routes:     make(map[string]http.Handler),
 	}
 }
 
+// Handle adds handler of pattern to mux of rest server, it is called before Run
+func (r *Rest) Handle(pattern string, handler http.Handler) {
+	r.routes[pattern] = handler
+}
+
 // Run rest server with handle swagger
 func (r *Rest) Run(grpcUrl string, restPort int) (err error) {
 	gwMux := runtime.NewServeMux()
 	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
-	err = api.RegisterSpendingHandlerFromEndpoint(r.ctx, gwMux, grpcUrl, opts)
-	if err != nil {
-		return errors.Wrap(err, "failed to register greeter handler")
+	for _, register := range r.handlers {
+		if err = register(r.ctx, gwMux, grpcUrl, opts); err != nil {
+			return errors.Wrap(err, "failed to register gateway handler")
+		}
 	}
 
 	// Serve the swagger-ui and swagger file
@@ -44,6 +57,9 @@ func (r *Rest) Run(grpcUrl string, restPort int) (err error) {
 	mux.Handle("/", gwMux)
 	mux.Handle("/metrics", promhttp.Handler())
 	handleSwaggerFile(mux)
+	for pattern, handler := range r.routes {
+		mux.Handle(pattern, handler)
+	}
 
 	// Register Swagger Handler
 	fs := http.FileServer(http.Dir(swaggerDir))
@@ -1,21 +1,34 @@
 package model
 
 type State struct {
-	Id       int
-	Currency Currency
+	Id            int
+	Currency      Currency
+	LastAccountId int
+	Thresholds    []int
+	WeekStart     int
+	MonthStart    int
+	Timezone      string
 }
 
 type StateDB struct {
-	Id         int `db:"id"`
-	CurrencyId int `db:"currency_id"`
+	Id            int `db:"id"`
+	CurrencyId    int `db:"currency_id"`
+	LastAccountId int `db:"last_account_id"`
 }
 
 type StateWithLimits struct {
 	Id              int    `db:"id"`
 	CurrencyId      int    `db:"currency_id"`
 	CurrencyAbbr    string `db:"currency_abbr"`
+	LastAccountId   int    `db:"last_account_id"`
+	Thresholds      string `db:"limit_thresholds"`
+	WeekStart       int    `db:"week_start"`
+	MonthStart      int    `db:"month_start"`
+	Timezone        string `db:"timezone"`
 	CategoryId      int    `db:"category_id"`
 	CategoryTitle   string `db:"category_title"`
 	CategoryLimit   int64  `db:"category_limit"`
 	CategoryLimitId int    `db:"category_limit_id"`
+	LimitPeriod     string `db:"period"`
+	LimitDays       int    `db:"period_days"`
 }
@@ -43,6 +43,16 @@ func (i *KeyboardRow) Add(k, v string) {
 	})
 }
 
+// Values returns callback data of buttons of row
+func (i *KeyboardRow) Values() []string {
+	values := make([]string, 0, len(i.buttons))
+	for _, b := range i.buttons {
+		values = append(values, b.v)
+	}
+
+	return values
+}
+
 func (i *KeyboardRow) AddSwitch(k, v string) {
 	i.buttons = append(i.buttons, KeyboardButton{
 		k, v, KeyboardButtonTypeSwitch,
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/sku4/ozon-route256-spending-bot/internal/handler/telegram"
+	"strings"
+)
+
+// CommandMiddleware skips commands addressed to other bots in group chats like `/report7@otherbot`,
+// commands with suffix of this bot are routed by command name without suffix
+func CommandMiddleware(next telegram.IHandler, botName string) telegram.IHandler {
+	return telegram.Func(func(ctx context.Context, upd tgbotapi.Update) (err error) {
+		if upd.Message != nil && upd.Message.IsCommand() {
+			command := upd.Message.CommandWithAt()
+			if i := strings.Index(command, "@"); i != -1 && !strings.EqualFold(command[i+1:], botName) {
+				return nil
+			}
+		}
+
+		return next.IncomingMessage(ctx, upd)
+	})
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sku4/ozon-route256-spending-bot/internal/handler/telegram"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/logger"
+	"sync"
+	"time"
+)
+
+const (
+	// Workers is number of updates handled at the same time
+	Workers = 16
+	// QueueSize is number of updates waiting for every worker, sender is blocked when queue is full
+	QueueSize = 64
+)
+
+var (
+	PoolClosedError = errors.New("pool is closed")
+
+	QueuedUpdates = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "bot",
+		Subsystem: "pool",
+		Name:      "queued_updates",
+	})
+	BusyWorkers = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "bot",
+		Subsystem: "pool",
+		Name:      "busy_workers",
+	})
+	QueueFullTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "bot",
+		Subsystem: "pool",
+		Name:      "queue_full_total",
+	})
+	QueueWaitTime = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "bot",
+		Subsystem: "pool",
+		Name:      "queue_wait_seconds",
+		Buckets:   []float64{0.001, 0.01, 0.1, 0.5, 1, 5, 10, 30},
+	})
+)
+
+type queuedUpdate struct {
+	update   tgbotapi.Update
+	queuedAt time.Time
+}
+
+// Pool handles updates concurrently, updates of one chat go to the same worker and are handled in order
+type Pool struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	queues  []chan queuedUpdate
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	closing chan struct{}
+	start   sync.Once
+	close   sync.Once
+}
+
+// NewPool creates pool of workers with queues of queueSize updates
+func NewPool(workers, queueSize int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		ctx:     ctx,
+		cancel:  cancel,
+		queues:  make([]chan queuedUpdate, workers),
+		closing: make(chan struct{}),
+	}
+	for i := range p.queues {
+		p.queues[i] = make(chan queuedUpdate, queueSize)
+	}
+
+	return p
+}
+
+// Start runs workers handling updates by h, handlers get context of pool which outlives context of server
+// to finish queued updates on shutdown
+func (p *Pool) Start(h telegram.IHandler) {
+	p.start.Do(func() {
+		for _, queue := range p.queues {
+			p.wg.Add(1)
+			go p.work(h, queue)
+		}
+	})
+}
+
+// Submit queues update to worker of its chat, it waits while queue is full
+func (p *Pool) Submit(ctx context.Context, update tgbotapi.Update) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	select {
+	case <-p.closing:
+		return PoolClosedError
+	default:
+	}
+
+	queue := p.queues[uint64(chatId(update))%uint64(len(p.queues))]
+	item := queuedUpdate{update: update, queuedAt: time.Now()}
+	select {
+	case queue <- item:
+	default:
+		QueueFullTotal.Inc()
+		select {
+		case queue <- item:
+		case <-p.closing:
+			return PoolClosedError
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "submit update")
+		}
+	}
+	QueuedUpdates.Inc()
+
+	return nil
+}
+
+// Drain stops accepting updates and waits until queued updates are handled,
+// handlers are cancelled when ctx is done first
+func (p *Pool) Drain(ctx context.Context) error {
+	p.close.Do(func() {
+		close(p.closing)
+		p.mu.Lock()
+		for _, queue := range p.queues {
+			close(queue)
+		}
+		p.mu.Unlock()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return errors.Wrap(ctx.Err(), "drain pool")
+	}
+}
+
+func (p *Pool) work(h telegram.IHandler, queue <-chan queuedUpdate) {
+	defer p.wg.Done()
+	for item := range queue {
+		QueuedUpdates.Dec()
+		QueueWaitTime.Observe(time.Since(item.queuedAt).Seconds())
+
+		BusyWorkers.Inc()
+		if err := h.IncomingMessage(p.ctx, item.update); err != nil {
+			logger.Infos("error processing message: ", err)
+		}
+		BusyWorkers.Dec()
+	}
+}
+
+// chatId returns chat of update, updates without chat are ordered by user
+func chatId(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil && update.Message.Chat != nil:
+		return update.Message.Chat.ID
+	case update.EditedMessage != nil && update.EditedMessage.Chat != nil:
+		return update.EditedMessage.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil && update.CallbackQuery.Message.Chat != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.From != nil:
+		return int64(update.CallbackQuery.From.ID)
+	case update.InlineQuery != nil && update.InlineQuery.From != nil:
+		return int64(update.InlineQuery.From.ID)
+	}
+
+	return 0
+}
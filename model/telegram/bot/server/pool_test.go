@@ -0,0 +1,88 @@
+//go:build integration
+// +build integration
+
+package server
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/sku4/ozon-route256-spending-bot/internal/handler/telegram"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func chatUpdate(updateId int, chatId int64) tgbotapi.Update {
+	return tgbotapi.Update{
+		UpdateID: updateId,
+		Message:  &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatId}},
+	}
+}
+
+func TestPool_Order(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		handled = make(map[int64][]int)
+	)
+	slow := make(chan struct{})
+	p := NewPool(4, 10)
+	p.Start(telegram.Func(func(ctx context.Context, update tgbotapi.Update) error {
+		// the first update of chat 1 waits until updates of chat 2 are handled
+		if update.UpdateID == 1 {
+			<-slow
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		handled[update.Message.Chat.ID] = append(handled[update.Message.Chat.ID], update.UpdateID)
+		return nil
+	}))
+
+	ctx := context.Background()
+	assert.NoError(t, p.Submit(ctx, chatUpdate(1, 1)))
+	assert.NoError(t, p.Submit(ctx, chatUpdate(2, 1)))
+	assert.NoError(t, p.Submit(ctx, chatUpdate(3, 2)))
+	assert.NoError(t, p.Submit(ctx, chatUpdate(4, 2)))
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(handled[2]) == 2
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	assert.Empty(t, handled[1])
+	mu.Unlock()
+
+	close(slow)
+	assert.NoError(t, p.Drain(ctx))
+	assert.Equal(t, []int{1, 2}, handled[1])
+	assert.Equal(t, []int{3, 4}, handled[2])
+	assert.ErrorIs(t, p.Submit(ctx, chatUpdate(5, 1)), PoolClosedError)
+}
+
+func TestPool_Backpressure(t *testing.T) {
+	block := make(chan struct{})
+	p := NewPool(1, 1)
+	p.Start(telegram.Func(func(ctx context.Context, update tgbotapi.Update) error {
+		<-block
+		return nil
+	}))
+
+	ctx := context.Background()
+	assert.NoError(t, p.Submit(ctx, chatUpdate(1, 1)))
+	// worker is busy with the first update, the second one waits in queue
+	assert.Eventually(t, func() bool {
+		return p.Submit(ctx, chatUpdate(2, 1)) == nil
+	}, time.Second, time.Millisecond)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, p.Submit(timeoutCtx, chatUpdate(3, 1)), context.DeadlineExceeded)
+
+	// drain is limited by its context, handlers get cancelled context of pool
+	drainCtx, cancelDrain := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancelDrain()
+	assert.ErrorIs(t, p.Drain(drainCtx), context.DeadlineExceeded)
+	assert.Error(t, p.ctx.Err())
+	close(block)
+}
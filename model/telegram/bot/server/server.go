@@ -2,46 +2,99 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
 	"github.com/sku4/ozon-route256-spending-bot/internal/handler/telegram"
 	"github.com/sku4/ozon-route256-spending-bot/pkg/logger"
+	"net/url"
 )
 
 type Server struct {
 	client *tgbotapi.BotAPI
 	ctx    context.Context
+	pool   *Pool
 }
 
 func NewServer(ctx context.Context, client *tgbotapi.BotAPI) (*Server, error) {
 	return &Server{
 		ctx:    ctx,
 		client: client,
+		pool:   NewPool(Workers, QueueSize),
 	}, nil
 }
 
+// Run receives updates by long polling until ctx is done, updates are handled by pool of workers
 func (s *Server) Run(ctx context.Context, h telegram.IHandler) error {
-	h = MetricsMiddleware(h)
-	h = TracingMiddleware(h)
+	s.pool.Start(s.wrap(h))
+
+	// updates are not returned by long polling while webhook is set
+	if _, err := s.client.RemoveWebhook(); err != nil {
+		logger.Infos("error remove webhook: ", err)
+	}
 
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
-	updates, _ := s.client.GetUpdatesChan(u)
+	updates, err := s.client.GetUpdatesChan(u)
+	if err != nil {
+		return errors.Wrap(err, "get updates")
+	}
 
 	logger.Info("Listening for messages")
 
-	for update := range updates {
-		if update.Message != nil {
-			logger.Infos(update.Message.From.UserName, update.Message.Text)
-		}
-		if update.CallbackQuery != nil {
-			logger.Infos(update.CallbackQuery.From.UserName, update.CallbackQuery.Data)
+	for {
+		select {
+		case update := <-updates:
+			logUpdate(update)
+			if err = s.pool.Submit(ctx, update); err != nil {
+				logger.Infos("error queue update: ", err)
+			}
+		case <-ctx.Done():
+			s.client.StopReceivingUpdates()
+			return nil
 		}
+	}
+}
 
-		err := h.IncomingMessage(ctx, update)
-		if err != nil {
-			logger.Infos("error processing message: ", err)
-		}
+// Shutdown waits until received updates are handled, handlers are cancelled when ctx is done first
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.pool.Drain(ctx)
+}
+
+// wrap adds middlewares of updates to handler
+func (s *Server) wrap(h telegram.IHandler) telegram.IHandler {
+	h = CommandMiddleware(h, s.client.Self.UserName)
+	h = MetricsMiddleware(h)
+	h = TracingMiddleware(h)
+
+	return h
+}
+
+func logUpdate(update tgbotapi.Update) {
+	if update.Message != nil {
+		logger.Infos(update.Message.From.UserName, update.Message.Text)
+	}
+	if update.CallbackQuery != nil {
+		logger.Infos(update.CallbackQuery.From.UserName, update.CallbackQuery.Data)
 	}
+}
+
+// SetCommands replaces menu of commands of bot in Telegram for users with language code,
+// empty code sets menu of users of other languages
+func (s *Server) SetCommands(commands []telegram.BotCommand, languageCode string) error {
+	b, err := json.Marshal(commands)
+	if err != nil {
+		return errors.Wrap(err, "marshal commands")
+	}
+	params := url.Values{}
+	params.Set("commands", string(b))
+	if languageCode != "" {
+		params.Set("language_code", languageCode)
+	}
+	if _, err = s.client.MakeRequest("setMyCommands", params); err != nil {
+		return errors.Wrap(err, "set my commands")
+	}
+
 	return nil
 }
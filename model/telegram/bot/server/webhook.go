@@ -0,0 +1,209 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/handler/telegram"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/logger"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// SecretTokenHeader carries secret token of webhook in requests of Telegram
+	SecretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+	// DedupeSize is number of the latest update ids remembered to skip repeated updates
+	DedupeSize = 10000
+	// DedupeTTL is time update id is remembered by redis, Telegram stops repeating update earlier
+	DedupeTTL = 24 * time.Hour
+
+	maxUpdateLen      = 1 << 20
+	dedupeKeyPrefix   = "telegram_update:"
+	dedupeDialTimeout = time.Second
+)
+
+var EmptySecretTokenError = errors.New("secret token of webhook is empty")
+
+// WebhookConfig is public https url of webhook registered in Telegram and secret token of its requests
+type WebhookConfig struct {
+	Url         string
+	SecretToken string
+}
+
+// UpdateDeduper remembers handled updates, Telegram repeats update when it did not get response in time
+type UpdateDeduper interface {
+	// Seen marks update as handled and reports whether it was handled before
+	Seen(context.Context, int) bool
+	// Forget removes mark of update which was not handled, so repeated update is handled
+	Forget(context.Context, int)
+}
+
+// Webhook registers webhook in Telegram and returns handler of its requests for rest server,
+// updates are handled by the same middlewares and pool of workers as long polling of Run
+func (s *Server) Webhook(ctx context.Context, h telegram.IHandler, cfg WebhookConfig,
+	deduper UpdateDeduper) (http.Handler, error) {
+	handler, err := NewWebhookHandler(ctx, telegram.Func(s.pool.Submit), cfg.SecretToken, deduper)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("url", cfg.Url)
+	params.Set("secret_token", cfg.SecretToken)
+	if _, err = s.client.MakeRequest("setWebhook", params); err != nil {
+		return nil, errors.Wrap(err, "set webhook")
+	}
+	logger.Info(fmt.Sprintf("Webhook is set to %s", cfg.Url))
+
+	s.pool.Start(s.wrap(h))
+
+	return handler, nil
+}
+
+// NewWebhookHandler handles updates posted by Telegram: checks secret token, skips repeated updates
+// and passes update to h, errors of h are logged like in long polling. Secret token is required,
+// webhook without it accepts updates from anyone
+func NewWebhookHandler(ctx context.Context, h telegram.IHandler, secretToken string,
+	deduper UpdateDeduper) (http.Handler, error) {
+	if secretToken == "" {
+		return nil, EmptySecretTokenError
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(SecretTokenHeader)), []byte(secretToken)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxUpdateLen)).Decode(&update); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if deduper.Seen(r.Context(), update.UpdateID) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		logUpdate(update)
+		if err := h.IncomingMessage(ctx, update); err != nil {
+			logger.Infos("error processing message: ", err)
+			// stopping replica returns update to Telegram to repeat it
+			if errors.Is(err, PoolClosedError) {
+				deduper.Forget(r.Context(), update.UpdateID)
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}), nil
+}
+
+// NewDeduper returns deduper in redis at addr shared by replicas of bot, deduper in memory of process
+// when redis is not available
+func NewDeduper(ctx context.Context, addr, password string) UpdateDeduper {
+	rdb := redis.NewClient(&redis.Options{
+		Addr:        addr,
+		Password:    password,
+		DialTimeout: dedupeDialTimeout,
+	})
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		_ = rdb.Close()
+		logger.Info(fmt.Sprintf("telegram updates are deduplicated in memory, redis is not available: %s",
+			err.Error()))
+		return NewMemoryDeduper(DedupeSize)
+	}
+
+	return NewRedisDeduper(rdb, DedupeTTL)
+}
+
+// RedisDeduper remembers update ids in redis with ttl, only one of replicas handles update
+type RedisDeduper struct {
+	rdb *redis.Client
+	ttl time.Duration
+}
+
+func NewRedisDeduper(rdb *redis.Client, ttl time.Duration) *RedisDeduper {
+	return &RedisDeduper{
+		rdb: rdb,
+		ttl: ttl,
+	}
+}
+
+// Seen reports update as not handled when redis fails, handling update twice is better than losing it
+func (d *RedisDeduper) Seen(ctx context.Context, updateId int) bool {
+	ok, err := d.rdb.SetNX(ctx, dedupeKeyPrefix+strconv.Itoa(updateId), 1, d.ttl).Result()
+	if err != nil {
+		logger.Infos("dedupe update error: ", err)
+		return false
+	}
+
+	return !ok
+}
+
+func (d *RedisDeduper) Forget(ctx context.Context, updateId int) {
+	if err := d.rdb.Del(ctx, dedupeKeyPrefix+strconv.Itoa(updateId)).Err(); err != nil {
+		logger.Infos("forget update error: ", err)
+	}
+}
+
+// MemoryDeduper remembers the latest update ids of process, it is used by single replica
+type MemoryDeduper struct {
+	mu   sync.Mutex
+	seen map[int]struct{}
+	ring []int
+	pos  int
+}
+
+// NewMemoryDeduper creates deduper remembering size latest update ids
+func NewMemoryDeduper(size int) *MemoryDeduper {
+	if size < 1 {
+		size = 1
+	}
+
+	return &MemoryDeduper{
+		seen: make(map[int]struct{}, size),
+		ring: make([]int, 0, size),
+	}
+}
+
+func (d *MemoryDeduper) Seen(_ context.Context, updateId int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.seen[updateId]; ok {
+		return true
+	}
+	if len(d.ring) < cap(d.ring) {
+		d.ring = append(d.ring, updateId)
+	} else {
+		delete(d.seen, d.ring[d.pos])
+		d.ring[d.pos] = updateId
+		d.pos = (d.pos + 1) % len(d.ring)
+	}
+	d.seen[updateId] = struct{}{}
+
+	return false
+}
+
+// Forget removes update id from seen, its slot in ring is freed with the oldest ids
+func (d *MemoryDeduper) Forget(_ context.Context, updateId int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.seen, updateId)
+}
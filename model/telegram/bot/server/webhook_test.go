@@ -0,0 +1,119 @@
+//go:build integration
+// +build integration
+
+package server
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/internal/handler/telegram"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	var handled []int
+	h := telegram.Func(func(ctx context.Context, update tgbotapi.Update) error {
+		handled = append(handled, update.UpdateID)
+		if update.UpdateID == 3 {
+			return errors.New("handler error")
+		}
+		return nil
+	})
+	_, err := NewWebhookHandler(context.Background(), h, "", NewMemoryDeduper(10))
+	assert.ErrorIs(t, err, EmptySecretTokenError)
+
+	handler, err := NewWebhookHandler(context.Background(), h, "secret", NewMemoryDeduper(10))
+	assert.NoError(t, err)
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	post := func(secret, body string) int {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(body))
+		req.Header.Set(SecretTokenHeader, secret)
+		resp, err := srv.Client().Do(req)
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		body     string
+		wantCode int
+	}{
+		{name: "Ok", secret: "secret", body: `{"update_id": 1, "message": {"from": {"id": 5}, "text": "/start"}}`, wantCode: 200},
+		{name: "Wrong secret", secret: "other", body: `{"update_id": 2}`, wantCode: 401},
+		{name: "No secret", body: `{"update_id": 2}`, wantCode: 401},
+		{name: "Repeated", secret: "secret", body: `{"update_id": 1}`, wantCode: 200},
+		{name: "Handler error is not retried", secret: "secret", body: `{"update_id": 3}`, wantCode: 200},
+		{name: "Invalid json", secret: "secret", body: `{"update_id":`, wantCode: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantCode, post(tt.secret, tt.body))
+		})
+	}
+	assert.Equal(t, []int{1, 3}, handled)
+
+	resp, err := srv.Client().Get(srv.URL)
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestWebhookHandler_PoolClosed(t *testing.T) {
+	closed := true
+	var handled []int
+	h := telegram.Func(func(ctx context.Context, update tgbotapi.Update) error {
+		if closed {
+			return errors.Wrap(PoolClosedError, "submit update")
+		}
+		handled = append(handled, update.UpdateID)
+		return nil
+	})
+	handler, err := NewWebhookHandler(context.Background(), h, "secret", NewMemoryDeduper(10))
+	assert.NoError(t, err)
+
+	post := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"update_id": 7}`))
+		req.Header.Set(SecretTokenHeader, "secret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// stopping replica refuses update, Telegram repeats it to another replica
+	assert.Equal(t, http.StatusServiceUnavailable, post())
+	closed = false
+	assert.Equal(t, http.StatusOK, post())
+	assert.Equal(t, []int{7}, handled)
+	assert.Equal(t, http.StatusOK, post())
+	assert.Equal(t, []int{7}, handled)
+}
+
+func TestMemoryDeduper_Seen(t *testing.T) {
+	ctx := context.Background()
+	d := NewMemoryDeduper(2)
+	assert.False(t, d.Seen(ctx, 1))
+	assert.False(t, d.Seen(ctx, 2))
+	assert.True(t, d.Seen(ctx, 1))
+	// the oldest id is forgotten
+	assert.False(t, d.Seen(ctx, 3))
+	assert.False(t, d.Seen(ctx, 1))
+	assert.True(t, d.Seen(ctx, 3))
+}
+
+func TestMemoryDeduper_Forget(t *testing.T) {
+	ctx := context.Background()
+	d := NewMemoryDeduper(2)
+	assert.False(t, d.Seen(ctx, 1))
+	d.Forget(ctx, 1)
+	assert.False(t, d.Seen(ctx, 1))
+	assert.True(t, d.Seen(ctx, 1))
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"database/sql"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/auth"
+	"time"
+)
+
+// Token is personal access token of api, only hash of token is kept
+type Token struct {
+	Id        int
+	UserId    int
+	Name      string
+	Hash      string
+	Scopes    auth.Scopes
+	CreatedAt time.Time
+	UsedAt    time.Time
+}
+
+type TokenDB struct {
+	Id        int          `db:"id"`
+	UserId    int          `db:"user_id"`
+	Name      string       `db:"name"`
+	Hash      string       `db:"token_hash"`
+	Scopes    string       `db:"scopes"`
+	CreatedAt time.Time    `db:"created_at"`
+	UsedAt    sql.NullTime `db:"used_at"`
+}
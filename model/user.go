@@ -1,13 +1,18 @@
 package model
 
 type User struct {
-	Id    int
-	TgId  int
-	State *State
+	Id   int
+	TgId int
+	Name string
+	// Language is chosen by /language, empty is language of telegram client
+	Language string
+	State    *State
 }
 
 type UserDB struct {
-	Id      int `db:"id"`
-	TgId    int `db:"telegram_id"`
-	StateId int `db:"state_id"`
+	Id       int    `db:"id"`
+	TgId     int    `db:"telegram_id"`
+	StateId  int    `db:"state_id"`
+	Name     string `db:"name"`
+	Language string `db:"language"`
 }
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+type WebhookEvent string
+
+const (
+	WebhookSpendingAdded   WebhookEvent = "spending.added"
+	WebhookLimitExceeded   WebhookEvent = "limit.exceeded"
+	WebhookReportGenerated WebhookEvent = "report.generated"
+)
+
+// WebhookEvents are events webhooks subscribe to, all of them by default
+var WebhookEvents = []WebhookEvent{WebhookSpendingAdded, WebhookLimitExceeded, WebhookReportGenerated}
+
+// Webhook is callback url of state, it is disabled after repeated failed deliveries in a row
+type Webhook struct {
+	Id         int
+	StateId    int
+	UserId     int
+	Url        string
+	Secret     string
+	Events     []WebhookEvent
+	Failures   int
+	CreatedAt  time.Time
+	DisabledAt time.Time
+}
+
+// Has reports whether webhook subscribes to event
+func (w Webhook) Has(event WebhookEvent) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+type WebhookDB struct {
+	Id         int          `db:"id"`
+	StateId    int          `db:"state_id"`
+	UserId     int          `db:"user_id"`
+	Url        string       `db:"url"`
+	Secret     string       `db:"secret"`
+	Events     string       `db:"events"`
+	Failures   int          `db:"failures"`
+	CreatedAt  time.Time    `db:"created_at"`
+	DisabledAt sql.NullTime `db:"disabled_at"`
+}
+
+// WebhookDelivery is attempt of delivery, StatusCode is 0 when receiver is unreachable
+type WebhookDelivery struct {
+	Id         int          `db:"id"`
+	WebhookId  int          `db:"webhook_id"`
+	DeliveryId string       `db:"delivery_id"`
+	Event      WebhookEvent `db:"event"`
+	Attempt    int          `db:"attempt"`
+	StatusCode int          `db:"status_code"`
+	Error      string       `db:"error"`
+	DurationMs int          `db:"duration_ms"`
+	CreatedAt  time.Time    `db:"created_at"`
+}
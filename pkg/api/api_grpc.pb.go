@@ -8,6 +8,7 @@ package api
 
 import (
 	context "context"
+	limit "github.com/sku4/ozon-route256-spending-bot/pkg/api/limit"
 	report "github.com/sku4/ozon-route256-spending-bot/pkg/api/report"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
@@ -25,6 +26,12 @@ const _ = grpc.SupportPackageIsVersion7
 type SpendingClient interface {
 	// Sends a greeting
 	SendReport(ctx context.Context, in *report.Report, opts ...grpc.CallOption) (*Empty, error)
+	// Returns category limits of state with spending in current period
+	GetLimits(ctx context.Context, in *limit.LimitsRequest, opts ...grpc.CallOption) (*limit.Limits, error)
+	// Adds or changes limit of category
+	SetLimit(ctx context.Context, in *limit.SetLimitRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Removes limit
+	DeleteLimit(ctx context.Context, in *limit.DeleteLimitRequest, opts ...grpc.CallOption) (*Empty, error)
 }
 
 type spendingClient struct {
@@ -44,12 +51,45 @@ func (c *spendingClient) SendReport(ctx context.Context, in *report.Report, opts
 	return out, nil
 }
 
+func (c *spendingClient) GetLimits(ctx context.Context, in *limit.LimitsRequest, opts ...grpc.CallOption) (*limit.Limits, error) {
+	out := new(limit.Limits)
+	err := c.cc.Invoke(ctx, "/api.Spending/GetLimits", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *spendingClient) SetLimit(ctx context.Context, in *limit.SetLimitRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/api.Spending/SetLimit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *spendingClient) DeleteLimit(ctx context.Context, in *limit.DeleteLimitRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/api.Spending/DeleteLimit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SpendingServer is the server API for Spending service.
 // All implementations should embed UnimplementedSpendingServer
 // for forward compatibility
 type SpendingServer interface {
 	// Sends a greeting
 	SendReport(context.Context, *report.Report) (*Empty, error)
+	// Returns category limits of state with spending in current period
+	GetLimits(context.Context, *limit.LimitsRequest) (*limit.Limits, error)
+	// Adds or changes limit of category
+	SetLimit(context.Context, *limit.SetLimitRequest) (*Empty, error)
+	// Removes limit
+	DeleteLimit(context.Context, *limit.DeleteLimitRequest) (*Empty, error)
 }
 
 // UnimplementedSpendingServer should be embedded to have forward compatible implementations.
@@ -59,6 +99,15 @@ type UnimplementedSpendingServer struct {
 func (UnimplementedSpendingServer) SendReport(context.Context, *report.Report) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendReport not implemented")
 }
+func (UnimplementedSpendingServer) GetLimits(context.Context, *limit.LimitsRequest) (*limit.Limits, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLimits not implemented")
+}
+func (UnimplementedSpendingServer) SetLimit(context.Context, *limit.SetLimitRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLimit not implemented")
+}
+func (UnimplementedSpendingServer) DeleteLimit(context.Context, *limit.DeleteLimitRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteLimit not implemented")
+}
 
 // UnsafeSpendingServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to SpendingServer will
@@ -89,6 +138,60 @@ func _Spending_SendReport_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Spending_GetLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(limit.LimitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpendingServer).GetLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Spending/GetLimits",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpendingServer).GetLimits(ctx, req.(*limit.LimitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Spending_SetLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(limit.SetLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpendingServer).SetLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Spending/SetLimit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpendingServer).SetLimit(ctx, req.(*limit.SetLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Spending_DeleteLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(limit.DeleteLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpendingServer).DeleteLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Spending/DeleteLimit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpendingServer).DeleteLimit(ctx, req.(*limit.DeleteLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Spending_ServiceDesc is the grpc.ServiceDesc for Spending service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -100,6 +203,18 @@ var Spending_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SendReport",
 			Handler:    _Spending_SendReport_Handler,
 		},
+		{
+			MethodName: "GetLimits",
+			Handler:    _Spending_GetLimits_Handler,
+		},
+		{
+			MethodName: "SetLimit",
+			Handler:    _Spending_SetLimit_Handler,
+		},
+		{
+			MethodName: "DeleteLimit",
+			Handler:    _Spending_DeleteLimit_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api.proto",
@@ -0,0 +1,654 @@
+// Code generated by protoc-gen-validate. DO NOT EDIT.
+// source: limit/limit.proto
+
+package limit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ensure the imports are used
+var (
+	_ = bytes.MinRead
+	_ = errors.New("")
+	_ = fmt.Print
+	_ = utf8.UTFMax
+	_ = (*regexp.Regexp)(nil)
+	_ = (*strings.Reader)(nil)
+	_ = net.IPv4len
+	_ = time.Duration(0)
+	_ = (*url.URL)(nil)
+	_ = (*mail.Address)(nil)
+	_ = anypb.Any{}
+	_ = sort.Sort
+)
+
+// Validate checks the field values on Limit with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Limit) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Limit with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in LimitMultiError, or nil if none found.
+func (m *Limit) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Limit) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Id
+
+	// no validation rules for CategoryId
+
+	// no validation rules for CategoryTitle
+
+	// no validation rules for Limit
+
+	// no validation rules for Spent
+
+	// no validation rules for Period
+
+	// no validation rules for Days
+
+	// no validation rules for Currency
+
+	if len(errors) > 0 {
+		return LimitMultiError(errors)
+	}
+
+	return nil
+}
+
+// LimitMultiError is an error wrapping multiple validation errors returned by
+// Limit.ValidateAll() if the designated constraints aren't met.
+type LimitMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m LimitMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m LimitMultiError) AllErrors() []error { return m }
+
+// LimitValidationError is the validation error returned by Limit.Validate if
+// the designated constraints aren't met.
+type LimitValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e LimitValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e LimitValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e LimitValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e LimitValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e LimitValidationError) ErrorName() string { return "LimitValidationError" }
+
+// Error satisfies the builtin error interface
+func (e LimitValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sLimit.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = LimitValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = LimitValidationError{}
+
+// Validate checks the field values on LimitsRequest with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *LimitsRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on LimitsRequest with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in LimitsRequestMultiError, or
+// nil if none found.
+func (m *LimitsRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *LimitsRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if m.GetStateId() <= 0 {
+		err := LimitsRequestValidationError{
+			field:  "StateId",
+			reason: "value must be greater than 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return LimitsRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// LimitsRequestMultiError is an error wrapping multiple validation errors
+// returned by LimitsRequest.ValidateAll() if the designated constraints
+// aren't met.
+type LimitsRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m LimitsRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m LimitsRequestMultiError) AllErrors() []error { return m }
+
+// LimitsRequestValidationError is the validation error returned by
+// LimitsRequest.Validate if the designated constraints aren't met.
+type LimitsRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e LimitsRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e LimitsRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e LimitsRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e LimitsRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e LimitsRequestValidationError) ErrorName() string { return "LimitsRequestValidationError" }
+
+// Error satisfies the builtin error interface
+func (e LimitsRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sLimitsRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = LimitsRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = LimitsRequestValidationError{}
+
+// Validate checks the field values on Limits with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Limits) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Limits with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in LimitsMultiError, or nil if none found.
+func (m *Limits) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Limits) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	for idx, item := range m.GetLimits() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, LimitsValidationError{
+						field:  fmt.Sprintf("Limits[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, LimitsValidationError{
+						field:  fmt.Sprintf("Limits[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return LimitsValidationError{
+					field:  fmt.Sprintf("Limits[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return LimitsMultiError(errors)
+	}
+
+	return nil
+}
+
+// LimitsMultiError is an error wrapping multiple validation errors returned by
+// Limits.ValidateAll() if the designated constraints aren't met.
+type LimitsMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m LimitsMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m LimitsMultiError) AllErrors() []error { return m }
+
+// LimitsValidationError is the validation error returned by Limits.Validate if
+// the designated constraints aren't met.
+type LimitsValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e LimitsValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e LimitsValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e LimitsValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e LimitsValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e LimitsValidationError) ErrorName() string { return "LimitsValidationError" }
+
+// Error satisfies the builtin error interface
+func (e LimitsValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sLimits.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = LimitsValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = LimitsValidationError{}
+
+// Validate checks the field values on SetLimitRequest with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *SetLimitRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on SetLimitRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// SetLimitRequestMultiError, or nil if none found.
+func (m *SetLimitRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *SetLimitRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if m.GetStateId() <= 0 {
+		err := SetLimitRequestValidationError{
+			field:  "StateId",
+			reason: "value must be greater than 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if m.GetCategoryId() <= 0 {
+		err := SetLimitRequestValidationError{
+			field:  "CategoryId",
+			reason: "value must be greater than 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if m.GetLimit() <= 0 {
+		err := SetLimitRequestValidationError{
+			field:  "Limit",
+			reason: "value must be greater than 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for Period
+
+	// no validation rules for Days
+
+	if len(errors) > 0 {
+		return SetLimitRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// SetLimitRequestMultiError is an error wrapping multiple validation errors
+// returned by SetLimitRequest.ValidateAll() if the designated constraints
+// aren't met.
+type SetLimitRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SetLimitRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SetLimitRequestMultiError) AllErrors() []error { return m }
+
+// SetLimitRequestValidationError is the validation error returned by
+// SetLimitRequest.Validate if the designated constraints aren't met.
+type SetLimitRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SetLimitRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SetLimitRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SetLimitRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SetLimitRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SetLimitRequestValidationError) ErrorName() string { return "SetLimitRequestValidationError" }
+
+// Error satisfies the builtin error interface
+func (e SetLimitRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSetLimitRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SetLimitRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SetLimitRequestValidationError{}
+
+// Validate checks the field values on DeleteLimitRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *DeleteLimitRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DeleteLimitRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// DeleteLimitRequestMultiError, or nil if none found.
+func (m *DeleteLimitRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DeleteLimitRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if m.GetStateId() <= 0 {
+		err := DeleteLimitRequestValidationError{
+			field:  "StateId",
+			reason: "value must be greater than 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if m.GetId() <= 0 {
+		err := DeleteLimitRequestValidationError{
+			field:  "Id",
+			reason: "value must be greater than 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return DeleteLimitRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// DeleteLimitRequestMultiError is an error wrapping multiple validation errors
+// returned by DeleteLimitRequest.ValidateAll() if the designated constraints
+// aren't met.
+type DeleteLimitRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DeleteLimitRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DeleteLimitRequestMultiError) AllErrors() []error { return m }
+
+// DeleteLimitRequestValidationError is the validation error returned by
+// DeleteLimitRequest.Validate if the designated constraints aren't met.
+type DeleteLimitRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DeleteLimitRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DeleteLimitRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DeleteLimitRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DeleteLimitRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DeleteLimitRequestValidationError) ErrorName() string {
+	return "DeleteLimitRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e DeleteLimitRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDeleteLimitRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DeleteLimitRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DeleteLimitRequestValidationError{}
@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+const (
+	// TokenPrefix marks personal access tokens of bot
+	TokenPrefix = "sbt_"
+
+	tokenBytes   = 32
+	headerKey    = "authorization"
+	bearerPrefix = "Bearer "
+)
+
+// Scope allows group of api methods
+type Scope string
+
+const (
+	ScopeRead  Scope = "read"
+	ScopeWrite Scope = "write"
+	// ScopeAdmin changes data shared by all states, it is not issued by /token,
+	// write tokens of admins from config get it on authentication
+	ScopeAdmin Scope = "admin"
+)
+
+// Scopes of token are stored comma separated
+type Scopes []Scope
+
+var (
+	UnknownScopeError = errors.New("unknown scope")
+	NoTokenError      = errors.New("token not found in metadata")
+)
+
+// Generate returns new random token and its hash, only the hash is stored
+func Generate() (token, hash string, err error) {
+	b := make([]byte, tokenBytes)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", errors.Wrap(err, "generate token")
+	}
+	token = TokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	return token, Hash(token), nil
+}
+
+// Hash returns hex of sha256 of token
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Equal compares tokens in constant time
+func Equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// ParseScopes reads `read`, `write` or `read,write`, write scope includes read, read only by default
+func ParseScopes(s string) (scopes Scopes, err error) {
+	for _, f := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		scope := Scope(f)
+		if scope != ScopeRead && scope != ScopeWrite {
+			return nil, errors.Wrap(UnknownScopeError, f)
+		}
+		if !scopes.Has(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return Scopes{ScopeRead}, nil
+	}
+	if scopes.Has(ScopeWrite) && !scopes.Has(ScopeRead) {
+		scopes = append(Scopes{ScopeRead}, scopes...)
+	}
+
+	return scopes, nil
+}
+
+func (s Scopes) Has(scope Scope) bool {
+	for _, sc := range s {
+		if sc == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s Scopes) String() string {
+	ss := make([]string, len(s))
+	for i, sc := range s {
+		ss[i] = string(sc)
+	}
+
+	return strings.Join(ss, ",")
+}
+
+// FromMetadata returns bearer token of incoming request, grpc-gateway passes header Authorization as is
+func FromMetadata(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", NoTokenError
+	}
+	for _, v := range md.Get(headerKey) {
+		if len(v) > len(bearerPrefix) && strings.EqualFold(v[:len(bearerPrefix)], bearerPrefix) {
+			return strings.TrimSpace(v[len(bearerPrefix):]), nil
+		}
+	}
+
+	return "", NoTokenError
+}
+
+type bearer struct {
+	token string
+}
+
+// NewCredentials returns per call credentials of grpc client with bearer token
+func NewCredentials(token string) credentials.PerRPCCredentials {
+	return bearer{token: token}
+}
+
+func (b bearer) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{headerKey: fmt.Sprintf("%s%s", bearerPrefix, b.token)}, nil
+}
+
+// RequireTransportSecurity is false, services talk inside of private network
+func (b bearer) RequireTransportSecurity() bool {
+	return false
+}
+
+// Identity is user of api token with scopes and states user has access to
+type Identity struct {
+	UserId int
+	Scopes Scopes
+	States []int
+}
+
+type ctxIdentity struct{}
+
+func ToContext(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, ctxIdentity{}, identity)
+}
+
+func FromContext(ctx context.Context) (Identity, error) {
+	if identity, ok := ctx.Value(ctxIdentity{}).(Identity); ok {
+		return identity, nil
+	}
+	return Identity{}, errors.New("identity not found in context")
+}
+
+// HasState checks that state is own state of user or shared ledger user is member of
+func (i Identity) HasState(stateId int) bool {
+	for _, st := range i.States {
+		if st == stateId {
+			return true
+		}
+	}
+
+	return false
+}
+
+type stateRequest interface {
+	GetStateId() int64
+}
+
+// statelessMethods do not belong to one state, all other methods must request own state
+var statelessMethods = map[string]Scope{
+	"/api.v1.Spending/WatchEvents":    ScopeRead,
+	"/api.v1.Spending/ListCategories": ScopeRead,
+	"/api.v1.Spending/CreateCategory": ScopeWrite,
+	"/api.v1.Spending/UpdateCategory": ScopeAdmin,
+	"/api.v1.Spending/DeleteCategory": ScopeAdmin,
+}
+
+// Authorize checks that token has scope of method and access to requested state,
+// method without state is denied unless it is stateless
+func (i Identity) Authorize(fullMethodName string, req interface{}) error {
+	scope, stateless := statelessMethods[fullMethodName]
+	if !stateless {
+		scope = MethodScope(fullMethodName)
+	}
+	if !i.Scopes.Has(scope) {
+		return status.Errorf(codes.PermissionDenied, "token has no scope '%s'", scope)
+	}
+
+	var stateId int64
+	if r, ok := req.(stateRequest); ok {
+		stateId = r.GetStateId()
+	}
+	if stateId == 0 && stateless {
+		// stateless methods check state in handler, e.g. all states of token are watched
+		return nil
+	}
+	if stateId <= 0 || !i.HasState(int(stateId)) {
+		return status.Error(codes.PermissionDenied, "no access to state")
+	}
+
+	return nil
+}
+
+// MethodScope returns read scope for methods which get, list or watch data and write scope for the rest
+func MethodScope(fullMethodName string) Scope {
+	method := fullMethodName[strings.LastIndex(fullMethodName, "/")+1:]
+	if strings.HasPrefix(method, "Get") || strings.HasPrefix(method, "List") || strings.HasPrefix(method, "Watch") {
+		return ScopeRead
+	}
+
+	return ScopeWrite
+}
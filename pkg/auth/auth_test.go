@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	token, hash, err := Generate()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, TokenPrefix))
+	assert.Equal(t, Hash(token), hash)
+	assert.NotContains(t, hash, token)
+
+	other, _, err := Generate()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := ParseScopes("")
+	assert.NoError(t, err)
+	assert.Equal(t, Scopes{ScopeRead}, scopes)
+
+	scopes, err = ParseScopes("write")
+	assert.NoError(t, err)
+	assert.Equal(t, "read,write", scopes.String())
+	assert.True(t, scopes.Has(ScopeWrite))
+
+	scopes, err = ParseScopes("read,read")
+	assert.NoError(t, err)
+	assert.False(t, scopes.Has(ScopeWrite))
+
+	_, err = ParseScopes("admin")
+	assert.ErrorIs(t, err, UnknownScopeError)
+}
+
+func TestFromMetadata(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer sbt_abc"))
+	token, err := FromMetadata(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "sbt_abc", token)
+
+	_, err = FromMetadata(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "sbt_abc")))
+	assert.ErrorIs(t, err, NoTokenError)
+
+	_, err = FromMetadata(context.Background())
+	assert.ErrorIs(t, err, NoTokenError)
+
+	md, err := NewCredentials("sbt_abc").GetRequestMetadata(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer sbt_abc", md["authorization"])
+}
+
+func TestIdentity_Authorize(t *testing.T) {
+	identity := Identity{UserId: 1, Scopes: Scopes{ScopeRead}, States: []int{5, 7}}
+
+	assert.NoError(t, identity.Authorize("/api.v1.Spending/ListEvents", &stateReq{stateId: 7}))
+	assert.Error(t, identity.Authorize("/api.v1.Spending/ListEvents", &stateReq{stateId: 8}))
+	assert.Error(t, identity.Authorize("/api.v1.Spending/CreateEvent", &stateReq{stateId: 5}))
+	assert.NoError(t, identity.Authorize("/api.v1.Spending/WatchEvents", nil))
+	assert.NoError(t, identity.Authorize("/api.v1.Spending/WatchEvents", &stateReq{stateId: 5}))
+	assert.Error(t, identity.Authorize("/api.v1.Spending/WatchEvents", &stateReq{stateId: 8}))
+	// methods of state are denied without state
+	assert.Error(t, identity.Authorize("/api.v1.Spending/ListEvents", &stateReq{}))
+	assert.Error(t, identity.Authorize("/api.v1.Spending/GetSettings", nil))
+	assert.Error(t, identity.Authorize("/api.v1.Spending/CreateCategory", nil))
+
+	identity.Scopes = Scopes{ScopeRead, ScopeWrite}
+	assert.NoError(t, identity.Authorize("/api.v1.Spending/CreateEvent", &stateReq{stateId: 5}))
+	assert.NoError(t, identity.Authorize("/api.v1.Spending/CreateCategory", nil))
+	// categories are shared by all states, only admin changes them
+	assert.Error(t, identity.Authorize("/api.v1.Spending/DeleteCategory", nil))
+
+	identity.Scopes = append(identity.Scopes, ScopeAdmin)
+	assert.NoError(t, identity.Authorize("/api.v1.Spending/DeleteCategory", nil))
+}
+
+type stateReq struct {
+	stateId int64
+}
+
+func (r *stateReq) GetStateId() int64 {
+	return r.stateId
+}
@@ -0,0 +1,161 @@
+package broadcast
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// SlowConsumerError closes subscription which buffer is full, subscriber resumes from its last cursor
+	SlowConsumerError = errors.New("slow consumer")
+	// CursorExpiredError is cursor of previous run or older than history
+	CursorExpiredError = errors.New("cursor expired")
+)
+
+// Item is published value with its sequence number
+type Item[T any] struct {
+	Seq   uint64
+	Value T
+}
+
+// Broadcaster delivers published values to subscribers in process and keeps recent history
+// to resume subscriptions after reconnects
+type Broadcaster[T any] struct {
+	mu          sync.Mutex
+	epoch       int64
+	seq         uint64
+	history     []Item[T]
+	historySize int
+	bufferSize  int
+	subs        map[*Subscription[T]]struct{}
+}
+
+// Subscription receives values matching its filter until it is closed or dropped as slow consumer
+type Subscription[T any] struct {
+	b      *Broadcaster[T]
+	ch     chan Item[T]
+	filter func(T) bool
+	err    error
+}
+
+// New creates broadcaster keeping historySize last values, bufferSize values are buffered by subscriber
+func New[T any](historySize, bufferSize int) *Broadcaster[T] {
+	return &Broadcaster[T]{
+		epoch:       time.Now().UnixNano(),
+		historySize: historySize,
+		bufferSize:  bufferSize,
+		subs:        make(map[*Subscription[T]]struct{}),
+	}
+}
+
+// Publish sends value to subscribers without blocking, subscriber with full buffer is dropped
+func (b *Broadcaster[T]) Publish(v T) uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	item := Item[T]{Seq: b.seq, Value: v}
+	b.history = append(b.history, item)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for sub := range b.subs {
+		if sub.filter != nil && !sub.filter(v) {
+			continue
+		}
+		select {
+		case sub.ch <- item:
+		default:
+			b.drop(sub, SlowConsumerError)
+		}
+	}
+
+	return item.Seq
+}
+
+// Subscribe returns subscription to values after cursor, empty cursor subscribes to new values only
+func (b *Broadcaster[T]) Subscribe(cursor string, filter func(T) bool) (*Subscription[T], error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	after, err := b.parseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if oldest := b.seq - uint64(len(b.history)); after < oldest {
+		return nil, CursorExpiredError
+	}
+
+	var backlog []Item[T]
+	for _, item := range b.history {
+		if item.Seq > after && (filter == nil || filter(item.Value)) {
+			backlog = append(backlog, item)
+		}
+	}
+	sub := &Subscription[T]{
+		b:      b,
+		ch:     make(chan Item[T], b.bufferSize+len(backlog)),
+		filter: filter,
+	}
+	for _, item := range backlog {
+		sub.ch <- item
+	}
+	b.subs[sub] = struct{}{}
+
+	return sub, nil
+}
+
+// Cursor returns position after item with seq, it is valid until restart of process
+func (b *Broadcaster[T]) Cursor(seq uint64) string {
+	return fmt.Sprintf("%d.%d", b.epoch, seq)
+}
+
+func (b *Broadcaster[T]) parseCursor(cursor string) (uint64, error) {
+	if cursor == "" {
+		return b.seq, nil
+	}
+	parts := strings.Split(cursor, ".")
+	if len(parts) != 2 || parts[0] != strconv.FormatInt(b.epoch, 10) {
+		return 0, CursorExpiredError
+	}
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || seq > b.seq {
+		return 0, CursorExpiredError
+	}
+
+	return seq, nil
+}
+
+func (b *Broadcaster[T]) drop(sub *Subscription[T], err error) {
+	delete(b.subs, sub)
+	sub.err = err
+	close(sub.ch)
+}
+
+// C returns channel of values, it is closed when subscription is closed or dropped
+func (s *Subscription[T]) C() <-chan Item[T] {
+	return s.ch
+}
+
+// Err returns reason of dropped subscription
+func (s *Subscription[T]) Err() error {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+
+	return s.err
+}
+
+// Close unsubscribes, it is safe to call after subscription is dropped
+func (s *Subscription[T]) Close() {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+
+	if _, ok := s.b.subs[s]; ok {
+		s.b.drop(s, nil)
+	}
+}
@@ -0,0 +1,64 @@
+package broadcast
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBroadcaster_Subscribe(t *testing.T) {
+	b := New[int](3, 2)
+	b.Publish(1)
+
+	sub, err := b.Subscribe("", func(v int) bool { return v%2 == 0 })
+	assert.NoError(t, err)
+	b.Publish(2)
+	b.Publish(3)
+	item := <-sub.C()
+	assert.Equal(t, Item[int]{Seq: 2, Value: 2}, item)
+	sub.Close()
+	_, ok := <-sub.C()
+	assert.False(t, ok)
+	assert.NoError(t, sub.Err())
+	sub.Close()
+
+	// resume after seq 2 replays history
+	resumed, err := b.Subscribe(b.Cursor(1), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, (<-resumed.C()).Value)
+	assert.Equal(t, 3, (<-resumed.C()).Value)
+	resumed.Close()
+}
+
+func TestBroadcaster_SlowConsumer(t *testing.T) {
+	b := New[int](10, 2)
+	sub, err := b.Subscribe("", nil)
+	assert.NoError(t, err)
+
+	b.Publish(1)
+	b.Publish(2)
+	b.Publish(3)
+	var got []int
+	for item := range sub.C() {
+		got = append(got, item.Value)
+	}
+	assert.Equal(t, []int{1, 2}, got)
+	assert.ErrorIs(t, sub.Err(), SlowConsumerError)
+}
+
+func TestBroadcaster_CursorExpired(t *testing.T) {
+	b := New[int](2, 2)
+	for i := 1; i <= 5; i++ {
+		b.Publish(i)
+	}
+
+	_, err := b.Subscribe(b.Cursor(1), nil)
+	assert.ErrorIs(t, err, CursorExpiredError)
+	_, err = b.Subscribe("1.1", nil)
+	assert.ErrorIs(t, err, CursorExpiredError)
+	_, err = b.Subscribe(b.Cursor(9), nil)
+	assert.ErrorIs(t, err, CursorExpiredError)
+
+	sub, err := b.Subscribe(b.Cursor(3), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, (<-sub.C()).Value)
+}
@@ -0,0 +1,77 @@
+package forecast
+
+import (
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+)
+
+// Input is spending of the current period so far and data known about the rest of it,
+// all maps are sums by category id
+type Input struct {
+	// Actual is spending from the start of period till today
+	Actual map[int]decimal.Decimal
+	// History is spending of previous periods of the same length
+	History []map[int]decimal.Decimal
+	// Recurring is recurring spending expected till the end of period
+	Recurring map[int]decimal.Decimal
+	// Elapsed and Total are days passed including today and days in period
+	Elapsed, Total int
+}
+
+// Project returns spending by category expected at the end of period: actual spending, known
+// recurring items and the rest days at daily rate. Daily rate is average of linear run-rate of
+// current period and daily average of previous periods, so early days of period are not overrated
+func Project(in Input) map[int]decimal.Decimal {
+	projection := make(map[int]decimal.Decimal)
+	rest := in.Total - in.Elapsed
+	if rest < 0 {
+		rest = 0
+	}
+
+	categories := make(map[int]struct{})
+	for categoryId := range in.Actual {
+		categories[categoryId] = struct{}{}
+	}
+	for categoryId := range in.Recurring {
+		categories[categoryId] = struct{}{}
+	}
+	for _, h := range in.History {
+		for categoryId := range h {
+			categories[categoryId] = struct{}{}
+		}
+	}
+
+	for categoryId := range categories {
+		rates := make([]decimal.Decimal, 0, 2)
+		if in.Elapsed > 0 {
+			rates = append(rates, in.Actual[categoryId]/decimal.Decimal(in.Elapsed))
+		}
+		if len(in.History) > 0 && in.Total > 0 {
+			var sum decimal.Decimal
+			for _, h := range in.History {
+				sum += h[categoryId]
+			}
+			rates = append(rates, sum/decimal.Decimal(len(in.History)*in.Total))
+		}
+
+		var daily decimal.Decimal
+		for _, r := range rates {
+			daily += r
+		}
+		if len(rates) > 0 {
+			daily /= decimal.Decimal(len(rates))
+		}
+
+		projection[categoryId] = in.Actual[categoryId] + in.Recurring[categoryId] + daily*decimal.Decimal(rest)
+	}
+
+	return projection
+}
+
+// Total returns sum of projection by all categories
+func Total(projection map[int]decimal.Decimal) (total decimal.Decimal) {
+	for _, sum := range projection {
+		total += sum
+	}
+
+	return
+}
@@ -0,0 +1,58 @@
+package forecast
+
+import (
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"testing"
+)
+
+func TestProject(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Input
+		want map[int]decimal.Decimal
+	}{
+		{
+			name: "run-rate only",
+			in: Input{
+				Actual:  map[int]decimal.Decimal{1: decimal.ToDecimal(100)},
+				Elapsed: 10,
+				Total:   30,
+			},
+			want: map[int]decimal.Decimal{1: decimal.ToDecimal(300)},
+		},
+		{
+			name: "run-rate with history and recurring",
+			in: Input{
+				Actual:    map[int]decimal.Decimal{1: decimal.ToDecimal(100)},
+				History:   []map[int]decimal.Decimal{{1: decimal.ToDecimal(600)}, {1: decimal.ToDecimal(0)}},
+				Recurring: map[int]decimal.Decimal{2: decimal.ToDecimal(50)},
+				Elapsed:   10,
+				Total:     30,
+			},
+			// daily (10 + 10) / 2 = 10, 100 + 20 days * 10
+			want: map[int]decimal.Decimal{1: decimal.ToDecimal(300), 2: decimal.ToDecimal(50)},
+		},
+		{
+			name: "period is over",
+			in: Input{
+				Actual:  map[int]decimal.Decimal{1: decimal.ToDecimal(100)},
+				Elapsed: 31,
+				Total:   30,
+			},
+			want: map[int]decimal.Decimal{1: decimal.ToDecimal(100)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Project(tt.in)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Project() = %v, want %v", got, tt.want)
+			}
+			for categoryId, want := range tt.want {
+				if got[categoryId] != want {
+					t.Errorf("Project()[%d] = %v, want %v", categoryId, got[categoryId], want)
+				}
+			}
+		})
+	}
+}
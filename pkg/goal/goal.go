@@ -0,0 +1,139 @@
+package goal
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"strings"
+	"time"
+)
+
+var UnknownDeadlineError = errors.New("unknown deadline")
+
+// PastDeadlineError is deadline before today
+type PastDeadlineError struct {
+	Deadline time.Time
+}
+
+func (e PastDeadlineError) Error() string {
+	return fmt.Sprintf("deadline %s is in the past", e.Deadline.Format("2 Jan 2006"))
+}
+
+// MonthsLeft returns months of contributions till deadline including month of t, at least 1 before deadline
+func MonthsLeft(t, deadline time.Time) int {
+	today := date(t)
+	if deadline.Before(today) {
+		return 0
+	}
+	n := (deadline.Year()-today.Year())*12 + int(deadline.Month()-today.Month())
+	if deadline.Day() >= today.Day() {
+		n++
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	return n
+}
+
+// Required returns monthly contribution to reach target by the end of months left,
+// rest of the smallest units is rounded up
+func Required(target, saved decimal.Decimal, monthsLeft int) decimal.Decimal {
+	rest := target - saved
+	if rest <= 0 {
+		return 0
+	}
+	if monthsLeft < 1 {
+		return rest
+	}
+
+	return (rest + decimal.Decimal(monthsLeft-1)) / decimal.Decimal(monthsLeft)
+}
+
+// OnTrack reports whether saved is not behind linear progress from created to deadline at t
+func OnTrack(target, saved decimal.Decimal, created, deadline, t time.Time) bool {
+	if saved >= target {
+		return true
+	}
+	today, start := date(t), date(created)
+	if today.After(deadline) {
+		return false
+	}
+	total := deadline.Sub(start)
+	if total <= 0 {
+		return false
+	}
+	expected := decimal.Decimal(float64(target) * float64(today.Sub(start)) / float64(total))
+
+	return saved >= expected
+}
+
+// Allocate distributes amount among needs in order, every need is filled before the next one,
+// the rest of amount over all needs is not allocated
+func Allocate(amount decimal.Decimal, needs []decimal.Decimal) []decimal.Decimal {
+	parts := make([]decimal.Decimal, len(needs))
+	for i, need := range needs {
+		if amount <= 0 {
+			break
+		}
+		if need <= 0 {
+			continue
+		}
+		if need > amount {
+			need = amount
+		}
+		parts[i] = need
+		amount -= need
+	}
+
+	return parts
+}
+
+var monthLayouts = []string{"2006-01", "January 2006", "Jan 2006", "01.2006"}
+
+// ParseDeadline parses deadline as date `2023-08-31` or month `2023-08`, `August 2023`, `Aug 2023`
+// which ends on its last day. Month without year `August` is the nearest one from t
+func ParseDeadline(s string, t time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	today := date(t)
+	deadline, err := parseDate(s, today)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if deadline.Before(today) {
+		return time.Time{}, PastDeadlineError{Deadline: deadline}
+	}
+
+	return deadline, nil
+}
+
+func parseDate(s string, today time.Time) (time.Time, error) {
+	if d, err := time.Parse("2006-01-02", s); err == nil {
+		return d, nil
+	}
+	for _, layout := range monthLayouts {
+		if m, err := time.Parse(layout, s); err == nil {
+			return endOfMonth(m.Year(), m.Month()), nil
+		}
+	}
+	for _, layout := range []string{"January", "Jan"} {
+		if m, err := time.Parse(layout, s); err == nil {
+			year := today.Year()
+			if m.Month() < today.Month() {
+				year++
+			}
+			return endOfMonth(year, m.Month()), nil
+		}
+	}
+
+	return time.Time{}, errors.Wrap(UnknownDeadlineError, s)
+}
+
+func endOfMonth(year int, month time.Month) time.Time {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+}
+
+// date returns calendar date of t as UTC midnight like dates read from database
+func date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,78 @@
+package goal
+
+import (
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMonthsLeft(t *testing.T) {
+	now := time.Date(2022, 11, 24, 15, 0, 0, 0, time.UTC)
+	assert.Equal(t, 10, MonthsLeft(now, time.Date(2023, 8, 31, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 9, MonthsLeft(now, time.Date(2023, 8, 10, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 1, MonthsLeft(now, time.Date(2022, 11, 24, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 0, MonthsLeft(now, time.Date(2022, 11, 23, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestRequired(t *testing.T) {
+	assert.Equal(t, decimal.ToDecimal(1000), Required(decimal.ToDecimal(10000), 0, 10))
+	assert.Equal(t, decimal.Decimal(4), Required(10, 0, 3))
+	assert.Equal(t, decimal.Decimal(0), Required(10, 20, 3))
+	assert.Equal(t, decimal.Decimal(10), Required(10, 0, 0))
+}
+
+func TestOnTrack(t *testing.T) {
+	created := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	deadline := time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2022, 7, 2, 10, 0, 0, 0, time.UTC)
+	target := decimal.ToDecimal(1000)
+	assert.True(t, OnTrack(target, decimal.ToDecimal(500), created, deadline, now))
+	assert.False(t, OnTrack(target, decimal.ToDecimal(400), created, deadline, now))
+	assert.True(t, OnTrack(target, target, created, deadline, deadline.AddDate(0, 1, 0)))
+	assert.False(t, OnTrack(target, decimal.ToDecimal(900), created, deadline, deadline.AddDate(0, 1, 0)))
+}
+
+func TestAllocate(t *testing.T) {
+	assert.Equal(t, []decimal.Decimal{100, 50, 0}, Allocate(150, []decimal.Decimal{100, 200, 300}))
+	assert.Equal(t, []decimal.Decimal{0, 200}, Allocate(500, []decimal.Decimal{0, 200}))
+	assert.Equal(t, []decimal.Decimal{0}, Allocate(0, []decimal.Decimal{100}))
+}
+
+func TestParseDeadline(t *testing.T) {
+	now := time.Date(2022, 11, 24, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "2023-08-15", want: time.Date(2023, 8, 15, 0, 0, 0, 0, time.UTC)},
+		{in: "2023-08", want: time.Date(2023, 8, 31, 0, 0, 0, 0, time.UTC)},
+		{in: "august 2023", want: time.Date(2023, 8, 31, 0, 0, 0, 0, time.UTC)},
+		{in: "August", want: time.Date(2023, 8, 31, 0, 0, 0, 0, time.UTC)},
+		{in: "Dec", want: time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{in: "November", want: time.Date(2022, 11, 30, 0, 0, 0, 0, time.UTC)},
+		{in: "2022-10-01", wantErr: true},
+		{in: "someday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDeadline(tt.in, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	// errors keep deadline for message in language of user
+	_, err := ParseDeadline("2022-10-01", now)
+	var past PastDeadlineError
+	assert.ErrorAs(t, err, &past)
+	assert.Equal(t, time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC), past.Deadline)
+	_, err = ParseDeadline("someday", now)
+	assert.ErrorIs(t, err, UnknownDeadlineError)
+}
@@ -0,0 +1,216 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Lang is language of messages of bot
+type Lang string
+
+const (
+	En Lang = "en"
+	Ru Lang = "ru"
+
+	// Default is language of users with unknown language code
+	Default = En
+)
+
+// Langs are supported languages in order of /language
+var Langs = []Lang{En, Ru}
+
+// Title is name of language in itself
+func (l Lang) Title() string {
+	switch l {
+	case Ru:
+		return "Русский"
+	}
+
+	return "English"
+}
+
+// Parse returns language by IETF code of Telegram like `ru` or `ru-RU`, other codes are Default
+func Parse(code string) Lang {
+	if l, ok := Lookup(code); ok {
+		return l
+	}
+
+	return Default
+}
+
+// Lookup returns supported language by code or name, ok is false for unsupported ones
+func Lookup(code string) (Lang, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	base, _, _ := strings.Cut(strings.ReplaceAll(code, "_", "-"), "-")
+	for _, l := range Langs {
+		if base == string(l) || code == strings.ToLower(l.Title()) {
+			return l, true
+		}
+	}
+
+	return "", false
+}
+
+// Printer translates messages of catalogue and formats numbers and dates of its language,
+// messages missing in catalogue are printed in English
+type Printer struct {
+	lang     Lang
+	messages map[string]string
+	plurals  map[string][]string
+}
+
+var printers = map[Lang]*Printer{
+	En: {lang: En},
+	Ru: {lang: Ru, messages: ru, plurals: ruPlurals},
+}
+
+// NewPrinter returns printer of language, unsupported languages get Default
+func NewPrinter(lang Lang) *Printer {
+	if p, ok := printers[lang]; ok {
+		return p
+	}
+
+	return printers[Default]
+}
+
+func (p *Printer) Lang() Lang {
+	return p.lang
+}
+
+// Sprintf translates format and formats floats and decimals of args by language
+func (p *Printer) Sprintf(format string, a ...interface{}) string {
+	if m, ok := p.messages[format]; ok {
+		format = m
+	}
+
+	return fmt.Sprintf(format, p.numbers(a)...)
+}
+
+// Text translates message without formatting, like help of commands
+func (p *Printer) Text(message string) string {
+	if m, ok := p.messages[message]; ok {
+		return m
+	}
+
+	return message
+}
+
+// Plural translates format chosen by plural form of n, one and other are English forms,
+// translation has forms of plural rules of language
+func (p *Printer) Plural(n int, one, other string, a ...interface{}) string {
+	if forms, ok := p.plurals[one]; ok {
+		return fmt.Sprintf(forms[pluralForm(p.lang, n)], p.numbers(a)...)
+	}
+	if pluralForm(En, n) == 0 {
+		return fmt.Sprintf(one, p.numbers(a)...)
+	}
+
+	return fmt.Sprintf(other, p.numbers(a)...)
+}
+
+// pluralForm returns index of plural form of n: one and other in English,
+// one, few and many in Russian
+func pluralForm(lang Lang, n int) int {
+	if n < 0 {
+		n = -n
+	}
+	switch lang {
+	case Ru:
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return 1
+		}
+		return 2
+	}
+	if n == 1 {
+		return 0
+	}
+
+	return 1
+}
+
+var (
+	ruMonths = []string{"январь", "февраль", "март", "апрель", "май", "июнь", "июль", "август",
+		"сентябрь", "октябрь", "ноябрь", "декабрь"}
+	// ruMonthsOf are names in genitive case used with day
+	ruMonthsOf = []string{"января", "февраля", "марта", "апреля", "мая", "июня", "июля", "августа",
+		"сентября", "октября", "ноября", "декабря"}
+	// ruMonthsShort are abbreviations in genitive case used in dates
+	ruMonthsShort = []string{"янв", "фев", "мар", "апр", "мая", "июн", "июл", "авг", "сен", "окт", "ноя", "дек"}
+	ruWeekdays    = []string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"}
+)
+
+// Date formats day like `2 Jan 06`
+func (p *Printer) Date(t time.Time) string {
+	if p.lang == Ru {
+		return fmt.Sprintf("%d %s %s", t.Day(), ruMonthsShort[t.Month()-1], t.Format("06"))
+	}
+
+	return t.Format("2 Jan 06")
+}
+
+// DateTime formats day and time like `2 Jan 06 15:04`
+func (p *Printer) DateTime(t time.Time) string {
+	return p.Date(t) + " " + t.Format("15:04")
+}
+
+// Month returns name of month like `January`
+func (p *Printer) Month(m time.Month) string {
+	if p.lang == Ru {
+		return ruMonths[m-1]
+	}
+
+	return m.String()
+}
+
+// DayMonth formats day of month like `15 March`
+func (p *Printer) DayMonth(day int, m time.Month) string {
+	if p.lang == Ru {
+		return fmt.Sprintf("%d %s", day, ruMonthsOf[m-1])
+	}
+
+	return fmt.Sprintf("%d %s", day, m)
+}
+
+// MonthShort returns abbreviation of month like `Jan` for buttons
+func (p *Printer) MonthShort(m time.Month) string {
+	if p.lang == Ru {
+		return string([]rune(ruMonths[m-1])[:3])
+	}
+
+	return m.String()[:3]
+}
+
+// MonthYear formats month like `January 2006`
+func (p *Printer) MonthYear(t time.Time) string {
+	return p.Month(t.Month()) + " " + t.Format("2006")
+}
+
+// Weekday returns name of weekday like `Sunday`
+func (p *Printer) Weekday(d time.Weekday) string {
+	if p.lang == Ru {
+		return ruWeekdays[d]
+	}
+
+	return d.String()
+}
+
+type printerKey struct{}
+
+// WithPrinter keeps printer of user in context
+func WithPrinter(ctx context.Context, p *Printer) context.Context {
+	return context.WithValue(ctx, printerKey{}, p)
+}
+
+// FromContext returns printer of user, updates without user are printed in Default language
+func FromContext(ctx context.Context) *Printer {
+	if p, ok := ctx.Value(printerKey{}).(*Printer); ok {
+		return p
+	}
+
+	return NewPrinter(Default)
+}
@@ -0,0 +1,155 @@
+package i18n
+
+import (
+	"context"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		code string
+		want Lang
+		ok   bool
+	}{
+		{code: "ru", want: Ru, ok: true},
+		{code: "ru-RU", want: Ru, ok: true},
+		{code: "RU_ru", want: Ru, ok: true},
+		{code: "Русский", want: Ru, ok: true},
+		{code: "en-GB", want: En, ok: true},
+		{code: "de", ok: false},
+		{code: "", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := Lookup(tt.code)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Lookup(%q) = %v, %v, want %v, %v", tt.code, got, ok, tt.want, tt.ok)
+		}
+	}
+	if got := Parse("de"); got != Default {
+		t.Errorf("Parse() = %v, want %v", got, Default)
+	}
+}
+
+func TestPrinter_Plural(t *testing.T) {
+	ru := NewPrinter(Ru)
+	tests := map[int]string{
+		1:  "1 месяц",
+		2:  "2 месяца",
+		5:  "5 месяцев",
+		11: "11 месяцев",
+		12: "12 месяцев",
+		21: "21 месяц",
+		22: "22 месяца",
+		25: "25 месяцев",
+	}
+	for n, want := range tests {
+		if got := ru.Plural(n, "%d month", "%d months", n); got != want {
+			t.Errorf("Plural(%d) = %q, want %q", n, got, want)
+		}
+	}
+
+	en := NewPrinter(En)
+	if got := en.Plural(1, "%d month", "%d months", 1); got != "1 month" {
+		t.Errorf("Plural(1) = %q, want %q", got, "1 month")
+	}
+	if got := en.Plural(21, "%d month", "%d months", 21); got != "21 months" {
+		t.Errorf("Plural(21) = %q, want %q", got, "21 months")
+	}
+}
+
+func TestPrinter_Sprintf(t *testing.T) {
+	ru := NewPrinter(Ru)
+	if got, want := ru.Sprintf("Total: *%.2f %s*", 1234.5, "USD"), "Итого: *1\u00a0234,50 USD*"; got != want {
+		t.Errorf("Sprintf() = %q, want %q", got, want)
+	}
+	if got, want := ru.Sprintf("%.2f", -1234567.0), "-1\u00a0234\u00a0567,00"; got != want {
+		t.Errorf("Sprintf() = %q, want %q", got, want)
+	}
+	if got, want := ru.Sprintf("%.2f", decimal.ToDecimal(1500.25)), "1\u00a0500,25"; got != want {
+		t.Errorf("Sprintf() decimal = %q, want %q", got, want)
+	}
+	if got, want := ru.Sprintf("%d%%", 1500), "1500%"; got != want {
+		t.Errorf("Sprintf() int = %q, want %q", got, want)
+	}
+	if got, want := ru.Sprintf("not in catalogue %.1f", 0.5), "not in catalogue 0,5"; got != want {
+		t.Errorf("Sprintf() = %q, want %q", got, want)
+	}
+
+	en := NewPrinter(En)
+	if got, want := en.Sprintf("Total: *%.2f %s*", 1234.5, "USD"), "Total: *1234.50 USD*"; got != want {
+		t.Errorf("Sprintf() = %q, want %q", got, want)
+	}
+}
+
+func TestPrinter_Date(t *testing.T) {
+	d := time.Date(2022, time.May, 2, 15, 4, 0, 0, time.UTC)
+	ru, en := NewPrinter(Ru), NewPrinter(En)
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "ru date", got: ru.Date(d), want: "2 мая 22"},
+		{name: "en date", got: en.Date(d), want: "2 May 22"},
+		{name: "ru date time", got: ru.DateTime(d), want: "2 мая 22 15:04"},
+		{name: "ru month year", got: ru.MonthYear(d), want: "май 2022"},
+		{name: "en month year", got: en.MonthYear(d), want: "May 2022"},
+		{name: "ru month short", got: ru.MonthShort(time.January), want: "янв"},
+		{name: "en month short", got: en.MonthShort(time.January), want: "Jan"},
+		{name: "ru day month", got: ru.DayMonth(15, time.March), want: "15 марта"},
+		{name: "ru weekday", got: ru.Weekday(time.Monday), want: "понедельник"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if got := FromContext(context.Background()).Lang(); got != Default {
+		t.Errorf("FromContext() = %v, want %v", got, Default)
+	}
+	ctx := WithPrinter(context.Background(), NewPrinter(Ru))
+	if got := FromContext(ctx).Lang(); got != Ru {
+		t.Errorf("FromContext() = %v, want %v", got, Ru)
+	}
+}
+
+var verbs = regexp.MustCompile(`%[-+# 0]*\d*(\.\d+)?[a-zA-Z%]`)
+
+// translations must keep verbs of message in order and its leading and trailing whitespace
+func TestCatalogue(t *testing.T) {
+	check := func(key, message string) {
+		got, want := verbs.FindAllString(message, -1), verbs.FindAllString(key, -1)
+		if strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("%q has verbs %v, want %v", message, got, want)
+		}
+		if leading(key) != leading(message) || trailing(key) != trailing(message) {
+			t.Errorf("%q has other leading or trailing whitespace than %q", message, key)
+		}
+	}
+	for key, message := range ru {
+		check(key, message)
+	}
+	for one, forms := range ruPlurals {
+		if len(forms) != 3 {
+			t.Errorf("%q has %d forms, want 3", one, len(forms))
+		}
+		for _, form := range forms {
+			check(one, form)
+		}
+	}
+}
+
+func leading(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \r\n"))]
+}
+
+func trailing(s string) string {
+	return s[len(strings.TrimRight(s, " \r\n")):]
+}
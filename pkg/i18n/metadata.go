@@ -0,0 +1,26 @@
+package i18n
+
+import (
+	"context"
+	"google.golang.org/grpc/metadata"
+)
+
+const headerKey = "accept-language"
+
+// ToMetadata passes language of user to outgoing grpc request
+func ToMetadata(ctx context.Context, lang Lang) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, headerKey, string(lang))
+}
+
+// FromMetadata returns language of incoming grpc request, Default when it is not passed
+func FromMetadata(ctx context.Context) Lang {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return Default
+	}
+	if v := md.Get(headerKey); len(v) > 0 {
+		return Parse(v[0])
+	}
+
+	return Default
+}
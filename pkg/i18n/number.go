@@ -0,0 +1,78 @@
+package i18n
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// number prints float or decimal by separators of language,
+// English keeps plain notation of fmt
+type number struct {
+	value interface{}
+}
+
+// numbers wraps floats and formatters like decimal.Decimal of args
+func (p *Printer) numbers(a []interface{}) []interface{} {
+	if p.lang == En {
+		return a
+	}
+	args := make([]interface{}, len(a))
+	for i, arg := range a {
+		switch arg.(type) {
+		case float32, float64, fmt.Formatter:
+			args[i] = number{value: arg}
+		default:
+			args[i] = arg
+		}
+	}
+
+	return args
+}
+
+func (n number) Format(state fmt.State, verb rune) {
+	spec := "%"
+	for _, flag := range "+-# 0" {
+		if state.Flag(int(flag)) {
+			spec += string(flag)
+		}
+	}
+	if width, ok := state.Width(); ok {
+		spec += strconv.Itoa(width)
+	}
+	if precision, ok := state.Precision(); ok {
+		spec += "." + strconv.Itoa(precision)
+	}
+	s := fmt.Sprintf(spec+string(verb), n.value)
+	if strings.ContainsRune("vfFg", verb) {
+		s = localize(s)
+	}
+	_, _ = state.Write([]byte(s))
+}
+
+// localize groups thousands by non-breaking space and uses decimal comma, other text is kept
+func localize(s string) string {
+	trimmed := strings.TrimSpace(s)
+	sign := ""
+	if strings.HasPrefix(trimmed, "-") || strings.HasPrefix(trimmed, "+") {
+		sign, trimmed = trimmed[:1], trimmed[1:]
+	}
+	whole, frac, hasFrac := strings.Cut(trimmed, ".")
+	if whole == "" || strings.Trim(whole, "0123456789") != "" || strings.Trim(frac, "0123456789") != "" {
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, c := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString("\u00a0")
+		}
+		b.WriteRune(c)
+	}
+	if hasFrac {
+		b.WriteString("," + frac)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,364 @@
+package i18n
+
+// ru is Russian catalogue, key is English message
+var ru = map[string]string{
+	// commands
+	"Available commands:":                    "Доступные команды:",
+	"Command not found":                      "Команда не найдена",
+	" (also %s)":                             " (также %s)",
+	"Too many requests, please repeat later": "Слишком много запросов, повторите позже",
+	"Tokens are personal, write /token in private chat with bot": "Токены личные, " +
+		"напишите /token в личном чате с ботом",
+	"Secret of webhook is personal, write /webhook in private chat with bot": "Секрет вебхука личный, " +
+		"напишите /webhook в личном чате с ботом",
+	"commands of bot":                       "команды бота",
+	"list categories or add one":            "список категорий или добавить категорию",
+	"where Food is category name":           "где Food - название категории",
+	"where 100 is price":                    "где 100 - цена",
+	"report by current week":                "отчёт за текущую неделю",
+	"report by current month with forecast": "отчёт за текущий месяц с прогнозом",
+	"report by current year":                "отчёт за текущий год",
+	"change currency":                       "сменить валюту",
+	"limit category by sum spending per `day`, `week`, `month` (default), `year` or `days 10`": "лимит трат " +
+		"категории за `day` (день), `week` (неделю), `month` (месяц, по умолчанию), `year` (год) или `days 10` (10 дней)",
+	"limits with spending, change or remove them":  "лимиты с тратами, изменить или удалить их",
+	"warn when spending reaches percents of limit": "предупреждать, когда траты достигают процентов лимита",
+	"weekday weeks begin on":                       "день недели, с которого начинается неделя",
+	"day months begin on, for example payday":      "день, с которого начинается месяц, например день зарплаты",
+	"time zone of dates and reports, also set by shared location": "часовой пояс дат и отчётов, " +
+		"также задаётся отправкой геопозиции",
+	"cash, cards and other accounts":                          "наличные, карты и другие счета",
+	"where 100 is opening balance":                            "где 100 - начальный баланс",
+	"transfer between accounts":                               "перевод между счетами",
+	"current balances of accounts and who owes whom in group": "текущие балансы счетов и кто кому должен в группе",
+	"split your last spending in group equally, by shares `Anna:2` or amounts `Anna=500`": "разделить вашу " +
+		"последнюю трату в группе поровну, по долям `Anna:2` или суммами `Anna=500`",
+	"minimal transfers to settle up debts of group": "минимум переводов, чтобы закрыть долги группы",
+	"monthly spending on day 10, also `week mon` or `year 03-15`": "ежемесячная трата 10-го числа, " +
+		"также `week mon` (еженедельно) или `year 03-15` (ежегодно)",
+	"total budget of month, unused money is carried to next month": "общий бюджет месяца, " +
+		"неизрасходованные деньги переносятся на следующий месяц",
+	"plan category of month, /plan shows planned, actual and difference": "план категории на месяц, " +
+		"/plan показывает план, факт и разницу",
+	"weekly report every sunday at 20:00, also `month` or `year`": "еженедельный отчёт каждое воскресенье " +
+		"в 20:00, также `month` (ежемесячно) или `year` (ежегодно)",
+	"savings goal, `/goal Vacation +5000` logs contribution": "цель накоплений, " +
+		"`/goal Vacation +5000` добавляет взнос",
+	"api token in private chat, read only without `write`, /token revokes": "токен api в личном чате, " +
+		"только чтение без `write`, /token отзывает",
+	"post signed events to url, /webhook deletes": "отправлять подписанные события на url, /webhook удаляет",
+	"language of bot, English or Russian":         "язык бота, английский или русский",
+
+	// dialogs
+	"Buttons are outdated, please start again with /%s":           "Кнопки устарели, начните заново с /%s",
+	"These buttons were opened by another member, please use /%s": "Эти кнопки открыл другой участник, используйте /%s",
+	"Time to answer is over, please start again with /%s":         "Время ответа истекло, начните заново с /%s",
+	"Cancelled": "Отменено",
+	"<< Back":   "<< Назад",
+	"Cancel":    "Отмена",
+	"Add":       "Добавить",
+	"List":      "Список",
+	"Delete":    "Удалить",
+	"Undo":      "Отменить",
+
+	// periods
+	"day":               "день",
+	"week":              "неделю",
+	"month":             "месяц",
+	"year":              "год",
+	"Day":               "День",
+	"Week":              "Неделя",
+	"Month":             "Месяц",
+	"Year":              "Год",
+	"weekly":            "еженедельный",
+	"monthly":           "ежемесячный",
+	"yearly":            "ежегодный",
+	"weekly on %s":      "еженедельно, %s",
+	"monthly on day %d": "ежемесячно %d-го числа",
+	"yearly on %s":      "ежегодно %s",
+
+	// spending
+	"Choose category (*%.2f %s*):":       "Выберите категорию (*%.2f %s*):",
+	"Without account":                    "Без счёта",
+	"Choose account (*%.2f %s* > *%s*):": "Выберите счёт (*%.2f %s* > *%s*):",
+	"Choose date or write it like `%s` (*%.2f %s* > *%s*):": "Выберите дату или напишите её как `%s` " +
+		"(*%.2f %s* > *%s*):",
+	"Today":                                         "Сегодня",
+	"Choose date":                                   "Выбрать дату",
+	"Error date '*%s*', write it like `%s`":         "Ошибка даты '*%s*', напишите её как `%s`",
+	"Choose years (*%.2f %s* > *%s*):":              "Выберите год (*%.2f %s* > *%s*):",
+	"Choose months (*%.2f %s* > *%s* > *%d*):":      "Выберите месяц (*%.2f %s* > *%s* > *%d*):",
+	"Choose days (*%.2f %s* > *%s* > *%d* > *%s*):": "Выберите день (*%.2f %s* > *%s* > *%d* > *%s*):",
+	"Rate not found: %s":                            "Курс не найден: %s",
+	"Error add event: %s":                           "Ошибка добавления траты: %s",
+	" from *%s*":                                    " со счёта *%s*",
+	"Event with price *%v %s* on *%s* success added to *%s*%s\r\n%sShow /report7 /report31 /report365": "Трата " +
+		"*%v %s* за *%s* добавлена в *%s*%s\r\n%sПоказать /report7 /report31 /report365",
+	"Remaining *%.2f %s* of *%.2f %s* per *%s*":      "Осталось *%.2f %s* из *%.2f %s* за *%s*",
+	"Limit *%.2f %s* per *%s* exceeded by *%.2f %s*": "Лимит *%.2f %s* за *%s* превышен на *%.2f %s*",
+	"Sum *%.2f %s* by category *%s* over than *%.2f %s* per *%s*": "Сумма *%.2f %s* по категории *%s* " +
+		"больше *%.2f %s* за *%s*",
+	"Spent *%d%%* of limit by category *%s*: *%.2f* of *%.2f %s* per *%s*": "Потрачено *%d%%* лимита " +
+		"по категории *%s*: *%.2f* из *%.2f %s* за *%s*",
+	"Spending by category *%s* is projected to *%.2f %s* by the end of *%s*, over limit *%.2f %s*": "Траты " +
+		"по категории *%s* по прогнозу составят *%.2f %s* на конец периода (*%s*), сверх лимита *%.2f %s*",
+
+	// categories and currency
+	"Choose categories command:":                       "Выберите команду категорий:",
+	"Write title of category:":                         "Напишите название категории:",
+	"Categories: %s":                                   "Категории: %s",
+	"Categories list is empty":                         "Список категорий пуст",
+	"Categories list:":                                 "Список категорий:",
+	"Category title is empty, please write title":      "Название категории пустое, напишите название",
+	"Error add category *%s*: %s":                      "Ошибка добавления категории *%s*: %s",
+	"Category *%s* success added\r\nShow /categories":  "Категория *%s* добавлена\r\nПоказать /categories",
+	"Category title is empty, please set title":        "Название категории пустое, укажите название",
+	"Categories list is empty, please add /categories": "Список категорий пуст, добавьте /categories",
+	"Category not found: %s":                           "Категория не найдена: %s",
+	"Change currency:":                                 "Смените валюту:",
+	"Currency not found: %s":                           "Валюта не найдена: %s",
+	"Currency success changed to *%s*\r\nShow /report7 /report31 /report365": "Валюта изменена на *%s*\r\n" +
+		"Показать /report7 /report31 /report365",
+
+	// accounts
+	"Choose accounts command:":                 "Выберите команду счетов:",
+	"Rates not loaded, please repeat later":    "Курсы не загружены, повторите позже",
+	"Account title is empty, please set title": "Название счёта пустое, укажите название",
+	"Rate *%s* not found":                      "Курс *%s* не найден",
+	"Error add account *%s*: %s":               "Ошибка добавления счёта *%s*: %s",
+	"Account *%s* with balance *%.2f %s* success added\r\nShow /accounts /balance": "Счёт *%s* с балансом " +
+		"*%.2f %s* добавлен\r\nПоказать /accounts /balance",
+	"Write `/accountadd Cash USD 100` to added account": "Напишите `/accountadd Cash USD 100`, чтобы добавить счёт",
+	"Accounts list:":          "Список счетов:",
+	"Account not deleted: %s": "Счёт не удалён: %s",
+	"Account success deleted": "Счёт удалён",
+	"Accounts: %s":            "Счета: %s",
+	"Accounts list is empty, write `/accountadd Cash USD 100` to added account": "Список счетов пуст, " +
+		"напишите `/accountadd Cash USD 100`, чтобы добавить счёт",
+	"Account not found: %s":      "Счёт не найден: %s",
+	"Account *%s* (*%s*)":        "Счёт *%s* (*%s*)",
+	"Error convert price '*%s*'": "Ошибка преобразования цены '*%s*'",
+	"Please set price over 0":    "Укажите цену больше 0",
+	"Transfer needs at least two accounts, please add /accounts": "Для перевода нужно хотя бы два счёта, " +
+		"добавьте /accounts",
+	"Transfer from (*%.2f %s*):": "Перевод со счёта (*%.2f %s*):",
+	"Error add transfer: %s":     "Ошибка добавления перевода: %s",
+	"Transfer *%.2f %s* from *%s* to *%s* success added\r\nShow /balance": "Перевод *%.2f %s* со счёта *%s* " +
+		"на *%s* добавлен\r\nПоказать /balance",
+	"Transfer to (*%.2f %s* > *%s*):":              "Перевод на счёт (*%.2f %s* > *%s*):",
+	"Accounts list is empty, please add /accounts": "Список счетов пуст, добавьте /accounts",
+	"Balance:":         "Баланс:",
+	"Total: *%.2f %s*": "Итого: *%.2f %s*",
+
+	// budget
+	"Error convert budget '*%s*', example `/budget 50000 rollover`": "Ошибка преобразования бюджета '*%s*', " +
+		"пример `/budget 50000 rollover`",
+	"Unknown option '*%s*', use `rollover` or `norollover`": "Неизвестный параметр '*%s*', " +
+		"используйте `rollover` или `norollover`",
+	"Budget not set: %s": "Бюджет не задан: %s",
+	"off":                "выкл",
+	"on":                 "вкл",
+	"Budget for *%s*: *%.2f %s*, carried *%.2f %s*, rollover %s\r\nSpent *%.2f %s*, left *%.2f %s*\r\n" +
+		"Show /plan, change by `/budget 50000 rollover`": "Бюджет на *%s*: *%.2f %s*, перенесено *%.2f %s*, " +
+		"перенос %s\r\nПотрачено *%.2f %s*, осталось *%.2f %s*\r\nПоказать /plan, изменить `/budget 50000 rollover`",
+	"Error convert plan '*%s*', example `/plan 5000`": "Ошибка преобразования плана '*%s*', пример `/plan 5000`",
+	"Choose category to plan *%.2f*, zero removes plan:": "Выберите категорию для плана *%.2f*, " +
+		"ноль удаляет план:",
+	"Plan not set: %s":                            "План не задан: %s",
+	"Plan for *%s* (%s):\n":                       "План на *%s* (%s):\n",
+	"_%s_ - plan %.2f, actual %.2f, diff %s\n":    "_%s_ - план %.2f, факт %.2f, разница %s\n",
+	"*Total* - plan %.2f, actual %.2f, diff %s\n": "*Итого* - план %.2f, факт %.2f, разница %s\n",
+	"nothing planned yet, write `/plan 5000` to plan category\n": "пока ничего не запланировано, " +
+		"напишите `/plan 5000`, чтобы запланировать категорию\n",
+	"Budget *%.2f %s* (carried %.2f), unallocated *%.2f %s*\nChange by /budget": "Бюджет *%.2f %s* " +
+		"(перенесено %.2f), не распределено *%.2f %s*\nИзменить /budget",
+
+	// digest
+	"Unknown period '*%s*', use `week`, `month` or `year`": "Неизвестный период '*%s*', " +
+		"используйте `week`, `month` или `year`",
+	"Error time '*%s*', example `/digest week 20:00`": "Ошибка времени '*%s*', пример `/digest week 20:00`",
+	"Error add digest: %s":                            "Ошибка подписки на сводку: %s",
+	"Digest %s at *%s* success subscribed, next on *%s*\r\nShow /digest": "Подписка на %s отчёт в *%s* " +
+		"оформлена, следующий *%s*\r\nПоказать /digest",
+	"Digest not cancelled: %s": "Подписка не отменена: %s",
+	"Digest list is empty, write `/digest week 20:00` to receive weekly report on the last day of week at 20:00, " +
+		"also `month` or `year`": "Список подписок пуст, напишите `/digest week 20:00`, чтобы получать " +
+		"еженедельный отчёт в последний день недели в 20:00, также `month` или `year`",
+	"Digest list, choose to cancel:":      "Список подписок, выберите для отмены:",
+	"%d. _%s_ at %02d:%02d, next on %s\n": "%d. _%s_ в %02d:%02d, следующий %s\n",
+	"Cancel %d. %s":                       "Отменить %d. %s",
+
+	// goals
+	"_%s_ - %.2f of %.2f %s (%d%%) by %s": "_%s_ - %.2f из %.2f %s (%d%%) к %s",
+	" - reached\n":                        " - достигнута\n",
+	" - deadline passed\n":                " - срок прошёл\n",
+	"on track":                            "в графике",
+	"behind":                              "отстаёт",
+	", %.2f %s a month for %s - %s\n":     ", %.2f %s в месяц в течение %s - %s\n",
+	"Goal not deleted: %s":                "Цель не удалена: %s",
+	"Goal success deleted":                "Цель удалена",
+	"Goals list is empty, write `/goal Vacation 150000 EUR by August` to add goal": "Список целей пуст, " +
+		"напишите `/goal Vacation 150000 EUR by August`, чтобы добавить цель",
+	"Goals:":                               "Цели:",
+	"Contribute by `/goal Vacation +5000`": "Внести взнос `/goal Vacation +5000`",
+	"Delete %s":                            "Удалить %s",
+	"Goal title or amount is empty, example `/goal Vacation 150000 EUR by August`": "Название или сумма цели " +
+		"пустые, пример `/goal Vacation 150000 EUR by August`",
+	"Error convert amount '*%s*', set amount over 0": "Ошибка преобразования суммы '*%s*', укажите сумму больше 0",
+	"Error deadline: %s, example `by August`, `by 2023-08-31`": "Ошибка срока: %s, пример `by August`, " +
+		"`by 2023-08-31`",
+	"deadline %s is in the past": "срок %s уже прошёл",
+	"unknown deadline '%s'":      "неизвестный срок '%s'",
+	"Error add goal *%s*: %s":    "Ошибка добавления цели *%s*: %s",
+	"Goal *%s* - *%.2f %s* by *%s* success added, save *%.2f %s* a month for %s\r\nShow /goal": "Цель *%s* - " +
+		"*%.2f %s* к *%s* добавлена, откладывайте *%.2f %s* в месяц в течение %s\r\nПоказать /goal",
+	"Error contribution, example `/goal Vacation +5000` or `/goal Vacation 150000 EUR by August` to add goal": "" +
+		"Ошибка взноса, пример `/goal Vacation +5000` или `/goal Vacation 150000 EUR by August`, чтобы добавить цель",
+	"Goal *%s* not found, show /goal": "Цель *%s* не найдена, показать /goal",
+	"Error add contribution: %s":      "Ошибка добавления взноса: %s",
+	"Contribution *%.2f %s* to goal *%s* success added\r\nShow /goal": "Взнос *%.2f %s* в цель *%s* " +
+		"добавлен\r\nПоказать /goal",
+
+	// language
+	"Unknown language '*%s*', example `/language ru`": "Неизвестный язык '*%s*', пример `/language ru`",
+	"Language *%s*\r\nChange by `/language ru`, available %s or `auto` for language of telegram": "Язык " +
+		"*%s*\r\nИзменить `/language en`, доступны %s или `auto` для языка telegram",
+
+	// limits
+	"Error period: %s\r\nExamples: `/limit 100 day`, `/limit 100 week`, `/limit 100 days 10`": "Ошибка периода: " +
+		"%s\r\nПримеры: `/limit 100 day`, `/limit 100 week`, `/limit 100 days 10`",
+	"Choose category limit (*%.2f %s* per *%s*):": "Выберите категорию лимита (*%.2f %s* за *%s*):",
+	"Limit not add: %s":                           "Лимит не добавлен: %s",
+	"Limit *%.2f %s* per *%s* for category *%s* success added": "Лимит *%.2f %s* за *%s* для категории *%s* " +
+		"добавлен",
+	"Error threshold '*%s*', set percents from 1 to %d": "Ошибка порога '*%s*', укажите проценты от 1 до %d",
+	"Too many thresholds, set up to %d percents":        "Слишком много порогов, укажите не больше %d",
+	"Thresholds not set: %s":                            "Пороги не заданы: %s",
+	"Limit warnings at *%s*\r\nChange by `/thresholds 50 80 100`": "Предупреждения о лимитах при *%s*\r\n" +
+		"Изменить `/thresholds 50 80 100`",
+	"Limit not found, show /limits": "Лимит не найден, показать /limits",
+	"Limit not removed: %s":         "Лимит не удалён: %s",
+	"Limits list is empty, write `/limit 100 week` to add limit": "Список лимитов пуст, " +
+		"напишите `/limit 100 week`, чтобы добавить лимит",
+	"Limits list, choose to change or remove:":  "Список лимитов, выберите для изменения или удаления:",
+	"%d. _%s_ - %.2f of %.2f %s per %s\n`%s`\n": "%d. _%s_ - %.2f из %.2f %s за %s\n`%s`\n",
+	"Change %d. %s": "Изменить %d. %s",
+	"Remove %d. %s": "Удалить %d. %s",
+	"Limit *%s* - %.2f of *%.2f %s* per *%s*\n`%s`\nChange sum or period:": "Лимит *%s* - %.2f из *%.2f %s* " +
+		"за *%s*\n`%s`\nИзмените сумму или период:",
+
+	// recurring
+	"Error schedule: %s\r\nExamples: `month 10`, `week mon`, `year 03-15`": "Ошибка расписания: %s\r\n" +
+		"Примеры: `month 10`, `week mon`, `year 03-15`",
+	"Choose category (*%.2f %s* %s):": "Выберите категорию (*%.2f %s* %s):",
+	"Error add recurring: %s":         "Ошибка добавления регулярной траты: %s",
+	"Recurring *%.2f %s* to *%s* %s success added, next on *%s*\r\nShow /recurring": "Регулярная трата " +
+		"*%.2f %s* в *%s* %s добавлена, следующая *%s*\r\nПоказать /recurring",
+	"Recurring not deleted: %s":         "Регулярная трата не удалена: %s",
+	"Event already undone or not found": "Трата уже отменена или не найдена",
+	"Event not deleted: %s":             "Трата не удалена: %s",
+	"Recurring event undone":            "Регулярная трата отменена",
+	"Recurring list is empty, write `/recurring 500 month 10` to added monthly spending on day 10": "Список " +
+		"регулярных трат пуст, напишите `/recurring 500 month 10`, чтобы добавить ежемесячную трату 10-го числа",
+	"Recurring list, choose to delete:": "Список регулярных трат, выберите для удаления:",
+	"Delete %d. %s":                     "Удалить %d. %s",
+	"Recurring event with price *%.2f %s* on *%s* success added to *%s*": "Регулярная трата *%.2f %s* " +
+		"за *%s* добавлена в *%s*",
+
+	// reports
+	"*By member:*":                                   "*По участникам:*",
+	"recurring and other":                            "регулярные и другие",
+	"*Forecast by the end of month:*":                "*Прогноз на конец месяца:*",
+	" - over limit *%.2f %s*":                        " - сверх лимита *%.2f %s*",
+	"*Total* - %.2f %s\n":                            "*Итого* - %.2f %s\n",
+	"*Goals:*":                                       "*Цели:*",
+	"Report by week (*%s - %s*): spending not found": "Отчёт за неделю (*%s - %s*): траты не найдены",
+	"Report by week (*%s - %s*):":                    "Отчёт за неделю (*%s - %s*):",
+
+	// split
+	"Spending is split among members of group chat, add the bot to group": "Траты делятся между участниками " +
+		"группового чата, добавьте бота в группу",
+	"%s, example `/split Anna Bob`, `/split Anna:2 Bob:1` or `/split Anna=500 Bob=700 USD`": "%s, пример " +
+		"`/split Anna Bob`, `/split Anna:2 Bob:1` или `/split Anna=500 Bob=700 USD`",
+	"Your spending not found, please add by /spendingadd": "Ваши траты не найдены, добавьте /spendingadd",
+	"Spending not split: %s":                              "Трата не разделена: %s",
+	"Group members not found":                             "Участники группы не найдены",
+	"Error argument '*%s*'":                               "Ошибка аргумента '*%s*'",
+	"Member '*%s*' not found":                             "Участник '*%s*' не найден",
+	"Member '*%s*' is repeated":                           "Участник '*%s*' указан повторно",
+	"Error amount '*%s*'":                                 "Ошибка суммы '*%s*'",
+	"Error share '*%s*', set share from 1":                "Ошибка доли '*%s*', укажите долю от 1",
+	"Currency *%s* not found":                             "Валюта *%s* не найдена",
+	"Error amounts, set amounts from 0":                   "Ошибка сумм, укажите суммы от 0",
+	"Amounts sum to %.2f %s, spending is %.2f %s":         "Сумма долей %.2f %s, а трата %.2f %s",
+	"Your last spending is already split, show /balance":  "Ваша последняя трата уже разделена, показать /balance",
+	"Spending *%.2f %s* (_%s_, %s) split:":                "Трата *%.2f %s* (_%s_, %s) разделена:",
+	"Show /balance or /settle":                            "Показать /balance или /settle",
+	"Transfer is outdated, settle plan is updated":        "Перевод устарел, план расчётов обновлён",
+	"Settlement not added: %s":                            "Расчёт не добавлен: %s",
+	"Settlement success added":                            "Расчёт добавлен",
+	"All debts are settled up":                            "Все долги закрыты",
+	"Transfers to settle up:":                             "Переводы для расчёта:",
+	"_%s_ pays _%s_ - %.2f %s\n":                          "_%s_ платит _%s_ - %.2f %s\n",
+	"%s paid %s %.2f":                                     "%s оплатил(а) %s %.2f",
+	"Press transfer when it is paid":                      "Нажмите перевод, когда он оплачен",
+	"_%s_ owes _%s_ - %.2f %s\n":                          "_%s_ должен(а) _%s_ - %.2f %s\n",
+	"Who owes whom:":                                      "Кто кому должен:",
+	"Settle up by /settle":                                "Рассчитаться /settle",
+
+	// start and time zone
+	"Unknown weekday '*%s*', example `/weekstart sun`": "Неизвестный день недели '*%s*', пример `/weekstart sun`",
+	"Week start not set: %s":                           "Начало недели не задано: %s",
+	"Weeks begin on *%s*, current week *%s - %s*\r\nChange by `/weekstart sun`": "Недели начинаются " +
+		"в *%s*, текущая неделя *%s - %s*\r\nИзменить `/weekstart sun`",
+	"Error day '*%s*', set day from 1 to %d": "Ошибка дня '*%s*', укажите день от 1 до %d",
+	"Month start not set: %s":                "Начало месяца не задано: %s",
+	"Months begin on day *%d*, current month *%s - %s*\r\nChange by `/monthstart 10`": "Месяцы начинаются " +
+		"*%d*-го числа, текущий месяц *%s - %s*\r\nИзменить `/monthstart 10`",
+	"Unknown time zone '*%s*', example `/timezone Europe/Moscow`": "Неизвестный часовой пояс '*%s*', " +
+		"пример `/timezone Europe/Moscow`",
+	" (server)": " (сервер)",
+	"Time zone *%s*, now *%s*\r\nChange by `/timezone Europe/Moscow` or share location": "Часовой пояс *%s*, " +
+		"сейчас *%s*\r\nИзменить `/timezone Europe/Moscow` или отправьте геопозицию",
+
+	// tokens
+	"Token not revoked: %s": "Токен не отозван: %s",
+	"Token success revoked": "Токен отозван",
+	"Tokens list is empty, write `/token Sheets` to issue read only token or `/token Sheets write` to issue " +
+		"token which also changes data": "Список токенов пуст, напишите `/token Sheets`, чтобы выпустить токен " +
+		"только для чтения, или `/token Sheets write`, чтобы выпустить токен, который также изменяет данные",
+	"Api tokens:":                            "Токены api:",
+	"never used":                             "не использовался",
+	"used %s":                                "использован %s",
+	"*%s* - %s, created %s, %s\n":            "*%s* - %s, создан %s, %s\n",
+	"Revoke %s":                              "Отозвать %s",
+	"Token name must be up to %d characters": "Название токена должно быть не длиннее %d символов",
+	"Error add token *%s*: %s":               "Ошибка выпуска токена *%s*: %s",
+	"Token *%s* - %s success issued, copy it now, it is not shown again:\n`%s`\n" +
+		"Send it in header `Authorization: Bearer <token>`\r\nShow /token": "Токен *%s* - %s выпущен, " +
+		"скопируйте его сейчас, он больше не будет показан:\n`%s`\nПередавайте его в заголовке " +
+		"`Authorization: Bearer <token>`\r\nПоказать /token",
+
+	// webhooks
+	"%s\nFor example `/webhook https://example.com/hook spending.added limit.exceeded`, events are %s": "%s\n" +
+		"Например `/webhook https://example.com/hook spending.added limit.exceeded`, события: %s",
+	"Error add webhook: %s": "Ошибка регистрации вебхука: %s",
+	"Webhook %s success registered for %s\nPayloads are signed by HMAC-SHA256 in header `%s`, secret:\n`%s`\r\n" +
+		"Show /webhook": "Вебхук %s зарегистрирован для %s\nДанные подписываются HMAC-SHA256 в заголовке `%s`, " +
+		"секрет:\n`%s`\r\nПоказать /webhook",
+	"Webhook not deleted: %s": "Вебхук не удалён: %s",
+	"Webhook success deleted": "Вебхук удалён",
+	"Webhooks list is empty, write `/webhook https://example.com/hook` to post events %s to url": "Список " +
+		"вебхуков пуст, напишите `/webhook https://example.com/hook`, чтобы отправлять события %s на url",
+	"Webhooks:": "Вебхуки:",
+	"active":    "активен",
+	"*disabled* %s, register url again to enable": "*отключён* %s, зарегистрируйте url снова, чтобы включить",
+}
+
+// ruPlurals are Russian forms one, few and many, key is English form one
+var ruPlurals = map[string][]string{
+	"%d day":   {"%d день", "%d дня", "%d дней"},
+	"%d month": {"%d месяц", "%d месяца", "%d месяцев"},
+	"%d failed delivery in a row": {"%d неудачная доставка подряд", "%d неудачные доставки подряд",
+		"%d неудачных доставок подряд"},
+}
@@ -0,0 +1,74 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultSize is page size when request does not set it
+	DefaultSize = 20
+	// MaxSize is the largest page size, larger sizes are cut to it
+	MaxSize = 100
+
+	tokenPrefix = "offset:"
+)
+
+var InvalidTokenError = errors.New("invalid page token")
+
+// Page is window of list by offset and limit
+type Page struct {
+	Offset int
+	Limit  int
+}
+
+// Parse returns page by requested size and opaque token of previous response, empty token is the first page
+func Parse(size int32, token string) (Page, error) {
+	p := Page{Limit: int(size)}
+	if p.Limit <= 0 {
+		p.Limit = DefaultSize
+	}
+	if p.Limit > MaxSize {
+		p.Limit = MaxSize
+	}
+	if token == "" {
+		return p, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || !strings.HasPrefix(string(raw), tokenPrefix) {
+		return Page{}, InvalidTokenError
+	}
+	p.Offset, err = strconv.Atoi(string(raw[len(tokenPrefix):]))
+	if err != nil || p.Offset < 0 {
+		return Page{}, InvalidTokenError
+	}
+
+	return p, nil
+}
+
+// NextToken returns token of the next page, empty when the page is the last one of total items
+func (p Page) NextToken(total int) string {
+	next := p.Offset + p.Limit
+	if next >= total {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s%d", tokenPrefix, next)))
+}
+
+// Slice returns bounds of page in list of total items
+func (p Page) Slice(total int) (from, to int) {
+	from, to = p.Offset, p.Offset+p.Limit
+	if from > total {
+		from = total
+	}
+	if to > total {
+		to = total
+	}
+
+	return
+}
@@ -0,0 +1,39 @@
+package pagination
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	p, err := Parse(0, "")
+	assert.NoError(t, err)
+	assert.Equal(t, Page{Offset: 0, Limit: DefaultSize}, p)
+
+	p, err = Parse(500, "")
+	assert.NoError(t, err)
+	assert.Equal(t, MaxSize, p.Limit)
+
+	token := Page{Offset: 0, Limit: 10}.NextToken(25)
+	p, err = Parse(10, token)
+	assert.NoError(t, err)
+	assert.Equal(t, Page{Offset: 10, Limit: 10}, p)
+
+	_, err = Parse(10, "garbage")
+	assert.ErrorIs(t, err, InvalidTokenError)
+}
+
+func TestPage_NextToken(t *testing.T) {
+	assert.Equal(t, "", Page{Offset: 20, Limit: 10}.NextToken(25))
+	assert.Equal(t, "", Page{Offset: 0, Limit: 10}.NextToken(10))
+	assert.NotEqual(t, "", Page{Offset: 0, Limit: 10}.NextToken(11))
+}
+
+func TestPage_Slice(t *testing.T) {
+	from, to := Page{Offset: 10, Limit: 10}.Slice(15)
+	assert.Equal(t, 10, from)
+	assert.Equal(t, 15, to)
+	from, to = Page{Offset: 30, Limit: 10}.Slice(15)
+	assert.Equal(t, 15, from)
+	assert.Equal(t, 15, to)
+}
@@ -0,0 +1,190 @@
+package period
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Period string
+
+const (
+	PeriodDay     Period = "day"
+	PeriodWeek    Period = "week"
+	PeriodMonth   Period = "month"
+	PeriodYear    Period = "year"
+	PeriodRolling Period = "days"
+
+	maxRollingDays = 366
+	// MaxMonthDay is the last day month can start on, so every month has it
+	MaxMonthDay = 28
+)
+
+// Start is weekday weeks start on and day of month months start on, financial year starts on
+// that day of January
+type Start struct {
+	Weekday  time.Weekday
+	MonthDay int
+}
+
+// DefaultStart is calendar week from monday and calendar month
+var DefaultStart = Start{Weekday: time.Monday, MonthDay: 1}
+
+func (s Start) Validate() error {
+	if s.Weekday < time.Sunday || s.Weekday > time.Saturday {
+		return errors.New(fmt.Sprintf("unknown weekday '%d'", s.Weekday))
+	}
+	if s.MonthDay < 1 || s.MonthDay > MaxMonthDay {
+		return errors.New(fmt.Sprintf("month day must be between 1 and %d", MaxMonthDay))
+	}
+
+	return nil
+}
+
+// Week returns start weekday 00:00:00 and the day before next start weekday 23:59:59 of week with t
+func (s Start) Week(t time.Time) (f1, f2 time.Time) {
+	offset := (int(t.Weekday()) - int(s.Weekday) + 7) % 7
+	f1 = time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+	f2 = endOfDay(f1.AddDate(0, 0, 6))
+
+	return
+}
+
+// Month returns start day of month with t and the day before next start day
+func (s Start) Month(t time.Time) (f1, f2 time.Time) {
+	month := t.Month()
+	if t.Day() < s.monthDay() {
+		month--
+	}
+	f1 = time.Date(t.Year(), month, s.monthDay(), 0, 0, 0, 0, t.Location())
+	f2 = endOfDay(f1.AddDate(0, 1, -1))
+
+	return
+}
+
+// Year returns start day of January of year with t and the day before start day of next January
+func (s Start) Year(t time.Time) (f1, f2 time.Time) {
+	year := t.Year()
+	if t.Month() == time.January && t.Day() < s.monthDay() {
+		year--
+	}
+	f1 = time.Date(year, time.January, s.monthDay(), 0, 0, 0, 0, t.Location())
+	f2 = endOfDay(f1.AddDate(1, 0, -1))
+
+	return
+}
+
+func (s Start) monthDay() int {
+	if s.MonthDay < 1 {
+		return 1
+	}
+
+	return s.MonthDay
+}
+
+// Range is calendar day, week, month, year or rolling number of days ending today
+type Range struct {
+	Period Period
+	Days   int
+}
+
+// Parse reads range from command arguments: `week` or `days 10`, month by default
+func Parse(args []string) (r Range, err error) {
+	if len(args) == 0 {
+		return Range{Period: PeriodMonth}, nil
+	}
+
+	r.Period = Period(strings.ToLower(args[0]))
+	if r.Period == PeriodRolling {
+		if len(args) != 2 {
+			return Range{}, errors.New("rolling period must contain number of days")
+		}
+		if r.Days, err = strconv.Atoi(args[1]); err != nil {
+			return Range{}, errors.Wrap(err, "period days")
+		}
+	} else if len(args) != 1 {
+		return Range{}, errors.New(fmt.Sprintf("period '%s' has no arguments", args[0]))
+	}
+
+	return r, r.Validate()
+}
+
+func (r Range) Validate() error {
+	switch r.Period {
+	case PeriodDay, PeriodWeek, PeriodMonth, PeriodYear:
+	case PeriodRolling:
+		if r.Days < 1 || r.Days > maxRollingDays {
+			return errors.New(fmt.Sprintf("days must be between 1 and %d", maxRollingDays))
+		}
+	default:
+		return errors.New(fmt.Sprintf("unknown period '%s'", r.Period))
+	}
+
+	return nil
+}
+
+// Window returns range bounds with t, weeks and months begin on start, unknown period is evaluated as month
+func (r Range) Window(t time.Time, start Start) (f1, f2 time.Time) {
+	switch r.Period {
+	case PeriodDay:
+		return Day(t)
+	case PeriodWeek:
+		return start.Week(t)
+	case PeriodYear:
+		return start.Year(t)
+	case PeriodRolling:
+		return Rolling(t, r.Days)
+	}
+
+	return start.Month(t)
+}
+
+func (r Range) String() string {
+	if r.Period == PeriodRolling {
+		return fmt.Sprintf("%d days", r.Days)
+	}
+
+	return string(r.Period)
+}
+
+// Date returns calendar date of t at UTC midnight, the way dates are read from database
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Day returns 00:00:00 and 23:59:59 of day with t
+func Day(t time.Time) (f1, f2 time.Time) {
+	f1 = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	f2 = endOfDay(f1)
+
+	return
+}
+
+// Week returns monday 00:00:00 and sunday 23:59:59 of week with t
+func Week(t time.Time) (f1, f2 time.Time) {
+	return DefaultStart.Week(t)
+}
+
+// Month returns first and last day of month with t
+func Month(t time.Time) (f1, f2 time.Time) {
+	return DefaultStart.Month(t)
+}
+
+// Year returns first and last day of year with t
+func Year(t time.Time) (f1, f2 time.Time) {
+	return DefaultStart.Year(t)
+}
+
+// Rolling returns last days including day with t
+func Rolling(t time.Time, days int) (f1, f2 time.Time) {
+	f1 = time.Date(t.Year(), t.Month(), t.Day()-days+1, 0, 0, 0, 0, t.Location())
+	f2 = endOfDay(t)
+
+	return
+}
+
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
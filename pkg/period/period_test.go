@@ -0,0 +1,79 @@
+package period
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriods(t *testing.T) {
+	at := func(y int, m time.Month, d, h, min, s int) time.Time {
+		return time.Date(y, m, d, h, min, s, 0, time.UTC)
+	}
+	tests := []struct {
+		name   string
+		fn     func(time.Time) (time.Time, time.Time)
+		t      time.Time
+		f1, f2 time.Time
+	}{
+		{"week from wednesday", Week, at(2022, 11, 16, 12, 0, 0), at(2022, 11, 14, 0, 0, 0), at(2022, 11, 20, 23, 59, 59)},
+		{"week from sunday", Week, at(2022, 11, 20, 12, 0, 0), at(2022, 11, 14, 0, 0, 0), at(2022, 11, 20, 23, 59, 59)},
+		{"week from monday", Week, at(2022, 11, 14, 0, 0, 0), at(2022, 11, 14, 0, 0, 0), at(2022, 11, 20, 23, 59, 59)},
+		{"month", Month, at(2022, 2, 10, 12, 0, 0), at(2022, 2, 1, 0, 0, 0), at(2022, 2, 28, 23, 59, 59)},
+		{"day", Day, at(2022, 2, 10, 12, 0, 0), at(2022, 2, 10, 0, 0, 0), at(2022, 2, 10, 23, 59, 59)},
+		{"year", Year, at(2022, 2, 10, 12, 0, 0), at(2022, 1, 1, 0, 0, 0), at(2022, 12, 31, 23, 59, 59)},
+		{"week from sunday start", Start{Weekday: time.Sunday, MonthDay: 1}.Week,
+			at(2022, 11, 16, 12, 0, 0), at(2022, 11, 13, 0, 0, 0), at(2022, 11, 19, 23, 59, 59)},
+		{"month from payday", Start{Weekday: time.Monday, MonthDay: 10}.Month,
+			at(2022, 11, 16, 12, 0, 0), at(2022, 11, 10, 0, 0, 0), at(2022, 12, 9, 23, 59, 59)},
+		{"month before payday", Start{Weekday: time.Monday, MonthDay: 10}.Month,
+			at(2022, 1, 9, 12, 0, 0), at(2021, 12, 10, 0, 0, 0), at(2022, 1, 9, 23, 59, 59)},
+		{"year before payday", Start{Weekday: time.Monday, MonthDay: 10}.Year,
+			at(2022, 1, 9, 12, 0, 0), at(2021, 1, 10, 0, 0, 0), at(2022, 1, 9, 23, 59, 59)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f1, f2 := tt.fn(tt.t)
+			if !f1.Equal(tt.f1) || !f2.Equal(tt.f2) {
+				t.Errorf("got %v - %v, want %v - %v", f1, f2, tt.f1, tt.f2)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    Range
+		wantErr bool
+	}{
+		{"default month", nil, Range{Period: PeriodMonth}, false},
+		{"week", []string{"Week"}, Range{Period: PeriodWeek}, false},
+		{"rolling", []string{"days", "10"}, Range{Period: PeriodRolling, Days: 10}, false},
+		{"rolling without days", []string{"days"}, Range{}, true},
+		{"rolling out of range", []string{"days", "0"}, Range{}, true},
+		{"unknown", []string{"hour"}, Range{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRange_Window(t *testing.T) {
+	now := time.Date(2022, 11, 16, 12, 0, 0, 0, time.UTC)
+	f1, f2 := Range{Period: PeriodRolling, Days: 10}.Window(now, DefaultStart)
+	if want := time.Date(2022, 11, 7, 0, 0, 0, 0, time.UTC); !f1.Equal(want) {
+		t.Errorf("Window() f1 = %v, want %v", f1, want)
+	}
+	if want := time.Date(2022, 11, 16, 23, 59, 59, 0, time.UTC); !f2.Equal(want) {
+		t.Errorf("Window() f2 = %v, want %v", f2, want)
+	}
+}
@@ -0,0 +1,171 @@
+package schedule
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Period string
+
+const (
+	Week  Period = "week"
+	Month Period = "month"
+	Year  Period = "year"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseWeekday reads weekday by first three letters of its name
+func ParseWeekday(s string) (time.Weekday, bool) {
+	wd, ok := weekdays[strings.ToLower(s)]
+
+	return wd, ok
+}
+
+// Schedule describes repeating date: weekly on weekday, monthly on day or yearly on month and day
+type Schedule struct {
+	Period Period
+	Day    int
+	Month  time.Month
+}
+
+// Parse reads schedule from command arguments: `month 10`, `week mon` or `year 03-15`
+func Parse(args []string) (s Schedule, err error) {
+	if len(args) != 2 {
+		return Schedule{}, errors.New("schedule must contain period and day")
+	}
+
+	s.Period = Period(strings.ToLower(args[0]))
+	day := strings.ToLower(args[1])
+	switch s.Period {
+	case Week:
+		if wd, ok := ParseWeekday(day); ok {
+			s.Day = int(wd)
+		} else if s.Day, err = strconv.Atoi(day); err != nil {
+			return Schedule{}, errors.Wrap(err, "schedule weekday")
+		}
+	case Month:
+		if s.Day, err = strconv.Atoi(day); err != nil {
+			return Schedule{}, errors.Wrap(err, "schedule day")
+		}
+	case Year:
+		t, errParse := time.Parse("01-02", day)
+		if errParse != nil {
+			return Schedule{}, errors.Wrap(errParse, "schedule month and day")
+		}
+		s.Month, s.Day = t.Month(), t.Day()
+	default:
+		return Schedule{}, errors.New(fmt.Sprintf("unknown period '%s'", args[0]))
+	}
+
+	return s, s.Validate()
+}
+
+func (s Schedule) Validate() error {
+	switch s.Period {
+	case Week:
+		if s.Day < 0 || s.Day > 6 {
+			return errors.New("weekday must be between 0 and 6")
+		}
+	case Month:
+		if s.Day < 1 || s.Day > 31 {
+			return errors.New("day must be between 1 and 31")
+		}
+	case Year:
+		if s.Month < time.January || s.Month > time.December || s.Day < 1 || s.Day > 31 {
+			return errors.New("month and day out of range")
+		}
+	default:
+		return errors.New(fmt.Sprintf("unknown period '%s'", s.Period))
+	}
+
+	return nil
+}
+
+// Next returns first occurrence at or after the date of t
+func (s Schedule) Next(t time.Time) time.Time {
+	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	switch s.Period {
+	case Week:
+		return d.AddDate(0, 0, (s.Day-int(d.Weekday())+7)%7)
+	case Month:
+		for m := time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, d.Location()); ; m = m.AddDate(0, 1, 0) {
+			o := dayOfMonth(m.Year(), m.Month(), s.Day, d.Location())
+			if !o.Before(d) {
+				return o
+			}
+		}
+	case Year:
+		for y := d.Year(); ; y++ {
+			o := dayOfMonth(y, s.Month, s.Day, d.Location())
+			if !o.Before(d) {
+				return o
+			}
+		}
+	}
+
+	return time.Time{}
+}
+
+// NextAt returns first occurrence at clock time of day strictly after t
+func (s Schedule) NextAt(t time.Time, clock time.Duration) time.Time {
+	at := func(d time.Time) time.Time {
+		return time.Date(d.Year(), d.Month(), d.Day(), 0, int(clock.Minutes()), 0, 0, d.Location())
+	}
+
+	o := at(s.Next(t))
+	if !o.After(t) {
+		o = at(s.Next(o.AddDate(0, 0, 1)))
+	}
+
+	return o
+}
+
+// Between returns occurrences in range (from, to] by dates
+func (s Schedule) Between(from, to time.Time) (ts []time.Time) {
+	if s.Validate() != nil {
+		return nil
+	}
+
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location())
+	for o := s.Next(from.AddDate(0, 0, 1)); !o.After(to); o = s.Next(o.AddDate(0, 0, 1)) {
+		ts = append(ts, o)
+	}
+
+	return
+}
+
+func (s Schedule) String() string {
+	switch s.Period {
+	case Week:
+		return fmt.Sprintf("weekly on %s", time.Weekday(s.Day))
+	case Month:
+		return fmt.Sprintf("monthly on day %d", s.Day)
+	case Year:
+		return fmt.Sprintf("yearly on %d %s", s.Day, s.Month)
+	}
+
+	return string(s.Period)
+}
+
+// dayOfMonth clamps day to the last day of month, so day 31 means end of every month
+func dayOfMonth(year int, month time.Month, day int, loc *time.Location) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+	if day > last {
+		day = last
+	}
+
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
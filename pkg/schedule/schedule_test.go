@@ -0,0 +1,102 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	t.Run("month", func(t *testing.T) {
+		got, err := Parse([]string{"month", "10"})
+		want := Schedule{Period: Month, Day: 10}
+		if err != nil || got != want {
+			t.Errorf("Parse() = %v, %v, want %v", got, err, want)
+		}
+	})
+	t.Run("week by name", func(t *testing.T) {
+		got, err := Parse([]string{"Week", "mon"})
+		want := Schedule{Period: Week, Day: int(time.Monday)}
+		if err != nil || got != want {
+			t.Errorf("Parse() = %v, %v, want %v", got, err, want)
+		}
+	})
+	t.Run("year", func(t *testing.T) {
+		got, err := Parse([]string{"year", "03-15"})
+		want := Schedule{Period: Year, Day: 15, Month: time.March}
+		if err != nil || got != want {
+			t.Errorf("Parse() = %v, %v, want %v", got, err, want)
+		}
+	})
+	t.Run("day out of range", func(t *testing.T) {
+		if _, err := Parse([]string{"month", "32"}); err == nil {
+			t.Errorf("Parse() error expected")
+		}
+	})
+	t.Run("unknown period", func(t *testing.T) {
+		if _, err := Parse([]string{"hour", "1"}); err == nil {
+			t.Errorf("Parse() error expected")
+		}
+	})
+}
+
+func TestSchedule_Next(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Schedule
+		t    time.Time
+		want time.Time
+	}{
+		{"month same day", Schedule{Period: Month, Day: 10}, date(2022, 11, 10), date(2022, 11, 10)},
+		{"month next", Schedule{Period: Month, Day: 10}, date(2022, 11, 11), date(2022, 12, 10)},
+		{"month clamp to end", Schedule{Period: Month, Day: 31}, date(2022, 2, 1), date(2022, 2, 28)},
+		{"week", Schedule{Period: Week, Day: int(time.Monday)}, date(2022, 11, 16), date(2022, 11, 21)},
+		{"year leap day", Schedule{Period: Year, Month: time.February, Day: 29}, date(2022, 3, 1), date(2023, 2, 28)},
+		{"year", Schedule{Period: Year, Month: time.March, Day: 15}, date(2022, 1, 1), date(2022, 3, 15)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.Next(tt.t); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_NextAt(t *testing.T) {
+	s := Schedule{Period: Week, Day: int(time.Sunday)}
+	clock := 20 * time.Hour
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{"before clock on the day", time.Date(2022, 11, 20, 19, 0, 0, 0, time.UTC), time.Date(2022, 11, 20, 20, 0, 0, 0, time.UTC)},
+		{"at clock", time.Date(2022, 11, 20, 20, 0, 0, 0, time.UTC), time.Date(2022, 11, 27, 20, 0, 0, 0, time.UTC)},
+		{"mid week", time.Date(2022, 11, 16, 8, 0, 0, 0, time.UTC), time.Date(2022, 11, 20, 20, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.NextAt(tt.t, clock); !got.Equal(tt.want) {
+				t.Errorf("NextAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_Between(t *testing.T) {
+	s := Schedule{Period: Month, Day: 1}
+	got := s.Between(date(2022, 9, 1), date(2022, 11, 1))
+	want := []time.Time{date(2022, 10, 1), date(2022, 11, 1)}
+	if len(got) != len(want) {
+		t.Fatalf("Between() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("Between()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
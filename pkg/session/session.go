@@ -0,0 +1,173 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/logger"
+	"sync"
+	"time"
+)
+
+const (
+	keyPrefix   = "session:"
+	idBytes     = 9
+	dialTimeout = time.Second
+)
+
+var NotFoundError = errors.New("session not found")
+
+// Session keeps states of buttons of inline keyboard of user on server
+type Session struct {
+	UserId int      `json:"u"`
+	Values []string `json:"v"`
+}
+
+// Store keeps sessions by id until they expire
+type Store interface {
+	Save(context.Context, string, Session) error
+	// Get returns session, NotFoundError when it is expired or deleted
+	Get(context.Context, string) (Session, error)
+	// Delete removes session and reports whether it existed, only one of concurrent calls gets true
+	Delete(context.Context, string) (bool, error)
+}
+
+// NewId returns short random id of session fitting into callback data
+func NewId() string {
+	b := make([]byte, idBytes)
+	_, _ = rand.Read(b)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// New returns store in redis at addr shared by replicas of bot, store in memory of process
+// when redis is not available
+func New(ctx context.Context, addr, password string, ttl time.Duration) Store {
+	rdb := redis.NewClient(&redis.Options{
+		Addr:        addr,
+		Password:    password,
+		DialTimeout: dialTimeout,
+	})
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		_ = rdb.Close()
+		logger.Info(fmt.Sprintf("sessions are kept in memory, redis is not available: %s", err.Error()))
+		return NewMemory(ttl)
+	}
+
+	return NewRedis(rdb, ttl)
+}
+
+// Redis keeps sessions in redis with ttl
+type Redis struct {
+	rdb *redis.Client
+	ttl time.Duration
+}
+
+func NewRedis(rdb *redis.Client, ttl time.Duration) *Redis {
+	return &Redis{
+		rdb: rdb,
+		ttl: ttl,
+	}
+}
+
+func (r *Redis) Save(ctx context.Context, id string, s Session) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return errors.Wrap(err, "marshal session")
+	}
+	if err = r.rdb.Set(ctx, keyPrefix+id, b, r.ttl).Err(); err != nil {
+		return errors.Wrap(err, "save session")
+	}
+
+	return nil
+}
+
+func (r *Redis) Get(ctx context.Context, id string) (s Session, err error) {
+	b, err := r.rdb.Get(ctx, keyPrefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return s, NotFoundError
+	}
+	if err != nil {
+		return s, errors.Wrap(err, "get session")
+	}
+	if err = json.Unmarshal(b, &s); err != nil {
+		return s, errors.Wrap(err, "unmarshal session")
+	}
+
+	return
+}
+
+func (r *Redis) Delete(ctx context.Context, id string) (bool, error) {
+	n, err := r.rdb.Del(ctx, keyPrefix+id).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "delete session")
+	}
+
+	return n > 0, nil
+}
+
+type memoryItem struct {
+	session   Session
+	expiresAt time.Time
+}
+
+// Memory keeps sessions in memory of process, expired sessions are removed on save
+type Memory struct {
+	mu      sync.Mutex
+	items   map[string]memoryItem
+	ttl     time.Duration
+	sweptAt time.Time
+	now     func() time.Time
+}
+
+func NewMemory(ttl time.Duration) *Memory {
+	return &Memory{
+		items: make(map[string]memoryItem),
+		ttl:   ttl,
+		now:   time.Now,
+	}
+}
+
+func (m *Memory) Save(_ context.Context, id string, s Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if now.Sub(m.sweptAt) >= m.ttl {
+		for key, item := range m.items {
+			if !now.Before(item.expiresAt) {
+				delete(m.items, key)
+			}
+		}
+		m.sweptAt = now
+	}
+	m.items[id] = memoryItem{session: s, expiresAt: now.Add(m.ttl)}
+
+	return nil
+}
+
+func (m *Memory) Get(_ context.Context, id string) (Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[id]
+	if !ok || !m.now().Before(item.expiresAt) {
+		return Session{}, NotFoundError
+	}
+
+	return item.session, nil
+}
+
+func (m *Memory) Delete(_ context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[id]
+	delete(m.items, id)
+
+	return ok && m.now().Before(item.expiresAt), nil
+}
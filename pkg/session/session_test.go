@@ -0,0 +1,54 @@
+package session
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 11, 28, 10, 0, 0, 0, time.UTC)
+	m := NewMemory(time.Hour)
+	m.now = func() time.Time { return now }
+
+	s := Session{UserId: 5, Values: []string{"100.00_1", "100.00_2"}}
+	assert.NoError(t, m.Save(ctx, "a", s))
+	got, err := m.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, s, got)
+
+	_, err = m.Get(ctx, "b")
+	assert.ErrorIs(t, err, NotFoundError)
+
+	// the first delete wins, replayed callback finds nothing
+	ok, err := m.Delete(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = m.Delete(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, m.Save(ctx, "c", s))
+	now = now.Add(time.Hour)
+	_, err = m.Get(ctx, "c")
+	assert.ErrorIs(t, err, NotFoundError)
+	ok, _ = m.Delete(ctx, "c")
+	assert.False(t, ok)
+
+	// expired sessions are swept on save
+	assert.NoError(t, m.Save(ctx, "d", s))
+	now = now.Add(2 * time.Hour)
+	assert.NoError(t, m.Save(ctx, "e", s))
+	assert.Len(t, m.items, 1)
+}
+
+func TestNew(t *testing.T) {
+	store := New(context.Background(), "127.0.0.1:1", "", time.Minute)
+	assert.IsType(t, &Memory{}, store)
+
+	id := NewId()
+	assert.Len(t, id, 12)
+	assert.NotEqual(t, id, NewId())
+}
@@ -0,0 +1,205 @@
+package split
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"sort"
+)
+
+var (
+	EmptyAmountsError   = errors.New("amounts are empty")
+	NegativeAmountError = errors.New("amount less than 0")
+	SumError            = errors.New("amounts sum differs from total")
+)
+
+// Equal splits total among n members, remainder of the smallest units goes to the first members
+func Equal(total decimal.Decimal, n int) []decimal.Decimal {
+	if n < 1 {
+		return nil
+	}
+
+	return Shares(total, repeat(1, n))
+}
+
+// Shares splits total in proportion to shares, remainder of the smallest units goes to the first members
+func Shares(total decimal.Decimal, shares []int) []decimal.Decimal {
+	sum := 0
+	for _, share := range shares {
+		sum += share
+	}
+	if sum <= 0 {
+		return nil
+	}
+
+	parts := make([]decimal.Decimal, len(shares))
+	var rest = total
+	for i, share := range shares {
+		parts[i] = decimal.Decimal(int64(total) * int64(share) / int64(sum))
+		rest -= parts[i]
+	}
+	for i := 0; rest > 0; i = (i + 1) % len(parts) {
+		if shares[i] > 0 {
+			parts[i]++
+			rest--
+		}
+	}
+
+	return parts
+}
+
+// Exact checks that amounts sum to total within tolerance, difference of rounding goes to the last member
+func Exact(total decimal.Decimal, amounts []decimal.Decimal, tolerance decimal.Decimal) ([]decimal.Decimal, error) {
+	if len(amounts) == 0 {
+		return nil, EmptyAmountsError
+	}
+	var sum decimal.Decimal
+	for _, amount := range amounts {
+		if amount < 0 {
+			return nil, NegativeAmountError
+		}
+		sum += amount
+	}
+	diff := total - sum
+	if diff > tolerance || -diff > tolerance {
+		return nil, errors.Wrapf(SumError, "sum %.2f, total %.2f", sum, total)
+	}
+
+	parts := append([]decimal.Decimal{}, amounts...)
+	parts[len(parts)-1] += diff
+
+	return parts, nil
+}
+
+// Transfer is payment of Amount from member From to member To
+type Transfer struct {
+	From, To int
+	Amount   decimal.Decimal
+}
+
+// maxExactMembers bounds members settled by search of zero sum groups, it takes 2^n steps,
+// larger groups are settled greedily
+const maxExactMembers = 16
+
+// Settle returns the minimal number of transfers which make net balances of members zero,
+// positive balance is owed to member. Members are partitioned into the largest number of groups
+// with zero sum, group of k members is settled by k-1 transfers where the largest debtor pays
+// the largest creditor first
+func Settle(balances map[int]decimal.Decimal) (transfers []Transfer) {
+	ids := make([]int, 0, len(balances))
+	for id, balance := range balances {
+		if balance != 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	if len(ids) > maxExactMembers {
+		return settleGreedy(ids, balances)
+	}
+
+	for _, group := range zeroSumGroups(ids, balances) {
+		transfers = append(transfers, settleGreedy(group, balances)...)
+	}
+
+	return
+}
+
+// zeroSumGroups partitions members into the largest number of groups with zero sum of balances
+func zeroSumGroups(ids []int, balances map[int]decimal.Decimal) [][]int {
+	n := len(ids)
+	full := 1<<n - 1
+	sums := make([]decimal.Decimal, full+1)
+	// groups[mask] is the largest number of zero sum groups members of mask are partitioned into
+	groups := make([]int, full+1)
+	for mask := 1; mask <= full; mask++ {
+		for i := 0; i < n; i++ {
+			if mask&(1<<i) != 0 {
+				sums[mask] = sums[mask&^(1<<i)] + balances[ids[i]]
+				break
+			}
+		}
+		for i := 0; i < n; i++ {
+			if mask&(1<<i) != 0 && groups[mask&^(1<<i)] > groups[mask] {
+				groups[mask] = groups[mask&^(1<<i)]
+			}
+		}
+		if sums[mask] == 0 {
+			groups[mask]++
+		}
+	}
+
+	// members are removed one by one keeping the number of groups, every zero sum of the rest closes group
+	var result [][]int
+	var group []int
+	for mask := full; mask > 0; {
+		closed := 0
+		if sums[mask] == 0 {
+			closed = 1
+		}
+		for i := 0; i < n; i++ {
+			if mask&(1<<i) != 0 && groups[mask&^(1<<i)]+closed == groups[mask] {
+				group = append(group, ids[i])
+				mask &^= 1 << i
+				break
+			}
+		}
+		if sums[mask] == 0 {
+			result = append(result, group)
+			group = nil
+		}
+	}
+
+	return result
+}
+
+// settleGreedy settles members by at most n-1 transfers, the largest debtor pays the largest creditor first
+func settleGreedy(ids []int, balances map[int]decimal.Decimal) (transfers []Transfer) {
+	type member struct {
+		id     int
+		amount decimal.Decimal
+	}
+	var creditors, debtors []member
+	for _, id := range ids {
+		if balance := balances[id]; balance > 0 {
+			creditors = append(creditors, member{id, balance})
+		} else if balance < 0 {
+			debtors = append(debtors, member{id, -balance})
+		}
+	}
+	byAmount := func(ms []member) func(i, j int) bool {
+		return func(i, j int) bool {
+			if ms[i].amount == ms[j].amount {
+				return ms[i].id < ms[j].id
+			}
+			return ms[i].amount > ms[j].amount
+		}
+	}
+	sort.Slice(creditors, byAmount(creditors))
+	sort.Slice(debtors, byAmount(debtors))
+
+	for i, j := 0, 0; i < len(debtors) && j < len(creditors); {
+		amount := debtors[i].amount
+		if creditors[j].amount < amount {
+			amount = creditors[j].amount
+		}
+		transfers = append(transfers, Transfer{From: debtors[i].id, To: creditors[j].id, Amount: amount})
+		debtors[i].amount -= amount
+		creditors[j].amount -= amount
+		if debtors[i].amount == 0 {
+			i++
+		}
+		if creditors[j].amount == 0 {
+			j++
+		}
+	}
+
+	return
+}
+
+func repeat(v, n int) []int {
+	r := make([]int, n)
+	for i := range r {
+		r[i] = v
+	}
+
+	return r
+}
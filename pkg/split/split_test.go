@@ -0,0 +1,72 @@
+package split
+
+import (
+	"github.com/sku4/ozon-route256-spending-bot/pkg/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	got := Equal(decimal.Decimal(10), 3)
+	assert.Equal(t, []decimal.Decimal{4, 3, 3}, got)
+}
+
+func TestShares(t *testing.T) {
+	got := Shares(decimal.ToDecimal(1200), []int{2, 1, 1})
+	assert.Equal(t, []decimal.Decimal{decimal.ToDecimal(600), decimal.ToDecimal(300), decimal.ToDecimal(300)}, got)
+	assert.Nil(t, Shares(decimal.ToDecimal(1200), []int{0, 0}))
+}
+
+func TestExact(t *testing.T) {
+	tests := []struct {
+		name    string
+		amounts []decimal.Decimal
+		want    []decimal.Decimal
+		wantErr bool
+	}{
+		{"exact", []decimal.Decimal{60, 40}, []decimal.Decimal{60, 40}, false},
+		{"rounding to last", []decimal.Decimal{60, 39}, []decimal.Decimal{60, 40}, false},
+		{"over tolerance", []decimal.Decimal{60, 30}, nil, true},
+		{"negative", []decimal.Decimal{110, -10}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Exact(100, tt.amounts, 1)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	_, err := Exact(100, []decimal.Decimal{60, 30}, 1)
+	assert.ErrorIs(t, err, SumError)
+	_, err = Exact(100, nil, 1)
+	assert.ErrorIs(t, err, EmptyAmountsError)
+}
+
+func TestSettle(t *testing.T) {
+	balances := map[int]decimal.Decimal{1: 90, 2: -30, 3: -60, 4: 0}
+	got := Settle(balances)
+	assert.Equal(t, []Transfer{{From: 3, To: 1, Amount: 60}, {From: 2, To: 1, Amount: 30}}, got)
+
+	// chain of debts is settled with one transfer
+	got = Settle(map[int]decimal.Decimal{1: 50, 2: 0, 3: -50})
+	assert.Equal(t, []Transfer{{From: 3, To: 1, Amount: 50}}, got)
+
+	// greedy pays 5 to 6 first and needs 4 transfers, groups {5, -5} and {6, -3, -3} need 3
+	balances = map[int]decimal.Decimal{1: 6, 2: 5, 3: -5, 4: -3, 5: -3}
+	got = Settle(balances)
+	assert.ElementsMatch(t, []Transfer{{From: 3, To: 2, Amount: 5}, {From: 4, To: 1, Amount: 3},
+		{From: 5, To: 1, Amount: 3}}, got)
+	net := make(map[int]decimal.Decimal)
+	for _, tr := range got {
+		net[tr.From] += tr.Amount
+		net[tr.To] -= tr.Amount
+	}
+	for id, balance := range balances {
+		assert.Equal(t, balance, -net[id], "member %d", id)
+	}
+}
@@ -0,0 +1,37 @@
+package timezone
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+	"time"
+	// zone database is embedded, alpine images have no tzdata
+	_ "time/tzdata"
+)
+
+// Load returns time zone by IANA name, empty name is time zone of server
+func Load(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("unknown time zone '%s'", name))
+	}
+
+	return loc, nil
+}
+
+// FromLongitude returns zone with offset of whole hours nearest to solar time at longitude,
+// name of Etc zones has inverted sign: Etc/GMT-10 is UTC+10
+func FromLongitude(longitude float64) string {
+	offset := int(math.Round(longitude / 15))
+	switch {
+	case offset == 0:
+		return "Etc/GMT"
+	case offset > 0:
+		return fmt.Sprintf("Etc/GMT-%d", offset)
+	}
+
+	return fmt.Sprintf("Etc/GMT+%d", -offset)
+}
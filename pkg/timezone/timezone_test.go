@@ -0,0 +1,46 @@
+package timezone
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFromLongitude(t *testing.T) {
+	tests := []struct {
+		name      string
+		longitude float64
+		want      string
+		offset    int
+	}{
+		{"vladivostok", 131.9, "Etc/GMT-9", 9 * 3600},
+		{"london", -0.1, "Etc/GMT", 0},
+		{"new york", -74, "Etc/GMT+5", -5 * 3600},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromLongitude(tt.longitude)
+			if got != tt.want {
+				t.Fatalf("FromLongitude() = %v, want %v", got, tt.want)
+			}
+			loc, err := Load(got)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if _, offset := time.Date(2022, 11, 23, 0, 0, 0, 0, loc).Zone(); offset != tt.offset {
+				t.Errorf("offset = %d, want %d", offset, tt.offset)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	if loc, err := Load(""); err != nil || loc != time.Local {
+		t.Errorf("Load(\"\") = %v, %v, want local", loc, err)
+	}
+	if _, err := Load("Europe/Moscow"); err != nil {
+		t.Errorf("Load() error = %v", err)
+	}
+	if _, err := Load("Mars/Olympus"); err == nil {
+		t.Error("Load() of unknown zone expected error")
+	}
+}
@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// SignatureHeader carries `sha256=<hex>` HMAC of body by secret of webhook
+	SignatureHeader = "X-Spending-Signature"
+	// EventHeader carries name of event
+	EventHeader = "X-Spending-Event"
+	// DeliveryHeader carries id of delivery, it is the same for retries of delivery
+	DeliveryHeader = "X-Spending-Delivery"
+
+	signaturePrefix = "sha256="
+	secretPrefix    = "whsec_"
+	secretBytes     = 24
+)
+
+var (
+	// DeliveryError is returned when receiver did not accept payload after all attempts
+	DeliveryError = errors.New("webhook delivery failed")
+)
+
+// Attempt is result of one request to receiver, StatusCode is 0 when request failed
+type Attempt struct {
+	Number     int
+	StatusCode int
+	Err        error
+	Duration   time.Duration
+}
+
+// Ok reports whether receiver accepted payload
+func (a Attempt) Ok() bool {
+	return a.Err == nil && a.StatusCode >= 200 && a.StatusCode < 300
+}
+
+// retryable reports whether delivery is repeated after attempt: network errors, 429 and 5xx
+func (a Attempt) retryable() bool {
+	return a.Err != nil || a.StatusCode == http.StatusTooManyRequests || a.StatusCode >= 500
+}
+
+// Sender posts signed json payloads to receivers with retries by exponential backoff
+type Sender struct {
+	client   *http.Client
+	attempts int
+	backoff  time.Duration
+}
+
+// NewSender creates sender making up to attempts requests, pause before retry n is backoff * 2^(n-1)
+func NewSender(client *http.Client, attempts int, backoff time.Duration) *Sender {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	return &Sender{
+		client:   client,
+		attempts: attempts,
+		backoff:  backoff,
+	}
+}
+
+// Send posts body to url until receiver responds with 2xx, every attempt is passed to log.
+// Receiver errors 4xx except 429 are not retried
+func (s *Sender) Send(ctx context.Context, url, secret, event, deliveryId string, body []byte,
+	log func(Attempt)) error {
+	signature := Sign(secret, body)
+	for n := 1; n <= s.attempts; n++ {
+		if n > 1 {
+			select {
+			case <-ctx.Done():
+				return errors.Wrap(ctx.Err(), "webhook delivery")
+			case <-time.After(s.backoff << (n - 2)):
+			}
+		}
+
+		attempt := s.post(ctx, url, signature, event, deliveryId, body)
+		attempt.Number = n
+		if log != nil {
+			log(attempt)
+		}
+		if attempt.Ok() {
+			return nil
+		}
+		if !attempt.retryable() {
+			break
+		}
+	}
+
+	return DeliveryError
+}
+
+func (s *Sender) post(ctx context.Context, url, signature, event, deliveryId string, body []byte) (a Attempt) {
+	start := time.Now()
+	defer func() {
+		a.Duration = time.Since(start)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Attempt{Err: errors.Wrap(err, "webhook request")}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "spending-bot-webhook")
+	req.Header.Set(SignatureHeader, signature)
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, deliveryId)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Attempt{Err: err}
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	return Attempt{StatusCode: resp.StatusCode}
+}
+
+// Sign returns value of SignatureHeader for body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks value of SignatureHeader in constant time, receivers verify payloads the same way
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// NewSecret returns random secret of webhook
+func NewSecret() (string, error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "webhook secret")
+	}
+
+	return secretPrefix + hex.EncodeToString(b), nil
+}
+
+// NewDeliveryId returns random id of delivery
+func NewDeliveryId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,139 @@
+package webhook
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receiver verifies signatures and responds with codes in order, the last code repeats
+type receiver struct {
+	mu        sync.Mutex
+	secret    string
+	codes     []int
+	requests  int
+	bodies    []string
+	delivery  []string
+	badSigned int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !Verify(r.secret, body, req.Header.Get(SignatureHeader)) {
+		r.badSigned++
+	}
+	r.bodies = append(r.bodies, string(body))
+	r.delivery = append(r.delivery, req.Header.Get(DeliveryHeader)+" "+req.Header.Get(EventHeader))
+	code := r.codes[len(r.codes)-1]
+	if r.requests < len(r.codes) {
+		code = r.codes[r.requests]
+	}
+	r.requests++
+	w.WriteHeader(code)
+}
+
+func TestSender_Send(t *testing.T) {
+	body := []byte(`{"event":"spending.added","data":{"price":100}}`)
+
+	tests := []struct {
+		name         string
+		codes        []int
+		wantErr      error
+		wantRequests int
+		wantCodes    []int
+	}{
+		{
+			name:         "Ok",
+			codes:        []int{http.StatusNoContent},
+			wantRequests: 1,
+			wantCodes:    []int{http.StatusNoContent},
+		},
+		{
+			name:         "Retry server errors",
+			codes:        []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusOK},
+			wantRequests: 3,
+			wantCodes:    []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name:         "Client error is not retried",
+			codes:        []int{http.StatusGone},
+			wantErr:      DeliveryError,
+			wantRequests: 1,
+			wantCodes:    []int{http.StatusGone},
+		},
+		{
+			name:         "Attempts exhausted",
+			codes:        []int{http.StatusBadGateway},
+			wantErr:      DeliveryError,
+			wantRequests: 4,
+			wantCodes: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway,
+				http.StatusBadGateway},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recv := &receiver{secret: "whsec_test", codes: tt.codes}
+			srv := httptest.NewServer(recv)
+			defer srv.Close()
+
+			var codes []int
+			sender := NewSender(srv.Client(), 4, time.Millisecond)
+			err := sender.Send(context.Background(), srv.URL, "whsec_test", "spending.added", "d-1", body,
+				func(a Attempt) {
+					codes = append(codes, a.StatusCode)
+				})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantRequests, recv.requests)
+			assert.Equal(t, tt.wantCodes, codes)
+			assert.Zero(t, recv.badSigned)
+			for i := range recv.bodies {
+				assert.Equal(t, string(body), recv.bodies[i])
+				assert.Equal(t, "d-1 spending.added", recv.delivery[i])
+			}
+		})
+	}
+}
+
+func TestSender_SendUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	var attempts []Attempt
+	err := NewSender(http.DefaultClient, 2, time.Millisecond).Send(context.Background(), url, "s", "e", "d",
+		[]byte("{}"), func(a Attempt) {
+			attempts = append(attempts, a)
+		})
+	assert.ErrorIs(t, err, DeliveryError)
+	assert.Len(t, attempts, 2)
+	assert.Error(t, attempts[1].Err)
+	assert.Equal(t, 2, attempts[1].Number)
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"a":1}`)
+	signature := Sign("secret", body)
+	assert.True(t, strings.HasPrefix(signature, "sha256="))
+	assert.True(t, Verify("secret", body, signature))
+	assert.False(t, Verify("other", body, signature))
+	assert.False(t, Verify("secret", []byte(`{"a":2}`), signature))
+
+	secret, err := NewSecret()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, "whsec_"))
+	assert.NotEqual(t, NewDeliveryId(), NewDeliveryId())
+}